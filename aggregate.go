@@ -0,0 +1,316 @@
+package geohashi
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+var errInvalidAggregator = errors.New("geohashi: invalid aggregator encoding")
+
+// Stats are aggregated values.
+type Stats struct {
+	Count         int64
+	Sum, Min, Max float64
+}
+
+// Mean returns the mean value.
+func (s Stats) Mean() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / float64(s.Count)
+}
+
+func (s *Stats) add(v float64) {
+	if s.Count == 0 {
+		s.Min, s.Max = v, v
+	} else {
+		s.Min, s.Max = math.Min(s.Min, v), math.Max(s.Max, v)
+	}
+	s.Count++
+	s.Sum += v
+}
+
+func (s *Stats) merge(o Stats) {
+	if o.Count <= 0 {
+		return
+	}
+	if s.Count == 0 {
+		*s = o
+		return
+	}
+	s.Count += o.Count
+	s.Sum += o.Sum
+	s.Min, s.Max = math.Min(s.Min, o.Min), math.Max(s.Max, o.Max)
+}
+
+// CellStats are aggregated values of a cell.
+type CellStats struct {
+	Hash  Hash
+	Stats Stats
+}
+
+// --------------------------------------------------------------------
+
+// Aggregator accumulates values per cell at a fixed precision. Aggregators are
+// not safe for concurrent use, but can be merged.
+type Aggregator struct {
+	prec  uint8
+	cells map[Hash]Stats
+}
+
+// NewAggregator inits a new aggregator.
+func NewAggregator(prec uint8) *Aggregator {
+	if prec < PrecisionMin || prec > PrecisionMax {
+		prec = PrecisionMax
+	}
+	return &Aggregator{prec: prec, cells: make(map[Hash]Stats)}
+}
+
+// Precision returns the precision of the aggregator.
+func (a *Aggregator) Precision() uint8 { return a.prec }
+
+// Len returns the number of cells.
+func (a *Aggregator) Len() int { return len(a.cells) }
+
+// Add adds a value at a location. Locations outside the supported coordinate
+// range are ignored.
+func (a *Aggregator) Add(lat, lon, value float64) {
+	if ValidCoordinates(lat, lon) {
+		a.add(encode(lat, lon, a.prec), value)
+	}
+}
+
+// AddHash adds a value for a hash. Hashes with a higher precision than the
+// aggregator's are rolled up, lower precision and invalid hashes are ignored.
+func (a *Aggregator) AddHash(h Hash, value float64) {
+	if h.Precision() >= a.prec && h.validate() == nil {
+		a.add(h.ancestor(a.prec), value)
+	}
+}
+
+// Get returns the stats of a cell.
+func (a *Aggregator) Get(h Hash) (Stats, bool) {
+	s, ok := a.cells[h]
+	return s, ok
+}
+
+// Merge merges the values of another aggregator. It returns an error if the
+// other aggregator has a lower precision.
+func (a *Aggregator) Merge(o *Aggregator) error {
+	if o.prec < a.prec {
+		return errInvalidPrecision
+	}
+	for h, s := range o.cells {
+		a.merge(h.ancestor(a.prec), s)
+	}
+	return nil
+}
+
+// AddStats adds aggregated values for a hash, e.g. from the Cells of another
+// aggregator. Hashes with a higher precision than the aggregator's are rolled
+// up, lower precision and invalid hashes are ignored, as are stats without a
+// positive count.
+func (a *Aggregator) AddStats(h Hash, s Stats) {
+	if h.Precision() >= a.prec && h.validate() == nil {
+		a.merge(h.ancestor(a.prec), s)
+	}
+}
+
+// RollUp returns a new aggregator with values rolled up to a lower precision.
+// It returns an error if the precision is higher than the precision of a.
+func (a *Aggregator) RollUp(prec uint8) (*Aggregator, error) {
+	if prec < PrecisionMin || prec > a.prec {
+		return nil, errInvalidPrecision
+	}
+
+	res := NewAggregator(prec)
+	if err := res.Merge(a); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Iterate calls fn for each cell, in no particular order. Iteration stops if
+// fn returns false.
+func (a *Aggregator) Iterate(fn func(Hash, Stats) bool) {
+	for h, s := range a.cells {
+		if !fn(h, s) {
+			return
+		}
+	}
+}
+
+// Cells returns the stats of all cells, in ascending order.
+func (a *Aggregator) Cells() []CellStats {
+	res := make([]CellStats, 0, len(a.cells))
+	for h, s := range a.cells {
+		res = append(res, CellStats{Hash: h, Stats: s})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Hash < res[j].Hash })
+	return res
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Aggregators can be
+// marshaled by workers and merged elsewhere.
+func (a *Aggregator) MarshalBinary() ([]byte, error) {
+	data := []byte{a.prec}
+	data = binary.AppendUvarint(data, uint64(len(a.cells)))
+
+	var last uint64
+	for _, c := range a.Cells() {
+		base := c.Hash.base()
+		data = binary.AppendUvarint(data, base-last)
+		data = binary.AppendUvarint(data, uint64(c.Stats.Count))
+		for _, f := range [3]float64{c.Stats.Sum, c.Stats.Min, c.Stats.Max} {
+			data = binary.LittleEndian.AppendUint64(data, math.Float64bits(f))
+		}
+		last = base
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (a *Aggregator) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] < PrecisionMin || data[0] > PrecisionMax {
+		return errInvalidAggregator
+	}
+
+	res := NewAggregator(data[0])
+	n, sz := binary.Uvarint(data[1:])
+	if sz <= 0 {
+		return errInvalidAggregator
+	}
+	data = data[1+sz:]
+
+	var base uint64
+	for i := uint64(0); i < n; i++ {
+		delta, sz := binary.Uvarint(data)
+		if sz <= 0 || (i != 0 && delta == 0) {
+			return errInvalidAggregator
+		}
+		data = data[sz:]
+
+		count, sz := binary.Uvarint(data)
+		if sz <= 0 || count == 0 || count > math.MaxInt64 || len(data[sz:]) < 24 {
+			return errInvalidAggregator
+		}
+		data = data[sz:]
+
+		if base += delta; base >= 1<<(2*res.prec) {
+			return errInvalidAggregator
+		}
+		res.cells[newHash(base, res.prec)] = Stats{
+			Count: int64(count),
+			Sum:   math.Float64frombits(binary.LittleEndian.Uint64(data)),
+			Min:   math.Float64frombits(binary.LittleEndian.Uint64(data[8:])),
+			Max:   math.Float64frombits(binary.LittleEndian.Uint64(data[16:])),
+		}
+		data = data[24:]
+	}
+	if len(data) != 0 {
+		return errInvalidAggregator
+	}
+
+	*a = *res
+	return nil
+}
+
+// WriteGeoJSON writes all cells as a GeoJSON FeatureCollection of polygons,
+// with the stats as properties. Features are encoded directly into a single,
+// reused buffer. Non-finite values are written as null.
+func (a *Aggregator) WriteGeoJSON(w io.Writer) error {
+	buf := make([]byte, 0, 512)
+	buf = append(buf, `{"type":"FeatureCollection","features":[`...)
+
+	for i, c := range a.Cells() {
+		if i != 0 {
+			buf = append(buf, ',')
+		}
+
+		b := c.Hash.Decode()
+		buf = append(buf, `{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[`...)
+		for j, pt := range [5][2]float64{
+			{b.MinLon, b.MinLat},
+			{b.MaxLon, b.MinLat},
+			{b.MaxLon, b.MaxLat},
+			{b.MinLon, b.MaxLat},
+			{b.MinLon, b.MinLat},
+		} {
+			if j != 0 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, '[')
+			buf = appendJSONFloat(buf, pt[0])
+			buf = append(buf, ',')
+			buf = appendJSONFloat(buf, pt[1])
+			buf = append(buf, ']')
+		}
+
+		buf = append(buf, `]]},"properties":{"hash":"`...)
+		buf = strconv.AppendUint(buf, uint64(c.Hash), 10)
+		buf = append(buf, `","precision":`...)
+		buf = strconv.AppendUint(buf, uint64(c.Hash.Precision()), 10)
+		buf = append(buf, `,"count":`...)
+		buf = strconv.AppendInt(buf, c.Stats.Count, 10)
+		buf = append(buf, `,"sum":`...)
+		buf = appendJSONFloat(buf, c.Stats.Sum)
+		buf = append(buf, `,"min":`...)
+		buf = appendJSONFloat(buf, c.Stats.Min)
+		buf = append(buf, `,"max":`...)
+		buf = appendJSONFloat(buf, c.Stats.Max)
+		buf = append(buf, `,"mean":`...)
+		buf = appendJSONFloat(buf, c.Stats.Mean())
+		buf = append(buf, "}}"...)
+
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+		buf = buf[:0]
+	}
+
+	_, err := w.Write(append(buf, "]}"...))
+	return err
+}
+
+func (a *Aggregator) add(h Hash, v float64) {
+	s := a.cells[h]
+	s.add(v)
+	a.cells[h] = s
+}
+
+func (a *Aggregator) merge(h Hash, o Stats) {
+	if o.Count <= 0 {
+		return
+	}
+
+	s := a.cells[h]
+	s.merge(o)
+	a.cells[h] = s
+}
+
+// appendJSONFloat appends a float in the format used by encoding/json. Unlike
+// encoding/json, which fails on them, NaN and infinite values are encoded as
+// null.
+func appendJSONFloat(dst []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return append(dst, "null"...)
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+
+	// clean up e-09 to e-9
+	if n := len(dst); format == 'e' && n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+		dst[n-2] = dst[n-1]
+		dst = dst[:n-1]
+	}
+	return dst
+}
@@ -0,0 +1,202 @@
+package geohashi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Aggregator", func() {
+	var subject *Aggregator
+
+	get := func(h Hash) Stats {
+		s, ok := subject.Get(h)
+		Expect(ok).To(BeTrue())
+		return s
+	}
+
+	BeforeEach(func() {
+		subject = NewAggregator(20)
+		subject.Add(51.5178, -0.0823, 1)
+		subject.Add(51.5178, -0.0823, 3)
+		subject.Add(51.5256, -0.0875, 5)
+		subject.Add(89, 0, 7)
+	})
+
+	It("should aggregate", func() {
+		Expect(subject.Precision()).To(Equal(uint8(20)))
+		Expect(subject.Len()).To(Equal(2))
+		Expect(get(EncodeWithPrecision(51.5178, -0.0823, 20))).To(Equal(Stats{Count: 2, Sum: 4, Min: 1, Max: 3}))
+		Expect(get(EncodeWithPrecision(51.5256, -0.0875, 20))).To(Equal(Stats{Count: 1, Sum: 5, Min: 5, Max: 5}))
+
+		subject.AddHash(Encode(51.5256, -0.0875), -1)
+		subject.AddHash(EncodeWithPrecision(51.5256, -0.0875, 19), 100)
+		subject.AddHash(Hash(27<<52), 100)
+		subject.AddHash(Hash(20<<52|1<<40), 100)
+		Expect(subject.Len()).To(Equal(2))
+		Expect(get(EncodeWithPrecision(51.5256, -0.0875, 20))).To(Equal(Stats{Count: 2, Sum: 4, Min: -1, Max: 5}))
+
+		s, _ := subject.Get(EncodeWithPrecision(51.5178, -0.0823, 20))
+		Expect(s.Mean()).To(Equal(2.0))
+		Expect(Stats{}.Mean()).To(Equal(0.0))
+	})
+
+	It("should roll up", func() {
+		res, err := subject.RollUp(14)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Precision()).To(Equal(uint8(14)))
+		Expect(res.Cells()).To(Equal([]CellStats{
+			{Hash: EncodeWithPrecision(51.5178, -0.0823, 14), Stats: Stats{Count: 3, Sum: 9, Min: 1, Max: 5}},
+		}))
+
+		_, err = subject.RollUp(22)
+		Expect(err).To(MatchError(errInvalidPrecision))
+		_, err = subject.RollUp(0)
+		Expect(err).To(MatchError(errInvalidPrecision))
+	})
+
+	It("should add stats", func() {
+		other := NewAggregator(14)
+		for _, c := range subject.Cells() {
+			other.AddStats(c.Hash, c.Stats)
+		}
+		other.AddStats(EncodeWithPrecision(51.5178, -0.0823, 10), Stats{Count: 1})
+		other.AddStats(Hash(31<<52), Stats{Count: 1})
+
+		res, err := subject.RollUp(14)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(other.Cells()).To(Equal(res.Cells()))
+	})
+
+	It("should marshal binary", func() {
+		subject.Add(-80, -170, math.Inf(1))
+		data, err := subject.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+
+		res := new(Aggregator)
+		Expect(res.UnmarshalBinary(data)).To(Succeed())
+		Expect(res.Precision()).To(Equal(uint8(20)))
+		Expect(res.Cells()).To(Equal(subject.Cells()))
+
+		empty, err := NewAggregator(8).MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(empty).To(Equal([]byte{8, 0}))
+		Expect(res.UnmarshalBinary(empty)).To(Succeed())
+		Expect(res.Len()).To(Equal(0))
+
+		Expect(res.UnmarshalBinary(nil)).To(MatchError(errInvalidAggregator))
+		Expect(res.UnmarshalBinary([]byte{27, 0})).To(MatchError(errInvalidAggregator))
+		Expect(res.UnmarshalBinary(data[:len(data)-1])).To(MatchError(errInvalidAggregator))
+		Expect(res.UnmarshalBinary(append(data, 0))).To(MatchError(errInvalidAggregator))
+		Expect(res.UnmarshalBinary([]byte{1, 1, 4, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})).To(MatchError(errInvalidAggregator))
+	})
+
+	It("should merge", func() {
+		other := NewAggregator(22)
+		other.Add(51.5178, -0.0823, -2)
+		other.Add(48.8584, 2.2945, 9)
+
+		Expect(subject.Merge(other)).To(Succeed())
+		Expect(subject.Len()).To(Equal(3))
+		Expect(get(EncodeWithPrecision(51.5178, -0.0823, 20))).To(Equal(Stats{Count: 3, Sum: 2, Min: -2, Max: 3}))
+		Expect(subject.Merge(NewAggregator(19))).To(MatchError(errInvalidPrecision))
+	})
+
+	It("should ignore empty stats", func() {
+		n := subject.Len()
+		subject.AddStats(EncodeWithPrecision(10, 10, 20), Stats{})
+		subject.AddStats(EncodeWithPrecision(20, 20, 20), Stats{Count: -1, Sum: 1})
+		Expect(subject.Len()).To(Equal(n))
+
+		other := NewAggregator(20)
+		other.AddStats(EncodeWithPrecision(30, 30, 20), Stats{})
+		Expect(other.Len()).To(Equal(0))
+		Expect(subject.Merge(other)).To(Succeed())
+		Expect(subject.Len()).To(Equal(n))
+
+		data, err := subject.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+
+		res := new(Aggregator)
+		Expect(res.UnmarshalBinary(data)).To(Succeed())
+		Expect(res.Cells()).To(Equal(subject.Cells()))
+	})
+
+	It("should iterate", func() {
+		n := 0
+		subject.Iterate(func(_ Hash, s Stats) bool {
+			n += int(s.Count)
+			return true
+		})
+		Expect(n).To(Equal(3))
+	})
+
+	It("should export GeoJSON", func() {
+		buf := new(bytes.Buffer)
+		res, err := subject.RollUp(1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.WriteGeoJSON(buf)).To(Succeed())
+		Expect(buf.String()).To(MatchJSON(`{
+			"type": "FeatureCollection",
+			"features": [{
+				"type": "Feature",
+				"geometry": {
+					"type": "Polygon",
+					"coordinates": [[[-180, 0], [0, 0], [0, 85.05112878], [-180, 85.05112878], [-180, 0]]]
+				},
+				"properties": {"hash": "4503599627370497", "precision": 1, "count": 3, "sum": 9, "min": 1, "max": 5, "mean": 3}
+			}]
+		}`))
+
+		buf.Reset()
+		Expect(subject.WriteGeoJSON(buf)).To(Succeed())
+
+		var v struct{ Features []json.RawMessage }
+		Expect(json.Unmarshal(buf.Bytes(), &v)).To(Succeed())
+		Expect(v.Features).To(HaveLen(2))
+
+		agg := NewAggregator(1)
+		agg.Add(10, 10, math.Inf(1))
+		buf.Reset()
+		Expect(agg.WriteGeoJSON(buf)).To(Succeed())
+		Expect(json.Valid(buf.Bytes())).To(BeTrue())
+		Expect(buf.String()).To(ContainSubstring(`"sum":null`))
+	})
+
+	It("should format floats like encoding/json", func() {
+		for _, f := range []float64{0, 1, -2.5, 1e-7, -3.25e-9, 1e20, 1e21, 1.5e300, 5e-324, math.MaxFloat64} {
+			exp, err := json.Marshal(f)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(appendJSONFloat(nil, f))).To(Equal(string(exp)), "float %v", f)
+		}
+	})
+
+})
+
+func BenchmarkAggregator_Add(b *testing.B) {
+	agg := NewAggregator(16)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		agg.Add(51.5+float64(i%1000)/10000, -0.1+float64(i%997)/10000, 1)
+	}
+}
+
+func BenchmarkAggregator_WriteGeoJSON(b *testing.B) {
+	agg := NewAggregator(16)
+	for i := 0; i < 1000; i++ {
+		agg.Add(51.5+float64(i)/1000, -0.1+float64(i%97)/1000, 1)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := agg.WriteGeoJSON(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
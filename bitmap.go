@@ -0,0 +1,463 @@
+package geohashi
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"sort"
+)
+
+var errInvalidBitmap = errors.New("geohashi: invalid bitmap encoding")
+
+// maxArrayLen is the maximum cardinality of array containers, larger
+// containers are converted to bitsets.
+const maxArrayLen = 4096
+
+// Bitmap is a compressed set of hashes of the same precision. The set is
+// partitioned into containers by the high bits of the interleaved hash value.
+// Each container holds the lower 16 bits either in a sorted array or, when
+// dense, in a bitset. Since hashes are Z-ordered, spatially close cells tend
+// to share the same container.
+type Bitmap struct {
+	prec       uint8
+	keys       []uint64
+	containers []*container
+}
+
+// NewBitmap inits a new bitmap for hashes of the given precision.
+func NewBitmap(prec uint8) *Bitmap {
+	return &Bitmap{prec: prec}
+}
+
+// Precision returns the precision of the bitmap.
+func (b *Bitmap) Precision() uint8 { return b.prec }
+
+// Len returns the number of hashes in the bitmap.
+func (b *Bitmap) Len() int {
+	n := 0
+	for _, c := range b.containers {
+		n += c.n
+	}
+	return n
+}
+
+// Add adds a hash to the bitmap. It returns true if the hash was added and
+// false if it was already included, has a different precision or is invalid.
+func (b *Bitmap) Add(h Hash) bool {
+	if h.Precision() != b.prec || h.validate() != nil {
+		return false
+	}
+
+	key, lo := h.base()>>16, uint16(h.base())
+	i := b.search(key)
+	if i == len(b.keys) || b.keys[i] != key {
+		b.keys = append(b.keys, 0)
+		copy(b.keys[i+1:], b.keys[i:])
+		b.keys[i] = key
+
+		b.containers = append(b.containers, nil)
+		copy(b.containers[i+1:], b.containers[i:])
+		b.containers[i] = new(container)
+	}
+	return b.containers[i].add(lo)
+}
+
+// Remove removes a hash from the bitmap. It returns true if the hash was
+// included.
+func (b *Bitmap) Remove(h Hash) bool {
+	if h.Precision() != b.prec || h.validate() != nil {
+		return false
+	}
+
+	key, lo := h.base()>>16, uint16(h.base())
+	i := b.search(key)
+	if i == len(b.keys) || b.keys[i] != key {
+		return false
+	}
+
+	c := b.containers[i]
+	if !c.remove(lo) {
+		return false
+	}
+	if c.n == 0 {
+		b.keys = append(b.keys[:i], b.keys[i+1:]...)
+		b.containers = append(b.containers[:i], b.containers[i+1:]...)
+	}
+	return true
+}
+
+// Contains returns true if the hash is included in the bitmap.
+func (b *Bitmap) Contains(h Hash) bool {
+	if h.Precision() != b.prec || h.validate() != nil {
+		return false
+	}
+
+	key := h.base() >> 16
+	i := b.search(key)
+	return i < len(b.keys) && b.keys[i] == key && b.containers[i].contains(uint16(h.base()))
+}
+
+// Iterate calls fn for each hash in the bitmap, in Z-order. Iteration stops
+// if fn returns false.
+func (b *Bitmap) Iterate(fn func(Hash) bool) {
+	for i, c := range b.containers {
+		hi := b.keys[i] << 16
+		if !c.iterate(func(lo uint16) bool { return fn(newHash(hi|uint64(lo), b.prec)) }) {
+			return
+		}
+	}
+}
+
+// Union returns a new bitmap with all hashes contained in either b or o. It
+// panics if the bitmaps have different precisions.
+func (b *Bitmap) Union(o *Bitmap) *Bitmap {
+	b.mustMatch(o)
+
+	res := NewBitmap(b.prec)
+	i, j := 0, 0
+	for i < len(b.keys) || j < len(o.keys) {
+		switch {
+		case j == len(o.keys) || (i < len(b.keys) && b.keys[i] < o.keys[j]):
+			res.keys = append(res.keys, b.keys[i])
+			res.containers = append(res.containers, b.containers[i].clone())
+			i++
+		case i == len(b.keys) || o.keys[j] < b.keys[i]:
+			res.keys = append(res.keys, o.keys[j])
+			res.containers = append(res.containers, o.containers[j].clone())
+			j++
+		default:
+			res.keys = append(res.keys, b.keys[i])
+			res.containers = append(res.containers, b.containers[i].union(o.containers[j]))
+			i++
+			j++
+		}
+	}
+	return res
+}
+
+// Intersection returns a new bitmap with all hashes contained in both b and o.
+// It panics if the bitmaps have different precisions.
+func (b *Bitmap) Intersection(o *Bitmap) *Bitmap {
+	b.mustMatch(o)
+
+	res := NewBitmap(b.prec)
+	i, j := 0, 0
+	for i < len(b.keys) && j < len(o.keys) {
+		switch {
+		case b.keys[i] < o.keys[j]:
+			i++
+		case o.keys[j] < b.keys[i]:
+			j++
+		default:
+			if c := b.containers[i].intersect(o.containers[j]); c.n != 0 {
+				res.keys = append(res.keys, b.keys[i])
+				res.containers = append(res.containers, c)
+			}
+			i++
+			j++
+		}
+	}
+	return res
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (b *Bitmap) MarshalBinary() ([]byte, error) {
+	data := []byte{b.prec}
+	data = binary.AppendUvarint(data, uint64(len(b.keys)))
+	for i, c := range b.containers {
+		data = binary.AppendUvarint(data, b.keys[i])
+		data = binary.AppendUvarint(data, uint64(c.n))
+		if c.bits != nil {
+			for _, w := range c.bits {
+				data = binary.LittleEndian.AppendUint64(data, w)
+			}
+		} else {
+			for _, v := range c.array {
+				data = binary.LittleEndian.AppendUint16(data, v)
+			}
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (b *Bitmap) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] < PrecisionMin || data[0] > PrecisionMax {
+		return errInvalidBitmap
+	}
+
+	res := Bitmap{prec: data[0]}
+	data = data[1:]
+
+	// below precision 8, the grid has fewer than 1<<16 cells, all of which
+	// belong to the first container
+	limit := uint64(1) << 16
+	if n := uint64(1) << (2 * res.prec); n < limit {
+		limit = n
+	}
+
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > uint64(len(data)) {
+		return errInvalidBitmap
+	}
+	data = data[n:]
+
+	res.keys = make([]uint64, 0, int(size))
+	res.containers = make([]*container, 0, int(size))
+	for i := 0; i < int(size); i++ {
+		key, n := binary.Uvarint(data)
+		if n <= 0 || (i != 0 && key <= res.keys[i-1]) || key > (1<<(2*res.prec)-1)>>16 {
+			return errInvalidBitmap
+		}
+		data = data[n:]
+
+		card, n := binary.Uvarint(data)
+		if n <= 0 || card == 0 || card > limit {
+			return errInvalidBitmap
+		}
+		data = data[n:]
+
+		c := &container{n: int(card)}
+		if card > maxArrayLen {
+			if len(data) < 1024*8 {
+				return errInvalidBitmap
+			}
+			c.bits = make([]uint64, 1024)
+			cnt := 0
+			for j := range c.bits {
+				c.bits[j] = binary.LittleEndian.Uint64(data[j*8:])
+				if uint64(j*64) >= limit && c.bits[j] != 0 {
+					return errInvalidBitmap
+				}
+				cnt += bits.OnesCount64(c.bits[j])
+			}
+			if cnt != c.n {
+				return errInvalidBitmap
+			}
+			data = data[1024*8:]
+		} else {
+			if len(data) < c.n*2 {
+				return errInvalidBitmap
+			}
+			c.array = make([]uint16, c.n)
+			for j := range c.array {
+				c.array[j] = binary.LittleEndian.Uint16(data[j*2:])
+				if (j != 0 && c.array[j] <= c.array[j-1]) || uint64(c.array[j]) >= limit {
+					return errInvalidBitmap
+				}
+			}
+			data = data[c.n*2:]
+		}
+
+		res.keys = append(res.keys, key)
+		res.containers = append(res.containers, c)
+	}
+	if len(data) != 0 {
+		return errInvalidBitmap
+	}
+
+	*b = res
+	return nil
+}
+
+func (b *Bitmap) search(key uint64) int {
+	return sort.Search(len(b.keys), func(i int) bool { return b.keys[i] >= key })
+}
+
+func (b *Bitmap) mustMatch(o *Bitmap) {
+	if b.prec != o.prec {
+		panic("geohashi: bitmap precision mismatch")
+	}
+}
+
+// --------------------------------------------------------------------
+
+type container struct {
+	array []uint16 // sorted values, used when sparse
+	bits  []uint64 // bitset, used when dense
+	n     int
+}
+
+func (c *container) contains(v uint16) bool {
+	if c.bits != nil {
+		return c.bits[v>>6]&(1<<(v&63)) != 0
+	}
+	i := c.search(v)
+	return i < len(c.array) && c.array[i] == v
+}
+
+func (c *container) add(v uint16) bool {
+	if c.bits != nil {
+		w, m := v>>6, uint64(1)<<(v&63)
+		if c.bits[w]&m != 0 {
+			return false
+		}
+		c.bits[w] |= m
+		c.n++
+		return true
+	}
+
+	i := c.search(v)
+	if i < len(c.array) && c.array[i] == v {
+		return false
+	}
+	c.array = append(c.array, 0)
+	copy(c.array[i+1:], c.array[i:])
+	c.array[i] = v
+	c.n++
+
+	if c.n > maxArrayLen {
+		c.toBits()
+	}
+	return true
+}
+
+func (c *container) remove(v uint16) bool {
+	if c.bits != nil {
+		w, m := v>>6, uint64(1)<<(v&63)
+		if c.bits[w]&m == 0 {
+			return false
+		}
+		c.bits[w] &^= m
+		c.n--
+
+		if c.n <= maxArrayLen {
+			c.toArray()
+		}
+		return true
+	}
+
+	i := c.search(v)
+	if i == len(c.array) || c.array[i] != v {
+		return false
+	}
+	c.array = append(c.array[:i], c.array[i+1:]...)
+	c.n--
+	return true
+}
+
+func (c *container) iterate(fn func(uint16) bool) bool {
+	if c.bits == nil {
+		for _, v := range c.array {
+			if !fn(v) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for i, w := range c.bits {
+		for w != 0 {
+			t := bits.TrailingZeros64(w)
+			if !fn(uint16(i<<6 | t)) {
+				return false
+			}
+			w &= w - 1
+		}
+	}
+	return true
+}
+
+func (c *container) union(o *container) *container {
+	if c.bits == nil && o.bits == nil && c.n+o.n <= maxArrayLen {
+		res := &container{array: make([]uint16, 0, c.n+o.n)}
+		i, j := 0, 0
+		for i < len(c.array) || j < len(o.array) {
+			switch {
+			case j == len(o.array) || (i < len(c.array) && c.array[i] < o.array[j]):
+				res.array = append(res.array, c.array[i])
+				i++
+			case i == len(c.array) || o.array[j] < c.array[i]:
+				res.array = append(res.array, o.array[j])
+				j++
+			default:
+				res.array = append(res.array, c.array[i])
+				i++
+				j++
+			}
+		}
+		res.n = len(res.array)
+		return res
+	}
+
+	res := c.clone()
+	res.toBits()
+	if o.bits != nil {
+		res.n = 0
+		for i, w := range o.bits {
+			res.bits[i] |= w
+			res.n += bits.OnesCount64(res.bits[i])
+		}
+	} else {
+		for _, v := range o.array {
+			res.add(v)
+		}
+	}
+	if res.n <= maxArrayLen {
+		res.toArray()
+	}
+	return res
+}
+
+func (c *container) intersect(o *container) *container {
+	if c.bits != nil && o.bits != nil {
+		res := &container{bits: make([]uint64, 1024)}
+		for i, w := range c.bits {
+			res.bits[i] = w & o.bits[i]
+			res.n += bits.OnesCount64(res.bits[i])
+		}
+		if res.n <= maxArrayLen {
+			res.toArray()
+		}
+		return res
+	}
+
+	if c.bits != nil {
+		c, o = o, c
+	}
+	res := new(container)
+	for _, v := range c.array {
+		if o.contains(v) {
+			res.array = append(res.array, v)
+		}
+	}
+	res.n = len(res.array)
+	return res
+}
+
+func (c *container) clone() *container {
+	res := &container{n: c.n}
+	if c.bits != nil {
+		res.bits = append([]uint64(nil), c.bits...)
+	} else {
+		res.array = append([]uint16(nil), c.array...)
+	}
+	return res
+}
+
+func (c *container) toBits() {
+	if c.bits != nil {
+		return
+	}
+	c.bits = make([]uint64, 1024)
+	for _, v := range c.array {
+		c.bits[v>>6] |= 1 << (v & 63)
+	}
+	c.array = nil
+}
+
+func (c *container) toArray() {
+	if c.bits == nil {
+		return
+	}
+	c.array = make([]uint16, 0, c.n)
+	c.iterate(func(v uint16) bool {
+		c.array = append(c.array, v)
+		return true
+	})
+	c.bits = nil
+}
+
+func (c *container) search(v uint16) int {
+	return sort.Search(len(c.array), func(i int) bool { return c.array[i] >= v })
+}
@@ -0,0 +1,175 @@
+package geohashi
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bitmap", func() {
+	var subject *Bitmap
+
+	// fill adds all cells within an x/y grid offset
+	fill := func(b *Bitmap, x0, y0, w, h uint64) {
+		for x := x0; x < x0+w; x++ {
+			for y := y0; y < y0+h; y++ {
+				b.Add(newHash(interleave64(x, y), b.Precision()))
+			}
+		}
+	}
+
+	hashes := func(b *Bitmap) []Hash {
+		var res []Hash
+		b.Iterate(func(h Hash) bool {
+			res = append(res, h)
+			return true
+		})
+		return res
+	}
+
+	BeforeEach(func() {
+		subject = NewBitmap(20)
+	})
+
+	It("should add/remove", func() {
+		h1 := EncodeWithPrecision(51.52463, -0.08411, 20)
+		h2 := h1.MoveX(1)
+
+		Expect(subject.Add(h1)).To(BeTrue())
+		Expect(subject.Add(h1)).To(BeFalse())
+		Expect(subject.Add(h2)).To(BeTrue())
+		Expect(subject.Add(h1.Parent())).To(BeFalse())
+		Expect(subject.Len()).To(Equal(2))
+
+		Expect(subject.Contains(h1)).To(BeTrue())
+		Expect(subject.Contains(h1.MoveY(1))).To(BeFalse())
+		Expect(subject.Contains(h1.Parent())).To(BeFalse())
+
+		Expect(subject.Remove(h1)).To(BeTrue())
+		Expect(subject.Remove(h1)).To(BeFalse())
+		Expect(subject.Contains(h1)).To(BeFalse())
+		Expect(subject.Len()).To(Equal(1))
+	})
+
+	It("should switch between array and bitset containers", func() {
+		fill(subject, 0, 0, 128, 64)
+		Expect(subject.keys).To(HaveLen(1))
+		Expect(subject.containers[0].bits).To(HaveLen(1024))
+		Expect(subject.Len()).To(Equal(8192))
+		Expect(subject.Contains(newHash(interleave64(127, 63), 20))).To(BeTrue())
+		Expect(subject.Contains(newHash(interleave64(128, 63), 20))).To(BeFalse())
+
+		for x := uint64(0); x < 128; x++ {
+			for y := uint64(0); y < 34; y++ {
+				subject.Remove(newHash(interleave64(x, y), 20))
+			}
+		}
+		Expect(subject.Len()).To(Equal(3840))
+		Expect(subject.containers[0].bits).To(BeNil())
+		Expect(subject.containers[0].array).To(HaveLen(3840))
+	})
+
+	It("should iterate in Z-order", func() {
+		fill(subject, 255, 255, 2, 2)
+		Expect(hashes(subject)).To(Equal([]Hash{
+			newHash(interleave64(255, 255), 20),
+			newHash(interleave64(256, 255), 20),
+			newHash(interleave64(255, 256), 20),
+			newHash(interleave64(256, 256), 20),
+		}))
+		Expect(subject.keys).To(HaveLen(4))
+
+		n := 0
+		subject.Iterate(func(_ Hash) bool { n++; return n < 3 })
+		Expect(n).To(Equal(3))
+	})
+
+	It("should union/intersect", func() {
+		o := NewBitmap(20)
+		fill(subject, 0, 0, 100, 50)
+		fill(o, 50, 25, 100, 50)
+		fill(subject, 1000, 1000, 2, 2)
+		fill(o, 2000, 2000, 2, 2)
+
+		Expect(subject.Union(o).Len()).To(Equal(5000 + 5000 - 1250 + 8))
+		Expect(subject.Intersection(o).Len()).To(Equal(1250))
+		Expect(subject.Intersection(o).Contains(newHash(interleave64(50, 25), 20))).To(BeTrue())
+		Expect(subject.Intersection(o).Contains(newHash(interleave64(49, 25), 20))).To(BeFalse())
+		Expect(subject.Len()).To(Equal(5004))
+		Expect(o.Len()).To(Equal(5004))
+
+		Expect(func() { subject.Union(NewBitmap(19)) }).To(Panic())
+	})
+
+	It("should marshal/unmarshal", func() {
+		fill(subject, 0, 0, 100, 50)
+		fill(subject, 1000, 1000, 2, 2)
+
+		data, err := subject.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(data)).To(BeNumerically("<", 14000))
+
+		res := new(Bitmap)
+		Expect(res.UnmarshalBinary(data)).To(Succeed())
+		Expect(res.Precision()).To(Equal(uint8(20)))
+		Expect(hashes(res)).To(Equal(hashes(subject)))
+
+		Expect(res.UnmarshalBinary(data[:len(data)-1])).To(MatchError(errInvalidBitmap))
+		Expect(res.UnmarshalBinary([]byte{27, 0})).To(MatchError(errInvalidBitmap))
+	})
+
+	It("should reject values beyond low precisions", func() {
+		res := new(Bitmap)
+		Expect(res.UnmarshalBinary([]byte{2, 1, 0, 1, 15, 0})).To(Succeed())
+		Expect(hashes(res)).To(Equal([]Hash{newHash(15, 2)}))
+		Expect(res.UnmarshalBinary([]byte{2, 1, 0, 1, 16, 0})).To(MatchError(errInvalidBitmap))
+
+		full := NewBitmap(7)
+		fill(full, 0, 0, 128, 128)
+		data, err := full.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.UnmarshalBinary(data)).To(Succeed())
+		Expect(res.Len()).To(Equal(1 << 14))
+
+		// move the last value beyond the grid
+		words := data[len(data)-1024*8:]
+		words[2047] &^= 0x80
+		words[2048] |= 0x01
+		Expect(res.UnmarshalBinary(data)).To(MatchError(errInvalidBitmap))
+	})
+
+	It("should reject hashes beyond the grid", func() {
+		b := NewBitmap(7)
+		for _, h := range []Hash{newHash(1<<14, 7), newHash(1<<20, 7), newHash(1<<40|1, 7)} {
+			Expect(b.Add(h)).To(BeFalse(), "hash %x", uint64(h))
+			Expect(b.Contains(h)).To(BeFalse(), "hash %x", uint64(h))
+			Expect(b.Remove(h)).To(BeFalse(), "hash %x", uint64(h))
+		}
+		Expect(b.Len()).To(Equal(0))
+
+		Expect(b.Add(newHash(1<<14-1, 7))).To(BeTrue())
+		data, err := b.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+
+		res := new(Bitmap)
+		Expect(res.UnmarshalBinary(data)).To(Succeed())
+		Expect(hashes(res)).To(Equal([]Hash{newHash(1<<14-1, 7)}))
+	})
+
+})
+
+func BenchmarkBitmap_Contains(b *testing.B) {
+	bm := NewBitmap(20)
+	for x := uint64(0); x < 256; x++ {
+		for y := uint64(0); y < 256; y++ {
+			bm.Add(newHash(interleave64(x*3, y*3), 20))
+		}
+	}
+	hash := newHash(interleave64(300, 300), 20)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bm.Contains(hash)
+	}
+}
@@ -12,6 +12,16 @@ const (
 	s8 = 0x000FFFFFFFFFFFFF // 0000000000001111111111111111111111111111111111111111111111111111
 )
 
+// Sieve constants for three dimensions
+const (
+	t1 = 0x1249249249249249 // 0001001001001001001001001001001001001001001001001001001001001001
+	t2 = 0x10C30C30C30C30C3 // 0001000011000011000011000011000011000011000011000011000011000011
+	t3 = 0x100F00F00F00F00F // 0001000000001111000000001111000000001111000000001111000000001111
+	t4 = 0x001F0000FF0000FF // 0000000000011111000000000000000011111111000000000000000011111111
+	t5 = 0x001F00000000FFFF // 0000000000011111000000000000000000000000000000001111111111111111
+	t6 = 0x00000000001FFFFF // 0000000000000000000000000000000000000000000111111111111111111111
+)
+
 func interleave64(x, y uint64) uint64 {
 	x = (x | (x << 16)) & s5
 	y = (y | (y << 16)) & s5
@@ -55,3 +65,35 @@ func deinterleave64(n uint64) (x, y uint64) {
 
 	return
 }
+
+// interleave3 interleaves the lower 21 bits of three values, x occupies
+// every third bit starting with the lowest, followed by y and z.
+func interleave3(x, y, z uint64) uint64 {
+	return spread3(x) | spread3(y)<<1 | spread3(z)<<2
+}
+
+func deinterleave3(n uint64) (x, y, z uint64) {
+	return compact3(n), compact3(n >> 1), compact3(n >> 2)
+}
+
+// spread3 inserts two zero bits between each of the lower 21 bits of n.
+func spread3(n uint64) uint64 {
+	n &= t6
+	n = (n | (n << 32)) & t5
+	n = (n | (n << 16)) & t4
+	n = (n | (n << 8)) & t3
+	n = (n | (n << 4)) & t2
+	n = (n | (n << 2)) & t1
+	return n
+}
+
+// compact3 is the inverse of spread3, it collects every third bit of n.
+func compact3(n uint64) uint64 {
+	n &= t1
+	n = (n | (n >> 2)) & t2
+	n = (n | (n >> 4)) & t3
+	n = (n | (n >> 8)) & t4
+	n = (n | (n >> 16)) & t5
+	n = (n | (n >> 32)) & t6
+	return n
+}
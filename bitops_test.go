@@ -34,4 +34,32 @@ var _ = Describe("Bitops", func() {
 		}
 	})
 
+	tests3 := []struct {
+		x, y, z uint64
+		i       uint64
+	}{
+		{0, 0, 0, 0},
+		{1, 0, 0, 1},
+		{0, 1, 0, 2},
+		{0, 0, 1, 4},
+		{1, 1, 1, 7},
+		{2, 2, 2, 56},
+		{3, 5, 6, 0x1ab},
+		{0x1fffff, 0, 0, 0x1249249249249249},
+		{0x1fffff, 0x1fffff, 0x1fffff, 0x7fffffffffffffff},
+	}
+
+	It("should interleave three dimensions", func() {
+		for _, test := range tests3 {
+			Expect(interleave3(test.x, test.y, test.z)).To(Equal(test.i), "for %v", test)
+		}
+	})
+
+	It("should deinterleave three dimensions", func() {
+		for _, test := range tests3 {
+			x, y, z := deinterleave3(test.i)
+			Expect([]uint64{x, y, z}).To(Equal([]uint64{test.x, test.y, test.z}), "for %v", test)
+		}
+	})
+
 })
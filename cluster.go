@@ -0,0 +1,189 @@
+package geohashi
+
+import "sort"
+
+// ZoomPrecision returns the precision used for clustering at a web map zoom
+// level. At zoom level z, the map is 2^z tiles wide and each tile is divided
+// into 4x4 cells.
+func ZoomPrecision(zoom int) uint8 {
+	prec := zoom + 2
+	if prec < PrecisionMin {
+		return PrecisionMin
+	} else if prec > PrecisionMax {
+		return PrecisionMax
+	}
+	return uint8(prec)
+}
+
+// clusterZoomMax is the highest zoom level with a distinct precision, see
+// ZoomPrecision.
+const clusterZoomMax = PrecisionMax - 2
+
+// Cluster is a group of points.
+type Cluster struct {
+	// Lat and Lon is the centroid of the cluster.
+	Lat, Lon float64
+	// Count is the number of points in the cluster.
+	Count int
+	// Hash is the cell with the most points of the cluster.
+	Hash Hash
+}
+
+// ClusterPoints clusters points at a zoom level. Points are grouped by cell at
+// the zoom precision and clusters of adjacent cells with fewer than
+// mergeBelow points each are merged.
+func ClusterPoints(points []Point, zoom int, mergeBelow int) []Cluster {
+	return NewClusterPyramid(points, zoom, zoom).Clusters(zoom, mergeBelow)
+}
+
+// --------------------------------------------------------------------
+
+type clusterCell struct {
+	count          int
+	sumLat, sumLon float64
+}
+
+func (c *clusterCell) merge(o *clusterCell) {
+	c.count += o.count
+	c.sumLat += o.sumLat
+	c.sumLon += o.sumLon
+}
+
+// ClusterPyramid holds precomputed cell aggregates for a range of zoom levels.
+type ClusterPyramid struct {
+	minZoom int
+	levels  []map[Hash]*clusterCell // indexed by zoom - minZoom
+}
+
+// NewClusterPyramid builds a pyramid for all zoom levels between minZoom and
+// maxZoom in one pass. Points are aggregated at the precision of maxZoom and
+// rolled up to the lower zoom levels. Zoom levels are clamped to the range
+// with distinct precisions, i.e. 0 to 24.
+func NewClusterPyramid(points []Point, minZoom, maxZoom int) *ClusterPyramid {
+	minZoom = max(0, min(minZoom, clusterZoomMax))
+	maxZoom = max(minZoom, min(maxZoom, clusterZoomMax))
+
+	p := &ClusterPyramid{minZoom: minZoom, levels: make([]map[Hash]*clusterCell, maxZoom-minZoom+1)}
+	top := make(map[Hash]*clusterCell)
+	prec := ZoomPrecision(maxZoom)
+	for _, pt := range points {
+		if !ValidCoordinates(pt.Lat, pt.Lon) {
+			continue
+		}
+
+		h := encode(pt.Lat, pt.Lon, prec)
+		c, ok := top[h]
+		if !ok {
+			c = new(clusterCell)
+			top[h] = c
+		}
+		c.merge(&clusterCell{count: 1, sumLat: pt.Lat, sumLon: pt.Lon})
+	}
+	p.levels[len(p.levels)-1] = top
+
+	for i := len(p.levels) - 2; i >= 0; i-- {
+		child, level := p.levels[i+1], make(map[Hash]*clusterCell)
+		if ZoomPrecision(minZoom+i) == ZoomPrecision(minZoom+i+1) {
+			p.levels[i] = child
+			continue
+		}
+
+		for h, c := range child {
+			parent := h.Parent()
+			pc, ok := level[parent]
+			if !ok {
+				pc = new(clusterCell)
+				level[parent] = pc
+			}
+			pc.merge(c)
+		}
+		p.levels[i] = level
+	}
+	return p
+}
+
+// Clusters returns the clusters at a zoom level. Clusters of adjacent cells
+// with fewer than mergeBelow points each are merged. Results are ordered by
+// count, in descending order. It returns nil if the zoom level is not
+// included in the pyramid.
+func (p *ClusterPyramid) Clusters(zoom int, mergeBelow int) []Cluster {
+	i := zoom - p.minZoom
+	if i < 0 || i >= len(p.levels) {
+		return nil
+	}
+	level := p.levels[i]
+
+	cells := make([]Hash, 0, len(level))
+	for h := range level {
+		cells = append(cells, h)
+	}
+	sort.Slice(cells, func(i, j int) bool { return cells[i] < cells[j] })
+
+	// union small, adjacent cells
+	parents := make(map[Hash]Hash, len(cells))
+	var root func(Hash) Hash
+	root = func(h Hash) Hash {
+		parent, ok := parents[h]
+		if !ok || parent == h {
+			return h
+		}
+		r := root(parent)
+		parents[h] = r
+		return r
+	}
+
+	for _, h := range cells {
+		if level[h].count >= mergeBelow {
+			continue
+		}
+
+		// check east, north-east, north and south-east neighbours, latitudes
+		// must not wrap around
+		x, _ := deinterleave64(h.base())
+		neighbours := []Hash{h.MoveX(1)}
+		if x < uint64(1)<<h.Precision()-1 {
+			neighbours = append(neighbours, h.MoveY(1), h.MoveX(1).MoveY(1))
+		}
+		if x > 0 {
+			neighbours = append(neighbours, h.MoveX(1).MoveY(-1))
+		}
+
+		for _, n := range neighbours {
+			if c, ok := level[n]; ok && c.count < mergeBelow {
+				if a, b := root(h), root(n); a != b {
+					parents[b] = a
+				}
+			}
+		}
+	}
+
+	// build clusters
+	groups := make(map[Hash]*Cluster)
+	sums := make(map[Hash]*clusterCell)
+	var roots []Hash
+	for _, h := range cells {
+		r, c := root(h), level[h]
+		cl, ok := groups[r]
+		if !ok {
+			cl = &Cluster{Hash: h}
+			groups[r] = cl
+			sums[r] = new(clusterCell)
+			roots = append(roots, r)
+		}
+		if c.count > level[cl.Hash].count {
+			cl.Hash = h
+		}
+		cl.Count += c.count
+		sums[r].merge(c)
+	}
+
+	res := make([]Cluster, 0, len(roots))
+	for _, r := range roots {
+		cl, s := groups[r], sums[r]
+		cl.Lat = s.sumLat / float64(s.count)
+		cl.Lon = s.sumLon / float64(s.count)
+		res = append(res, *cl)
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Count > res[j].Count })
+	return res
+}
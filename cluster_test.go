@@ -0,0 +1,88 @@
+package geohashi
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cluster", func() {
+	points := []Point{
+		{Lat: 51.5178, Lon: -0.0823},
+		{Lat: 51.5180, Lon: -0.0820},
+		{Lat: 51.5256, Lon: -0.0875},
+		{Lat: 48.8566, Lon: 2.3522},
+		{Lat: 40.7128, Lon: -74.0060},
+		{Lat: 91, Lon: 0},
+	}
+
+	It("should calculate zoom precision", func() {
+		Expect(ZoomPrecision(-5)).To(Equal(uint8(1)))
+		Expect(ZoomPrecision(0)).To(Equal(uint8(2)))
+		Expect(ZoomPrecision(10)).To(Equal(uint8(12)))
+		Expect(ZoomPrecision(30)).To(Equal(uint8(26)))
+	})
+
+	It("should cluster points", func() {
+		res := ClusterPoints(points, 13, 0)
+		Expect(res).To(HaveLen(4))
+		Expect(res[0].Count).To(Equal(2))
+		Expect(res[0].Lat).To(BeNumerically("~", 51.5179, 1e-9))
+		Expect(res[0].Lon).To(BeNumerically("~", -0.08215, 1e-9))
+		Expect(res[0].Hash).To(Equal(EncodeWithPrecision(51.5178, -0.0823, 15)))
+
+		var total int
+		for _, c := range res {
+			total += c.Count
+		}
+		Expect(total).To(Equal(5))
+	})
+
+	It("should merge adjacent small clusters", func() {
+		res := ClusterPoints(points, 13, 3)
+		Expect(res).To(HaveLen(3))
+		Expect(res[0].Count).To(Equal(3))
+		Expect(res[0].Hash).To(Equal(EncodeWithPrecision(51.5178, -0.0823, 15)))
+		Expect(res[0].Lat).To(BeNumerically("~", (51.5178+51.5180+51.5256)/3, 1e-9))
+
+		res = ClusterPoints(points, 13, 2)
+		Expect(res).To(HaveLen(4))
+	})
+
+	It("should not merge across the poles", func() {
+		res := ClusterPoints([]Point{{Lat: 85, Lon: 0}, {Lat: -85, Lon: 0}}, 0, 10)
+		Expect(res).To(HaveLen(2))
+	})
+
+	It("should build pyramids", func() {
+		pyramid := NewClusterPyramid(points, 2, 13)
+		for zoom := 2; zoom <= 13; zoom++ {
+			Expect(pyramid.Clusters(zoom, 3)).To(Equal(ClusterPoints(points, zoom, 3)), "zoom %d", zoom)
+		}
+		Expect(pyramid.Clusters(1, 3)).To(BeNil())
+		Expect(pyramid.Clusters(14, 3)).To(BeNil())
+
+		res := pyramid.Clusters(2, 0)
+		Expect(res).To(HaveLen(3))
+		Expect(res[0].Count).To(Equal(3))
+
+		res = pyramid.Clusters(2, 4)
+		Expect(res).To(HaveLen(2))
+		Expect(res[0].Count).To(Equal(4))
+	})
+
+	It("should clamp pyramid zoom levels", func() {
+		pyramid := NewClusterPyramid(points, 20, math.MaxInt)
+		Expect(pyramid.levels).To(HaveLen(clusterZoomMax - 20 + 1))
+		Expect(pyramid.Clusters(clusterZoomMax, 0)).To(Equal(ClusterPoints(points, clusterZoomMax, 0)))
+		Expect(pyramid.Clusters(clusterZoomMax+1, 0)).To(BeNil())
+
+		pyramid = NewClusterPyramid(points, math.MaxInt, math.MaxInt)
+		Expect(pyramid.levels).To(HaveLen(1))
+		Expect(pyramid.Clusters(clusterZoomMax, 0)).To(Equal(ClusterPoints(points, clusterZoomMax, 0)))
+
+		pyramid = NewClusterPyramid(points, math.MinInt, 1)
+		Expect(pyramid.levels).To(HaveLen(2))
+	})
+})
@@ -0,0 +1,41 @@
+// Command geohashi-http runs the geohashi JSON HTTP service.
+//
+// Usage:
+//
+//	geohashi-http [-addr :8080] [-precision 16] [-max-cells 100000]
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bsm/geohashi"
+	"github.com/bsm/geohashi/httpapi"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	prec := flag.Uint("precision", 16, "default precision")
+	maxCells := flag.Int("max-cells", 100000, "maximum number of cells per covering")
+	flag.Parse()
+
+	if *prec < geohashi.PrecisionMin || *prec > geohashi.PrecisionMax {
+		log.Fatalf("invalid -precision %d, must be between %d and %d", *prec, geohashi.PrecisionMin, geohashi.PrecisionMax)
+	}
+
+	srv := &http.Server{
+		Addr: *addr,
+		Handler: httpapi.NewHandler(&httpapi.Options{
+			DefaultPrecision: uint8(*prec),
+			MaxCells:         *maxCells,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("listening on %s", *addr)
+	if err := srv.ListenAndServe(); err != nil {
+		log.Fatal(err)
+	}
+}
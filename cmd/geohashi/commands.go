@@ -0,0 +1,360 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bsm/geohashi"
+)
+
+// maxCells limits the number of cells produced by a single command.
+const maxCells = 1 << 20
+
+var errTooManyCells = fmt.Errorf("too many cells, the limit is %d", maxCells)
+
+type options struct {
+	prec    uint
+	bbox    string
+	radius  string
+	geojson string
+	flat    bool
+}
+
+type command struct {
+	nargs   int  // number of (required) arguments/fields per record
+	single  bool // the command returns a single hash
+	verbose bool // the command prints details in text mode
+
+	flags  func(*flag.FlagSet, *options)
+	record func(*options, []string) ([]geohashi.Hash, error)
+	run    func(*options, io.Reader) ([]geohashi.Hash, error)
+}
+
+var commands = map[string]command{
+	"encode": {
+		nargs:  2,
+		single: true,
+		flags: func(fs *flag.FlagSet, o *options) {
+			fs.UintVar(&o.prec, "p", geohashi.PrecisionMax, "")
+		},
+		record: encode,
+	},
+	"decode": {
+		nargs:   1,
+		single:  true,
+		verbose: true,
+		record: func(_ *options, fields []string) ([]geohashi.Hash, error) {
+			h, err := parseHash(fields[0])
+			if err != nil {
+				return nil, err
+			}
+			return []geohashi.Hash{h}, nil
+		},
+	},
+	"parent": {
+		nargs:  1,
+		single: true,
+		flags: func(fs *flag.FlagSet, o *options) {
+			fs.UintVar(&o.prec, "p", 0, "")
+		},
+		record: parent,
+	},
+	"children": {
+		nargs: 1,
+		flags: func(fs *flag.FlagSet, o *options) {
+			fs.UintVar(&o.prec, "p", 0, "")
+		},
+		record: children,
+	},
+	"neighbors": {
+		nargs:  1,
+		record: neighbors,
+	},
+	"cover": {
+		flags: func(fs *flag.FlagSet, o *options) {
+			fs.UintVar(&o.prec, "p", 16, "")
+			fs.StringVar(&o.bbox, "bbox", "", "")
+			fs.StringVar(&o.radius, "radius", "", "")
+			fs.StringVar(&o.geojson, "geojson", "", "")
+			fs.BoolVar(&o.flat, "flat", false, "")
+		},
+		run: cover,
+	},
+}
+
+// runBatch reads CSV records from r and prints the results of each record.
+func runBatch(cmd command, opts *options, r io.Reader, out *printer) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		line, _ := cr.FieldPos(0)
+		if len(fields) < cmd.nargs {
+			return fmt.Errorf("line %d: expected %d field(s)", line, cmd.nargs)
+		}
+		for i, f := range fields {
+			fields[i] = strings.TrimSpace(f)
+		}
+
+		hashes, err := cmd.record(opts, fields)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := out.print(hashes, cmd.single); err != nil {
+			return err
+		}
+	}
+}
+
+// --------------------------------------------------------------------
+
+func encode(opts *options, fields []string) ([]geohashi.Hash, error) {
+	lat, err := parseFloat("latitude", fields[0])
+	if err != nil {
+		return nil, err
+	}
+	lon, err := parseFloat("longitude", fields[1])
+	if err != nil {
+		return nil, err
+	}
+	if !geohashi.ValidCoordinates(lat, lon) {
+		return nil, fmt.Errorf("coordinates %s,%s out of range", fields[0], fields[1])
+	}
+
+	prec := opts.prec
+	if len(fields) > 2 && fields[2] != "" {
+		if prec, err = parsePrecision(fields[2]); err != nil {
+			return nil, err
+		}
+	}
+	if err := validatePrecision(prec); err != nil {
+		return nil, err
+	}
+	return []geohashi.Hash{geohashi.EncodeWithPrecision(lat, lon, uint8(prec))}, nil
+}
+
+func parent(opts *options, fields []string) ([]geohashi.Hash, error) {
+	h, err := parseHash(fields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	prec := opts.prec
+	if prec == 0 {
+		prec = uint(h.Precision()) - 1
+	}
+	if prec < geohashi.PrecisionMin || prec >= uint(h.Precision()) {
+		return nil, fmt.Errorf("precision must be between %d and %d", geohashi.PrecisionMin, h.Precision()-1)
+	}
+
+	for h.Precision() > uint8(prec) {
+		h = h.Parent()
+	}
+	return []geohashi.Hash{h}, nil
+}
+
+func children(opts *options, fields []string) ([]geohashi.Hash, error) {
+	h, err := parseHash(fields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	prec := opts.prec
+	if prec == 0 {
+		prec = uint(h.Precision()) + 1
+	}
+	if prec <= uint(h.Precision()) || prec > geohashi.PrecisionMax {
+		return nil, fmt.Errorf("precision must be between %d and %d", h.Precision()+1, geohashi.PrecisionMax)
+	}
+	return descendants(nil, h, uint8(prec))
+}
+
+func neighbors(_ *options, fields []string) ([]geohashi.Hash, error) {
+	h, err := parseHash(fields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return h.Neighbors(), nil
+}
+
+func cover(opts *options, stdin io.Reader) ([]geohashi.Hash, error) {
+	if err := validatePrecision(opts.prec); err != nil {
+		return nil, err
+	}
+	prec := uint8(opts.prec)
+
+	var fences []geohashi.Fence
+	var points []geohashi.Point
+	switch {
+	case opts.bbox != "" && opts.radius == "" && opts.geojson == "":
+		vv, err := parseFloats(opts.bbox, 4)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -bbox: %w", err)
+		}
+		fences = append(fences, geohashi.Area{MinLon: vv[0], MinLat: vv[1], MaxLon: vv[2], MaxLat: vv[3]})
+	case opts.radius != "" && opts.bbox == "" && opts.geojson == "":
+		vv, err := parseFloats(opts.radius, 3)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -radius: %w", err)
+		}
+		fences = append(fences, geohashi.Circle{Lat: vv[0], Lon: vv[1], Radius: vv[2]})
+	case opts.geojson != "" && opts.bbox == "" && opts.radius == "":
+		data, err := readFile(opts.geojson, stdin)
+		if err != nil {
+			return nil, err
+		}
+		pts, polys, err := geohashi.ParseGeoJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range polys {
+			fences = append(fences, p)
+		}
+		points = pts
+	default:
+		return nil, fmt.Errorf("%w: cover expects exactly one of -bbox, -radius or -geojson", errUsage)
+	}
+
+	if len(points) > maxCells {
+		return nil, errTooManyCells
+	}
+
+	cells := make([]geohashi.Hash, 0, len(points))
+	for _, pt := range points {
+		if !geohashi.ValidCoordinates(pt.Lat, pt.Lon) {
+			return nil, fmt.Errorf("coordinates %v,%v out of range", pt.Lat, pt.Lon)
+		}
+		cells = append(cells, geohashi.EncodeWithPrecision(pt.Lat, pt.Lon, prec))
+	}
+	for _, f := range fences {
+		budget := maxCells - len(cells)
+		if budget <= 0 {
+			return nil, errTooManyCells
+		}
+
+		cov, err := geohashi.CoverFenceLimit(f, prec, budget)
+		if err != nil {
+			return nil, errTooManyCells
+		}
+		cells = append(cells, cov.Interior...)
+		cells = append(cells, cov.Boundary...)
+	}
+
+	if opts.flat {
+		var err error
+		flat := make([]geohashi.Hash, 0, len(cells))
+		for _, h := range cells {
+			if flat, err = descendants(flat, h, prec); err != nil {
+				return nil, err
+			}
+		}
+		cells = flat
+	}
+
+	sort.Slice(cells, func(i, j int) bool { return cells[i] < cells[j] })
+	return dedup(cells), nil
+}
+
+// --------------------------------------------------------------------
+
+// descendants appends all descendants of h at the given precision to dst.
+func descendants(dst []geohashi.Hash, h geohashi.Hash, prec uint8) ([]geohashi.Hash, error) {
+	if h.Precision() >= prec {
+		return append(dst, h), nil
+	}
+	if n := len(dst) + 1<<(2*(prec-h.Precision())); n > maxCells {
+		return nil, errTooManyCells
+	}
+
+	var err error
+	for _, c := range h.Children() {
+		if dst, err = descendants(dst, c, prec); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+func dedup(hashes []geohashi.Hash) []geohashi.Hash {
+	res := hashes[:0]
+	for i, h := range hashes {
+		if i == 0 || h != hashes[i-1] {
+			res = append(res, h)
+		}
+	}
+	return res
+}
+
+func parseHash(s string) (geohashi.Hash, error) {
+	var h geohashi.Hash
+	if err := h.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid hash %q", s)
+	}
+	return h, nil
+}
+
+func parsePrecision(s string) (uint, error) {
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid precision %q", s)
+	}
+	return uint(v), nil
+}
+
+func validatePrecision(prec uint) error {
+	if prec < geohashi.PrecisionMin || prec > geohashi.PrecisionMax {
+		return fmt.Errorf("precision must be between %d and %d", geohashi.PrecisionMin, geohashi.PrecisionMax)
+	}
+	return nil
+}
+
+func parseFloat(name, s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) {
+		return 0, fmt.Errorf("invalid %s %q", name, s)
+	}
+	return v, nil
+}
+
+func parseFloats(s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d comma-separated values", n)
+	}
+
+	vv := make([]float64, 0, n)
+	for _, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || math.IsNaN(v) {
+			return nil, errors.New("invalid number " + strconv.Quote(p))
+		}
+		vv = append(vv, v)
+	}
+	return vv, nil
+}
+
+// readFile reads a file, "-" reads stdin.
+func readFile(name string, stdin io.Reader) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(name)
+}
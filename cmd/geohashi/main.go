@@ -0,0 +1,161 @@
+// Command geohashi inspects and converts numeric geohashes.
+//
+// Usage:
+//
+//	geohashi encode LAT LON [-p prec]
+//	geohashi decode HASH
+//	geohashi parent HASH [-p prec]
+//	geohashi children HASH [-p prec]
+//	geohashi neighbors HASH
+//	geohashi cover (-bbox MINLON,MINLAT,MAXLON,MAXLAT | -radius LAT,LON,METERS | -geojson FILE) [-p prec] [-flat]
+//
+// All commands accept -o text|json|geojson to select the output format.
+// With -batch, encode, decode, parent, children and neighbors read CSV
+// records from stdin instead of positional arguments. Records for encode
+// contain LAT,LON[,PREC], all other commands expect a HASH per record.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+var errUsage = errors.New("usage error")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); errors.Is(err, errUsage) {
+		fmt.Fprintln(os.Stderr, err)
+		usage(os.Stderr)
+		os.Exit(2)
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, "geohashi:", err)
+		os.Exit(1)
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprint(w, `Usage:
+  geohashi encode LAT LON [-p prec]
+  geohashi decode HASH
+  geohashi parent HASH [-p prec]
+  geohashi children HASH [-p prec]
+  geohashi neighbors HASH
+  geohashi cover (-bbox MINLON,MINLAT,MAXLON,MAXLAT | -radius LAT,LON,METERS | -geojson FILE) [-p prec] [-flat]
+
+Options:
+  -o FORMAT   output format: text, json or geojson (default: text)
+  -batch      read CSV records from stdin (encode, decode, parent, children, neighbors)
+`)
+}
+
+// run executes a command.
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: missing command", errUsage)
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := fs.String("o", "text", "")
+	batch := fs.Bool("batch", false, "")
+	opts := new(options)
+	if cmd.flags != nil {
+		cmd.flags(fs, opts)
+	}
+
+	pos, err := parseArgs(fs, args[1:])
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	out, err := newPrinter(stdout, *format, cmd.verbose)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if *batch {
+		if cmd.record == nil {
+			return fmt.Errorf("%w: %s does not support -batch", errUsage, args[0])
+		}
+		if err := runBatch(cmd, opts, stdin, out); err != nil {
+			return err
+		}
+	} else if cmd.record != nil {
+		if len(pos) != cmd.nargs {
+			return fmt.Errorf("%w: %s expects %d argument(s)", errUsage, args[0], cmd.nargs)
+		}
+		hashes, err := cmd.record(opts, pos)
+		if err != nil {
+			return err
+		}
+		if err := out.print(hashes, cmd.single); err != nil {
+			return err
+		}
+	} else {
+		if len(pos) != 0 {
+			return fmt.Errorf("%w: %s expects no arguments", errUsage, args[0])
+		}
+		hashes, err := cmd.run(opts, stdin)
+		if err != nil {
+			return err
+		}
+		if err := out.print(hashes, false); err != nil {
+			return err
+		}
+	}
+	return out.flush()
+}
+
+// parseArgs parses flags and returns positional arguments. Unlike
+// flag.FlagSet.Parse, it accepts flags after positional arguments and treats
+// negative numbers as positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var flags, pos []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) < 2 || arg[0] != '-' || isNumber(arg) {
+			pos = append(pos, arg)
+			continue
+		}
+
+		flags = append(flags, arg)
+		if arg == "--" {
+			pos = append(pos, args[i+1:]...)
+			break
+		}
+
+		// append the value of non-boolean flags, unless passed as -flag=value
+		name := arg[1:]
+		if name[0] == '-' {
+			name = name[1:]
+		}
+		if f := fs.Lookup(name); f != nil && !isBoolFlag(f) && i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+
+	if err := fs.Parse(flags); err != nil {
+		return nil, err
+	}
+	return pos, nil
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bsm/geohashi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("run", func() {
+	exec := func(stdin string, args ...string) (string, error) {
+		out := new(bytes.Buffer)
+		err := run(args, strings.NewReader(stdin), out)
+		return out.String(), err
+	}
+
+	It("should encode", func() {
+		Expect(exec("", "encode", "51.52463", "-0.08411", "-p", "10")).To(Equal("45035996274208702\n"))
+		Expect(exec("", "encode", "-p=20", "51.52463", "-0.08411")).To(Equal("90072520759854475\n"))
+		Expect(exec("", "encode", "51.52463", "-0.08411")).To(Equal("119257148484531281\n"))
+		Expect(exec("", "encode", "51.52463", "-0.08411", "-p", "10", "-o", "json")).To(MatchJSON(`{
+			"hash": "45035996274208702",
+			"precision": 10,
+			"bbox": [-0.3515625, 51.49580062851564, 0, 51.66191611441408]
+		}`))
+	})
+
+	It("should decode", func() {
+		Expect(exec("", "decode", "4503599627370499")).To(Equal("4503599627370499\tprecision=1\tbbox=0,0,180,85.05112878\tcenter=42.52556439,90\n"))
+	})
+
+	It("should return parents and children", func() {
+		Expect(exec("", "parent", "90072520759854475", "-p", "10")).To(Equal("45035996274208702\n"))
+		Expect(exec("", "parent", "9007199254740999")).To(Equal("4503599627370497\n"))
+		Expect(exec("", "children", "4503599627370497")).To(Equal("9007199254740996\n9007199254740997\n9007199254740998\n9007199254740999\n"))
+		out, err := exec("", "children", "4503599627370497", "-p", "3")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Count(out, "\n")).To(Equal(16))
+	})
+
+	It("should return neighbors", func() {
+		out, err := exec("", "neighbors", "45035996274208702", "-o", "json")
+		Expect(err).NotTo(HaveOccurred())
+
+		var v []geohashi.DetailedHash
+		Expect(json.Unmarshal([]byte(out), &v)).To(Succeed())
+		Expect(v).To(HaveLen(8))
+		Expect(geohashi.Hash(v[0])).To(Equal(geohashi.Hash(45035996274208702).MoveY(1)))
+
+		north := geohashi.EncodeWithPrecision(geohashi.LatMax, 0, 3)
+		out, err = exec("", "neighbors", strconv.FormatUint(uint64(north), 10))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Fields(out)).To(Equal([]string{
+			strconv.FormatUint(uint64(north.MoveX(1)), 10),
+			strconv.FormatUint(uint64(north.MoveY(-1).MoveX(1)), 10),
+			strconv.FormatUint(uint64(north.MoveY(-1)), 10),
+			strconv.FormatUint(uint64(north.MoveY(-1).MoveX(-1)), 10),
+			strconv.FormatUint(uint64(north.MoveX(-1)), 10),
+		}))
+	})
+
+	It("should cover", func() {
+		Expect(exec("", "cover", "-bbox", "0,0,180,85", "-p", "1")).To(Equal("4503599627370499\n"))
+		Expect(exec("", "cover", "-bbox", "0,0,180,85", "-p", "2", "-flat")).To(Equal("9007199254741004\n9007199254741005\n9007199254741006\n9007199254741007\n"))
+
+		out, err := exec("", "cover", "-radius", "51.52463,-0.08411,1000", "-p", "16")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Count(out, "\n")).To(BeNumerically(">", 4))
+
+		out, err = exec(`{"type": "MultiPoint", "coordinates": [[-0.08411, 51.52463], [-0.08411, 51.52463]]}`, "cover", "-geojson", "-", "-p", "10")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("45035996274208702\n"))
+
+		_, err = exec("", "cover", "-bbox", "-10,-10,10,10", "-p", "26")
+		Expect(err).To(MatchError(errTooManyCells))
+		_, err = exec("", "cover", "-radius", "0,0,1000000", "-p", "26")
+		Expect(err).To(MatchError(errTooManyCells))
+
+		dir, err := os.MkdirTemp("", "geohashi-test")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		file := filepath.Join(dir, "fence.json")
+		Expect(os.WriteFile(file, []byte(`{"type": "Polygon", "coordinates": [[[0, 0], [180, 0], [180, 85], [0, 0]]]}`), 0o644)).To(Succeed())
+		out, err = exec("", "cover", "-geojson", file, "-p", "4", "-o", "geojson")
+		Expect(err).NotTo(HaveOccurred())
+
+		var fc struct{ Features []json.RawMessage }
+		Expect(json.Unmarshal([]byte(out), &fc)).To(Succeed())
+		Expect(len(fc.Features)).To(BeNumerically(">", 4))
+	})
+
+	It("should process batches", func() {
+		Expect(exec("# lat,lon,prec\n51.52463,-0.08411\n51.52463, -0.08411, 10\n", "encode", "-batch", "-p", "20")).
+			To(Equal("90072520759854475\n45035996274208702\n"))
+		Expect(exec("4503599627370499\n4503599627370497\n", "decode", "-batch", "-o", "json")).
+			To(Equal(`{"hash":"4503599627370499","precision":1,"bbox":[0,0,180,85.05112878]}` + "\n" +
+				`{"hash":"4503599627370497","precision":1,"bbox":[-180,0,0,85.05112878]}` + "\n"))
+
+		out, err := exec("4503599627370499\n4503599627370497\n", "children", "-batch", "-o", "geojson")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Count(out, `"Feature"`)).To(Equal(8))
+
+		_, err = exec("51.5,-0.1\nx,1\n", "encode", "-batch")
+		Expect(err).To(MatchError(`line 2: invalid latitude "x"`))
+		_, err = exec("51.5\n", "encode", "-batch")
+		Expect(err).To(MatchError(`line 1: expected 2 field(s)`))
+	})
+
+	It("should fail on bad inputs", func() {
+		_, err := exec("")
+		Expect(err).To(MatchError(errUsage))
+		_, err = exec("", "unknown")
+		Expect(err).To(MatchError(errUsage))
+		_, err = exec("", "encode", "1")
+		Expect(err).To(MatchError(errUsage))
+		_, err = exec("", "encode", "1", "2", "-x")
+		Expect(err).To(MatchError(errUsage))
+		_, err = exec("", "encode", "1", "2", "-o", "xml")
+		Expect(err).To(MatchError(errUsage))
+		_, err = exec("", "cover", "-batch")
+		Expect(err).To(MatchError(errUsage))
+		_, err = exec("", "cover", "-bbox", "0,0,1,1", "-radius", "0,0,1")
+		Expect(err).To(MatchError(errUsage))
+
+		_, err = exec("", "encode", "91", "0")
+		Expect(err).To(MatchError("coordinates 91,0 out of range"))
+		_, err = exec("", "encode", "NaN", "0")
+		Expect(err).To(MatchError(`invalid latitude "NaN"`))
+		_, err = exec(`{"type": "Point", "coordinates": [0, 89]}`, "cover", "-geojson", "-", "-p", "26")
+		Expect(err).To(MatchError("coordinates 89,0 out of range"))
+		_, err = exec("", "cover", "-radius", "0,0,NaN")
+		Expect(err).To(MatchError(`invalid -radius: invalid number "NaN"`))
+		_, err = exec("", "encode", "1", "2", "-p", "27")
+		Expect(err).To(MatchError("precision must be between 1 and 26"))
+		_, err = exec("", "decode", "123")
+		Expect(err).To(MatchError(`invalid hash "123"`))
+		_, err = exec("", "parent", "4503599627370499")
+		Expect(err).To(MatchError("precision must be between 1 and 0"))
+		_, err = exec("", "children", "4503599627370499", "-p", "20")
+		Expect(err).To(MatchError("too many cells, the limit is 1048576"))
+		_, err = exec("", "cover", "-bbox", "0,0,1")
+		Expect(err).To(MatchError("invalid -bbox: expected 4 comma-separated values"))
+	})
+})
+
+// --------------------------------------------------------------------
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "github.com/bsm/geohashi/cmd/geohashi")
+}
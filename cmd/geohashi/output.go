@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/bsm/geohashi"
+)
+
+type printer struct {
+	w       *bufio.Writer
+	enc     *json.Encoder
+	format  string
+	verbose bool
+	all     []geohashi.Hash // collected for GeoJSON output
+}
+
+func newPrinter(w io.Writer, format string, verbose bool) (*printer, error) {
+	switch format {
+	case "text", "json", "geojson":
+	default:
+		return nil, fmt.Errorf("invalid output format %q", format)
+	}
+
+	bw := bufio.NewWriter(w)
+	return &printer{w: bw, enc: json.NewEncoder(bw), format: format, verbose: verbose}, nil
+}
+
+// print prints the result of a command. In JSON mode, single results are
+// printed as objects, all others as arrays, one line per result.
+func (p *printer) print(hashes []geohashi.Hash, single bool) error {
+	switch p.format {
+	case "json":
+		details := make([]geohashi.DetailedHash, 0, len(hashes))
+		for _, h := range hashes {
+			details = append(details, geohashi.DetailedHash(h))
+		}
+		if single && len(details) == 1 {
+			return p.enc.Encode(details[0])
+		}
+		return p.enc.Encode(details)
+	case "geojson":
+		p.all = append(p.all, hashes...)
+		return nil
+	}
+
+	for _, h := range hashes {
+		if p.verbose {
+			a := h.Decode()
+			lat, lon := a.Center()
+			fmt.Fprintf(p.w, "%d\tprecision=%d\tbbox=%s,%s,%s,%s\tcenter=%s,%s\n", h, h.Precision(),
+				formatFloat(a.MinLon), formatFloat(a.MinLat), formatFloat(a.MaxLon), formatFloat(a.MaxLat),
+				formatFloat(lat), formatFloat(lon))
+		} else {
+			fmt.Fprintf(p.w, "%d\n", h)
+		}
+	}
+	return nil
+}
+
+// flush writes buffered output.
+func (p *printer) flush() error {
+	if p.format == "geojson" {
+		if err := p.enc.Encode(geohashi.NewFeatureCollection(p.all, nil)); err != nil {
+			return err
+		}
+	}
+	return p.w.Flush()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
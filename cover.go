@@ -0,0 +1,268 @@
+package geohashi
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+var errTooManyCells = errors.New("geohashi: too many cells")
+
+// locatorCoverCells is the maximum number of hashes used to cover the areas
+// of location codes, such as Plus Codes or grid references.
+const locatorCoverCells = 16
+
+// MaxCoverCells is the maximum number of hashes returned by Area.Cover and
+// Tile.Cover, and of tiles returned by Hash.Tiles.
+const MaxCoverCells = 1 << 20
+
+// Cover returns all hashes of the given precision which intersect with the
+// area, in ascending order. It returns nil if the area requires more than
+// MaxCoverCells hashes, use CoverLimit to choose a different limit.
+func (a Area) Cover(prec uint8) []Hash {
+	res, _ := a.CoverLimit(prec, MaxCoverCells)
+	return res
+}
+
+// CoverLimit is like Cover, but returns an error if the area requires more
+// than maxCells hashes.
+func (a Area) CoverLimit(prec uint8, maxCells int) ([]Hash, error) {
+	if prec < PrecisionMin || prec > PrecisionMax {
+		return nil, errInvalidPrecision
+	}
+	if a.MinLat > a.MaxLat || a.MinLon > a.MaxLon {
+		return nil, errInvalidArea
+	}
+
+	x0, x1, y0, y1 := a.gridBounds(prec)
+	if n := (x1 - x0 + 1) * (y1 - y0 + 1); maxCells < 0 || n > uint64(maxCells) {
+		return nil, errTooManyCells
+	}
+
+	res := make([]Hash, 0, (x1-x0+1)*(y1-y0+1))
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			res = append(res, newHash(interleave64(x, y), prec))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res, nil
+}
+
+// Covering is a set of hashes which cover a fence.
+type Covering struct {
+	// Interior hashes are entirely within the fence.
+	Interior []Hash
+	// Boundary hashes intersect with the boundary of the fence.
+	Boundary []Hash
+}
+
+// CoverFence returns a covering of the fence. Cells which are entirely within
+// the fence are returned at the lowest possible precision, cells on the
+// boundary of the fence are refined up to the given precision. It returns an
+// empty covering if the fence requires more than MaxCoverCells hashes, use
+// CoverFenceLimit to choose a different limit.
+func CoverFence(f Fence, prec uint8) Covering {
+	c, _ := CoverFenceLimit(f, prec, MaxCoverCells)
+	return c
+}
+
+// CoverFenceLimit is like CoverFence, but gives up as soon as the covering
+// exceeds maxCells hashes and returns an error instead.
+func CoverFenceLimit(f Fence, prec uint8, maxCells int) (Covering, error) {
+	var c Covering
+	if prec < PrecisionMin || prec > PrecisionMax {
+		return c, errInvalidPrecision
+	}
+
+	n := 0
+	var visit func(Hash) bool
+	visit = func(h Hash) bool {
+		switch f.Relate(h.Decode()) {
+		case Inside:
+			c.Interior = append(c.Interior, h)
+		case Boundary:
+			if h.Precision() < prec {
+				for _, child := range h.Children() {
+					if !visit(child) {
+						return false
+					}
+				}
+				return true
+			}
+			c.Boundary = append(c.Boundary, h)
+		default:
+			return true
+		}
+
+		n++
+		return n <= maxCells
+	}
+
+	bounds := f.Bounds()
+	start := bounds.coverPrecision(16)
+	if start > prec {
+		start = prec
+	}
+	for _, h := range bounds.Cover(start) {
+		if !visit(h) {
+			return Covering{}, errTooManyCells
+		}
+	}
+	return c, nil
+}
+
+// EstimateCells estimates the number of boundary cells of a fence covering
+// at the given precision, without computing the covering. The boundary of
+// polygons is measured along their edges, so that complex shapes are not
+// underestimated. It returns 0 for invalid precisions.
+func EstimateCells(f Fence, prec uint8) float64 {
+	if prec < PrecisionMin || prec > PrecisionMax {
+		return 0
+	}
+
+	n := float64(uint64(1) << prec)
+	cells := func(dLat, dLon float64) float64 {
+		return math.Abs(dLat)/latScale*n + math.Abs(dLon)/lonScale*n
+	}
+
+	if p, ok := f.(Polygon); ok {
+		var sum float64
+		for i, a := range p {
+			b := p[(i+1)%len(p)]
+			sum += cells(b.Lat-a.Lat, b.Lon-a.Lon) + 1
+		}
+		return sum
+	}
+
+	a := f.Bounds()
+	return 2 * cells(a.MaxLat-a.MinLat, a.MaxLon-a.MinLon)
+}
+
+// coverCells returns the hashes which cover the area at the highest precision
+// which requires no more than maxCells hashes, in ascending order. Like
+// Tile.Cover, hashes which only touch the edges of the area are not included.
+// Areas are clipped at the latitude limits, it returns an error if the area
+// lies entirely beyond them.
+func (a Area) coverCells(maxCells uint64) ([]Hash, error) {
+	if a.MinLat >= LatMax || a.MaxLat <= LatMin {
+		return nil, errInvalidArea
+	}
+	a.MinLat, a.MaxLat = math.Max(a.MinLat, LatMin), math.Min(a.MaxLat, LatMax)
+
+	fx0, fx1 := (a.MinLat-LatMin)/latScale, (a.MaxLat-LatMin)/latScale
+	fy0, fy1 := (a.MinLon-LonMin)/lonScale, (a.MaxLon-LonMin)/lonScale
+
+	prec := uint8(PrecisionMax)
+	x0, x1 := gridSpan(fx0, fx1, prec)
+	y0, y1 := gridSpan(fy0, fy1, prec)
+	for prec > PrecisionMin && (x1-x0+1)*(y1-y0+1) > maxCells {
+		prec--
+		x0, x1 = gridSpan(fx0, fx1, prec)
+		y0, y1 = gridSpan(fy0, fy1, prec)
+	}
+
+	res := make([]Hash, 0, (x1-x0+1)*(y1-y0+1))
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			res = append(res, newHash(interleave64(x, y), prec))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res, nil
+}
+
+// enclosingCode returns the first location code, tried in order of the given
+// lengths, which encloses the entire area.
+func (a Area) enclosingCode(lengths []int, encode func(lat, lon float64, n int) (string, error), decode func(string) (Area, error)) (string, error) {
+	if a.MinLat > a.MaxLat || a.MinLon > a.MaxLon {
+		return "", errInvalidArea
+	}
+
+	for _, n := range lengths {
+		code, err := encode(a.MinLat, a.MinLon, n)
+		if err != nil {
+			return "", err
+		}
+		if c, err := decode(code); err == nil && c.MaxLat >= a.MaxLat && c.MaxLon >= a.MaxLon {
+			return code, nil
+		}
+	}
+	return "", errInvalidArea
+}
+
+// gridBounds returns the lat (x) and lon (y) grid index bounds of an area at
+// the given precision.
+func (a Area) gridBounds(prec uint8) (x0, x1, y0, y1 uint64) {
+	x0 = gridIndex((a.MinLat-LatMin)/latScale, prec)
+	x1 = gridIndex((a.MaxLat-LatMin)/latScale, prec)
+	y0 = gridIndex((a.MinLon-LonMin)/lonScale, prec)
+	y1 = gridIndex((a.MaxLon-LonMin)/lonScale, prec)
+	return
+}
+
+// coverPrecision returns the highest precision at which the area can be
+// covered by at most maxCells cells.
+func (a Area) coverPrecision(maxCells uint64) uint8 {
+	for prec := uint8(PrecisionMax); prec > PrecisionMin; prec-- {
+		x0, x1, y0, y1 := a.gridBounds(prec)
+		if (x1-x0+1)*(y1-y0+1) <= maxCells {
+			return prec
+		}
+	}
+	return PrecisionMin
+}
+
+// encode is like EncodeWithPrecision, but does not validate the precision or
+// NaN coordinates.
+func encode(lat, lon float64, prec uint8) Hash {
+	x := gridIndex((lat-LatMin)/latScale, prec)
+	y := gridIndex((lon-LonMin)/lonScale, prec)
+	return newHash(interleave64(x, y), prec)
+}
+
+// gridIndex converts a relative offset (0..1) into a grid index.
+func gridIndex(f float64, prec uint8) uint64 {
+	n := uint64(1) << prec
+	if f <= 0 {
+		return 0
+	} else if i := uint64(f * float64(n)); i < n {
+		return i
+	}
+	return n - 1
+}
+
+// --------------------------------------------------------------------
+
+// hashRange is a half-open range of hashes with maximum precision.
+type hashRange struct{ min, max Hash }
+
+// span returns the range of maximum precision hashes contained in h.
+func (h Hash) span() hashRange {
+	shift := 2 * (PrecisionMax - h.Precision())
+	return hashRange{
+		min: newHash(h.base()<<shift, PrecisionMax),
+		max: newHash((h.base()+1)<<shift, PrecisionMax),
+	}
+}
+
+// ranges returns sorted ranges of maximum precision hashes which cover the
+// area.
+func (a Area) ranges() []hashRange {
+	return a.coverRanges(a.coverPrecision(16))
+}
+
+// coverRanges returns sorted ranges of maximum precision hashes which cover
+// the area with cells of the given precision.
+func (a Area) coverRanges(prec uint8) []hashRange {
+	var res []hashRange
+	for _, c := range a.Cover(prec) {
+		r := c.span()
+		if n := len(res); n != 0 && res[n-1].max == r.min {
+			res[n-1].max = r.max
+			continue
+		}
+		res = append(res, r)
+	}
+	return res
+}
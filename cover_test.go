@@ -0,0 +1,154 @@
+package geohashi
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cover", func() {
+
+	It("should cover areas", func() {
+		Expect(Area{MinLat: -10, MaxLat: 10, MinLon: -10, MaxLon: 10}.Cover(1)).To(Equal([]Hash{
+			0x0010000000000000,
+			0x0010000000000001,
+			0x0010000000000002,
+			0x0010000000000003,
+		}))
+		Expect(Area{MinLat: 10, MaxLat: 20, MinLon: 10, MaxLon: 20}.Cover(1)).To(Equal([]Hash{
+			0x0010000000000003,
+		}))
+
+		hash := Hash(108221613442698053)
+		area := hash.Decode()
+		lat, lon := area.Center()
+		Expect(Area{MinLat: lat, MaxLat: lat, MinLon: lon, MaxLon: lon}.Cover(24)).To(Equal([]Hash{hash}))
+		Expect(Area{MinLat: lat, MaxLat: area.MaxLat + 1e-9, MinLon: lon, MaxLon: area.MaxLon + 1e-9}.Cover(24)).To(ConsistOf(
+			hash, hash.MoveX(1), hash.MoveY(1), hash.MoveX(1).MoveY(1),
+		))
+
+		Expect(Area{MinLat: 10, MaxLat: 0, MinLon: 0, MaxLon: 10}.Cover(1)).To(BeNil())
+		Expect(Area{MinLat: 0, MaxLat: 10, MinLon: 0, MaxLon: 10}.Cover(27)).To(BeNil())
+	})
+
+	It("should limit covers", func() {
+		world := Area{MinLat: LatMin, MaxLat: LatMax, MinLon: LonMin, MaxLon: LonMax}
+		Expect(world.Cover(PrecisionMax)).To(BeNil())
+		Expect(world.Cover(10)).To(HaveLen(1 << 20))
+
+		_, err := world.CoverLimit(PrecisionMax, 1000)
+		Expect(err).To(MatchError(errTooManyCells))
+		_, err = world.CoverLimit(2, 15)
+		Expect(err).To(MatchError(errTooManyCells))
+		_, err = world.CoverLimit(2, -1)
+		Expect(err).To(MatchError(errTooManyCells))
+		Expect(world.CoverLimit(2, 16)).To(HaveLen(16))
+		_, err = world.CoverLimit(0, 16)
+		Expect(err).To(MatchError(errInvalidPrecision))
+		_, err = Area{MinLat: 10, MaxLat: 0}.CoverLimit(2, 16)
+		Expect(err).To(MatchError(errInvalidArea))
+	})
+
+	It("should clamp coordinates", func() {
+		Expect(Area{MinLat: -90, MaxLat: 90, MinLon: -200, MaxLon: 200}.Cover(2)).To(HaveLen(16))
+		Expect(encode(LatMax, LonMax, 2)).To(Equal(Hash(0x002000000000000f)))
+		Expect(encode(51.52463, -0.08411, 20)).To(Equal(EncodeWithPrecision(51.52463, -0.08411, 20)))
+	})
+
+	It("should calculate ranges", func() {
+		hash := Hash(108221613442698053)
+		area := hash.Decode()
+		area.MaxLat -= 1e-9
+		area.MaxLon -= 1e-9
+		Expect(area.ranges()).To(Equal([]hashRange{hash.span()}))
+
+		span := hash.span()
+		Expect(span.min.Precision()).To(Equal(uint8(PrecisionMax)))
+		Expect(span.min.Decode().MinLat).To(BeNumerically("~", hash.Decode().MinLat, 1e-9))
+		Expect(span.min.Decode().MinLon).To(BeNumerically("~", hash.Decode().MinLon, 1e-9))
+		Expect(span.max - span.min).To(Equal(Hash(16)))
+
+		Expect(Area{MinLat: -10, MaxLat: 10, MinLon: -10, MaxLon: 10}.ranges()).To(HaveLen(4))
+	})
+
+})
+
+var _ = Describe("CoverFence", func() {
+
+	It("should cover circles", func() {
+		circle := Circle{Lat: 51.5246, Lon: -0.0841, Radius: 1000}
+		cov := CoverFence(circle, 16)
+		Expect(cov.Interior).NotTo(BeEmpty())
+		Expect(cov.Boundary).NotTo(BeEmpty())
+
+		for _, h := range cov.Interior {
+			Expect(h.Precision()).To(BeNumerically("<=", 16))
+			Expect(circle.Relate(h.Decode())).To(Equal(Inside))
+		}
+		for _, h := range cov.Boundary {
+			Expect(h.Precision()).To(Equal(uint8(16)))
+		}
+
+		// all points within the circle must be covered
+		b := circle.Bounds()
+		for lat := b.MinLat; lat <= b.MaxLat; lat += 0.0005 {
+			for lon := b.MinLon; lon <= b.MaxLon; lon += 0.0005 {
+				if !circle.Contains(lat, lon) {
+					continue
+				}
+
+				n := 0
+				for _, h := range append(cov.Interior, cov.Boundary...) {
+					if h.Decode().Contains(lat, lon) {
+						n++
+					}
+				}
+				Expect(n).To(BeNumerically(">=", 1), "for %v,%v", lat, lon)
+			}
+		}
+	})
+
+	It("should cover polygons", func() {
+		cov := CoverFence(Polygon{{0, 0}, {0, 10}, {5, 10}, {5, 5}, {10, 5}, {10, 0}}, 8)
+		Expect(len(cov.Interior)).To(BeNumerically(">", 10))
+		Expect(len(cov.Boundary)).To(BeNumerically(">", 10))
+		Expect(CoverFence(Polygon{{0, 0}, {0, 10}, {5, 10}}, 0)).To(Equal(Covering{}))
+	})
+
+	It("should limit coverings", func() {
+		poly := Polygon{{0, 0}, {0, 10}, {5, 10}, {5, 5}, {10, 5}, {10, 0}}
+		cov := CoverFence(poly, 8)
+		n := len(cov.Interior) + len(cov.Boundary)
+
+		Expect(CoverFenceLimit(poly, 8, n)).To(Equal(cov))
+
+		_, err := CoverFenceLimit(poly, 8, n-1)
+		Expect(err).To(MatchError(errTooManyCells))
+		_, err = CoverFenceLimit(poly, 8, 0)
+		Expect(err).To(MatchError(errTooManyCells))
+		_, err = CoverFenceLimit(poly, 8, -1)
+		Expect(err).To(MatchError(errTooManyCells))
+		Expect(CoverFence(Area{MinLat: -80, MaxLat: 80, MinLon: -180, MaxLon: 180}, PrecisionMax)).To(Equal(Covering{}))
+		_, err = CoverFenceLimit(Area{MinLat: -80, MaxLat: 80, MinLon: -180, MaxLon: 180}, PrecisionMax, 1000)
+		Expect(err).To(MatchError(errTooManyCells))
+		_, err = CoverFenceLimit(poly, 0, n)
+		Expect(err).To(MatchError(errInvalidPrecision))
+	})
+
+	It("should estimate cells", func() {
+		box := Area{MinLat: 0, MaxLat: 10, MinLon: 0, MaxLon: 10}
+		cov := CoverFence(box, 10)
+		Expect(EstimateCells(box, 10)).To(BeNumerically(">=", len(cov.Boundary)))
+		Expect(EstimateCells(box, 11)).To(BeNumerically("~", 2*EstimateCells(box, 10), 1e-9))
+		Expect(EstimateCells(box, 0)).To(Equal(0.0))
+
+		// a comb, with a small bounding box but a long boundary
+		comb := Polygon{{0, 0}}
+		for i := 0; i < 40; i++ {
+			x := float64(i) / 40
+			comb = append(comb, Point{Lat: 1, Lon: x}, Point{Lat: 1, Lon: x + 0.0125}, Point{Lat: 0.1, Lon: x + 0.0125})
+		}
+		comb = append(comb, Point{Lat: 0, Lon: 1})
+		Expect(EstimateCells(comb, 14)).To(BeNumerically(">", 5*EstimateCells(comb.Bounds(), 14)))
+	})
+
+})
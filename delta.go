@@ -0,0 +1,263 @@
+package geohashi
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+)
+
+// DefaultRestartInterval is the default number of hashes between restart
+// points in delta-encoded sequences.
+const DefaultRestartInterval = 16
+
+// MaxRestartInterval is the maximum number of hashes between restart points,
+// larger intervals are capped.
+const MaxRestartInterval = 1 << 20
+
+var (
+	errNotSorted    = errors.New("geohashi: hashes are not sorted")
+	errInvalidDelta = errors.New("geohashi: invalid delta encoding")
+)
+
+// Delta-encoded sequences start with a uvarint restart interval, followed by
+// the uvarint encoded hashes. The first hash of each restart block is stored
+// verbatim, every following hash as the delta to its predecessor.
+type deltaEncoder struct {
+	interval int
+	n        int
+	last     Hash
+}
+
+func newDeltaEncoder(interval int) deltaEncoder {
+	if interval < 1 {
+		interval = DefaultRestartInterval
+	} else if interval > MaxRestartInterval {
+		interval = MaxRestartInterval
+	}
+	return deltaEncoder{interval: interval}
+}
+
+func (e *deltaEncoder) header(dst []byte) []byte {
+	return binary.AppendUvarint(dst, uint64(e.interval))
+}
+
+func (e *deltaEncoder) append(dst []byte, h Hash) ([]byte, error) {
+	if e.n != 0 && h < e.last {
+		return dst, errNotSorted
+	}
+
+	delta := h
+	if e.n%e.interval != 0 {
+		delta -= e.last
+	}
+	e.last = h
+	e.n++
+	return binary.AppendUvarint(dst, uint64(delta)), nil
+}
+
+// AppendDeltas appends sorted hashes in delta-encoding to dst. A restart
+// point, i.e. an unencoded hash, is inserted every restartInterval hashes.
+func AppendDeltas(dst []byte, hashes []Hash, restartInterval int) ([]byte, error) {
+	enc := newDeltaEncoder(restartInterval)
+	dst = enc.header(dst)
+	for _, h := range hashes {
+		var err error
+		if dst, err = enc.append(dst, h); err != nil {
+			return dst, err
+		}
+	}
+	return dst, nil
+}
+
+// --------------------------------------------------------------------
+
+// DeltaWriter writes a sorted sequence of hashes to a stream.
+type DeltaWriter struct {
+	w   *bufio.Writer
+	enc deltaEncoder
+	buf []byte
+}
+
+// NewDeltaWriter inits a new writer. A restart point is inserted every
+// restartInterval hashes, pass 0 to use the DefaultRestartInterval.
+func NewDeltaWriter(w io.Writer, restartInterval int) *DeltaWriter {
+	enc := newDeltaEncoder(restartInterval)
+	return &DeltaWriter{
+		w:   bufio.NewWriter(w),
+		enc: enc,
+		buf: enc.header(make([]byte, 0, binary.MaxVarintLen64)),
+	}
+}
+
+// Write appends a hash to the stream. Hashes must be written in ascending order.
+func (w *DeltaWriter) Write(h Hash) error {
+	var err error
+	if w.buf, err = w.enc.append(w.buf, h); err != nil {
+		return err
+	}
+
+	_, err = w.w.Write(w.buf)
+	w.buf = w.buf[:0]
+	return err
+}
+
+// Flush flushes buffered data to the underlying writer.
+func (w *DeltaWriter) Flush() error {
+	if len(w.buf) != 0 {
+		if _, err := w.w.Write(w.buf); err != nil {
+			return err
+		}
+		w.buf = w.buf[:0]
+	}
+	return w.w.Flush()
+}
+
+// --------------------------------------------------------------------
+
+// DeltaReader reads a sequence of hashes written by DeltaWriter.
+type DeltaReader struct {
+	r        io.ByteReader
+	interval int
+	n        int
+	last     Hash
+}
+
+// NewDeltaReader inits a new reader.
+func NewDeltaReader(r io.Reader) *DeltaReader {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &DeltaReader{r: br}
+}
+
+// Read returns the next hash in the sequence. It returns io.EOF at the end
+// of the stream.
+func (r *DeltaReader) Read() (Hash, error) {
+	if r.interval == 0 {
+		interval, err := binary.ReadUvarint(r.r)
+		if err == io.EOF {
+			return 0, errInvalidDelta
+		} else if err != nil {
+			return 0, err
+		} else if interval == 0 || interval > MaxRestartInterval {
+			return 0, errInvalidDelta
+		}
+		r.interval = int(interval)
+	}
+
+	delta, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = errInvalidDelta
+		}
+		return 0, err
+	}
+
+	if r.n%r.interval == 0 {
+		r.last = Hash(delta)
+	} else {
+		r.last += Hash(delta)
+	}
+	r.n++
+	return r.last, nil
+}
+
+// --------------------------------------------------------------------
+
+// DeltaList provides random access to a delta-encoded sequence of hashes, as
+// produced by AppendDeltas or DeltaWriter.
+type DeltaList struct {
+	data     []byte
+	interval int
+	restarts []int // offsets of restart points
+	n        int
+}
+
+// NewDeltaList parses data and inits a DeltaList. The data is retained, but
+// never modified.
+func NewDeltaList(data []byte) (*DeltaList, error) {
+	interval, n := binary.Uvarint(data)
+	if n <= 0 || interval == 0 || interval > MaxRestartInterval {
+		return nil, errInvalidDelta
+	}
+
+	l := &DeltaList{data: data, interval: int(interval)}
+	for pos := n; pos < len(data); l.n++ {
+		if l.n%l.interval == 0 {
+			l.restarts = append(l.restarts, pos)
+		}
+
+		_, n := binary.Uvarint(data[pos:])
+		if n <= 0 {
+			return nil, errInvalidDelta
+		}
+		pos += n
+	}
+	return l, nil
+}
+
+// Len returns the number of hashes in the list.
+func (l *DeltaList) Len() int { return l.n }
+
+// At returns the hash at index i. It panics if i is out of range.
+func (l *DeltaList) At(i int) Hash {
+	if i < 0 || i >= l.n {
+		panic("geohashi: index out of range")
+	}
+
+	pos := l.restarts[i/l.interval]
+	h := Hash(0)
+	for j := i - i%l.interval; j <= i; j++ {
+		delta, n := binary.Uvarint(l.data[pos:])
+		pos += n
+		h += Hash(delta)
+	}
+	return h
+}
+
+// Search returns the smallest index i at which At(i) >= h. It returns Len()
+// if there is no such index.
+func (l *DeltaList) Search(h Hash) int {
+	// find the first restart block with a first hash >= h
+	b := sort.Search(len(l.restarts), func(b int) bool {
+		v, _ := binary.Uvarint(l.data[l.restarts[b]:])
+		return Hash(v) >= h
+	})
+	if b == 0 {
+		return 0
+	}
+
+	// scan the previous block
+	b--
+	i, pos, v := b*l.interval, l.restarts[b], Hash(0)
+	for ; i < l.n && i < (b+1)*l.interval; i++ {
+		delta, n := binary.Uvarint(l.data[pos:])
+		pos += n
+		if v += Hash(delta); v >= h {
+			break
+		}
+	}
+	return i
+}
+
+// AppendTo appends all hashes to dst.
+func (l *DeltaList) AppendTo(dst []Hash) []Hash {
+	if l.n == 0 {
+		return dst
+	}
+
+	pos, h := l.restarts[0], Hash(0)
+	for i := 0; i < l.n; i++ {
+		delta, n := binary.Uvarint(l.data[pos:])
+		pos += n
+		if i%l.interval == 0 {
+			h = 0
+		}
+		h += Hash(delta)
+		dst = append(dst, h)
+	}
+	return dst
+}
@@ -0,0 +1,155 @@
+package geohashi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Delta encoding", func() {
+	var hashes []Hash
+
+	BeforeEach(func() {
+		rnd := rand.New(rand.NewSource(1))
+		hashes = make([]Hash, 1000)
+		for i := range hashes {
+			hashes[i] = Encode(51.5+rnd.Float64()/10, -0.1+rnd.Float64()/10)
+		}
+		sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+	})
+
+	It("should encode", func() {
+		data, err := AppendDeltas(nil, []Hash{4503599627370497, 4503599627370499, 4503599627370499}, 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal([]byte{
+			0x02,                                           // interval
+			0x81, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x08, // restart
+			0x02,                                           // delta
+			0x83, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x08, // restart
+		}))
+
+		_, err = AppendDeltas(nil, []Hash{3, 2}, 0)
+		Expect(err).To(MatchError(errNotSorted))
+	})
+
+	It("should compress", func() {
+		data, err := AppendDeltas(nil, hashes, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(data)).To(BeNumerically("<", 8*len(hashes)/2))
+	})
+
+	It("should stream", func() {
+		buf := new(bytes.Buffer)
+		w := NewDeltaWriter(buf, 10)
+		for _, h := range hashes {
+			Expect(w.Write(h)).To(Succeed())
+		}
+		Expect(w.Write(hashes[0])).To(MatchError(errNotSorted))
+		Expect(w.Flush()).To(Succeed())
+
+		data, err := AppendDeltas(nil, hashes, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buf.Bytes()).To(Equal(data))
+
+		r := NewDeltaReader(buf)
+		for _, h := range hashes {
+			Expect(r.Read()).To(Equal(h))
+		}
+		_, err = r.Read()
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("should stream empty sequences", func() {
+		buf := new(bytes.Buffer)
+		Expect(NewDeltaWriter(buf, 0).Flush()).To(Succeed())
+		Expect(buf.Bytes()).To(Equal([]byte{DefaultRestartInterval}))
+
+		_, err := NewDeltaReader(buf).Read()
+		Expect(err).To(Equal(io.EOF))
+
+		_, err = NewDeltaReader(buf).Read()
+		Expect(err).To(MatchError(errInvalidDelta))
+	})
+
+	It("should provide random access", func() {
+		data, err := AppendDeltas(nil, hashes, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		list, err := NewDeltaList(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Len()).To(Equal(len(hashes)))
+		Expect(list.AppendTo(nil)).To(Equal(hashes))
+
+		for i, h := range hashes {
+			Expect(list.At(i)).To(Equal(h))
+			Expect(list.Search(h)).To(Equal(sort.Search(len(hashes), func(j int) bool { return hashes[j] >= h })))
+			Expect(list.Search(h + 1)).To(Equal(sort.Search(len(hashes), func(j int) bool { return hashes[j] > h })))
+		}
+		Expect(list.Search(0)).To(Equal(0))
+		Expect(list.Search(hashes[len(hashes)-1] + 1)).To(Equal(len(hashes)))
+
+		_, err = NewDeltaList(data[:len(data)-1])
+		Expect(err).To(MatchError(errInvalidDelta))
+	})
+
+	It("should search duplicates across restart blocks", func() {
+		h := hashes[0]
+		data, err := AppendDeltas(nil, []Hash{h, h, h, h, h + 1}, 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		list, err := NewDeltaList(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Search(h - 1)).To(Equal(0))
+		Expect(list.Search(h)).To(Equal(0))
+		Expect(list.Search(h + 1)).To(Equal(4))
+		Expect(list.Search(h + 2)).To(Equal(5))
+
+		data, err = AppendDeltas(nil, []Hash{h, h + 1, h + 1, h + 1, h + 1, h + 2}, 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		list, err = NewDeltaList(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Search(h + 1)).To(Equal(1))
+		Expect(list.Search(h + 2)).To(Equal(5))
+	})
+
+	It("should reject invalid restart intervals", func() {
+		for _, interval := range []uint64{0, MaxRestartInterval + 1, 1 << 63, math.MaxUint64} {
+			data := binary.AppendUvarint(nil, interval)
+			data = binary.AppendUvarint(data, 1)
+
+			_, err := NewDeltaList(data)
+			Expect(err).To(MatchError(errInvalidDelta))
+			_, err = NewDeltaReader(bytes.NewReader(data)).Read()
+			Expect(err).To(MatchError(errInvalidDelta))
+		}
+
+		data, err := AppendDeltas(nil, hashes, MaxRestartInterval+1)
+		Expect(err).NotTo(HaveOccurred())
+		list, err := NewDeltaList(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.AppendTo(nil)).To(Equal(hashes))
+	})
+
+})
+
+func BenchmarkDeltaList_At(b *testing.B) {
+	hashes := make([]Hash, 10000)
+	for i := range hashes {
+		hashes[i] = Hash(i * 1000)
+	}
+	data, _ := AppendDeltas(nil, hashes, 0)
+	list, _ := NewDeltaList(data)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		list.At(i % len(hashes))
+	}
+}
@@ -0,0 +1,83 @@
+package geohashi
+
+import "math"
+
+// EarthRadius is the radius of the earth in meters, as used by Redis for
+// distance calculations.
+const EarthRadius = 6372797.560856
+
+// Distance returns the great-circle distance between two coordinates in
+// meters, using the haversine formula.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	φ1, φ2 := deg2rad(lat1), deg2rad(lat2)
+	u := math.Sin((φ2 - φ1) / 2)
+	v := math.Sin(deg2rad(lon2-lon1) / 2)
+	return 2 * EarthRadius * math.Asin(math.Sqrt(u*u+math.Cos(φ1)*math.Cos(φ2)*v*v))
+}
+
+// RadiusPrecision returns the highest precision at which a cell at the given
+// latitude is at least radius meters wide and tall. Searching a cell and its
+// eight neighbors at this precision is therefore guaranteed to find all
+// points within radius of any location in the cell.
+func RadiusPrecision(lat, radius float64) uint8 {
+	if radius <= 0 {
+		return PrecisionMax
+	}
+
+	φ := deg2rad(math.Min(math.Abs(lat), LatMax))
+	for prec := uint8(PrecisionMax); prec > PrecisionMin; prec-- {
+		n := float64(uint64(1) << prec)
+		height := deg2rad(latScale/n) * EarthRadius
+		width := deg2rad(lonScale/n) * EarthRadius * math.Cos(φ)
+		if width >= radius && height >= radius {
+			return prec
+		}
+	}
+	return PrecisionMin
+}
+
+// radiusAreas returns the bounding areas of a circle. Circles which cross
+// the antimeridian are split into two areas.
+func radiusAreas(lat, lon, radius float64) []Area {
+	dLat := rad2deg(radius / EarthRadius)
+	area := Area{
+		MinLat: math.Max(lat-dLat, LatMin),
+		MaxLat: math.Min(lat+dLat, LatMax),
+		MinLon: LonMin,
+		MaxLon: LonMax,
+	}
+
+	// circles spanning the Mercator limits cover all longitudes
+	if area.MinLat == LatMin || area.MaxLat == LatMax {
+		return []Area{area}
+	}
+
+	s := math.Sin(radius/EarthRadius) / math.Cos(deg2rad(lat))
+	if s >= 1 {
+		return []Area{area}
+	}
+
+	dLon := rad2deg(math.Asin(s))
+	switch area.MinLon, area.MaxLon = lon-dLon, lon+dLon; {
+	case area.MinLon < LonMin:
+		west := area
+		west.MinLon, west.MaxLon = area.MinLon+360, LonMax
+		area.MinLon = LonMin
+		return []Area{west, area}
+	case area.MaxLon > LonMax:
+		east := area
+		east.MinLon, east.MaxLon = LonMin, area.MaxLon-360
+		area.MaxLon = LonMax
+		return []Area{area, east}
+	}
+	return []Area{area}
+}
+
+// ValidCoordinates reports whether lat/lon are within the limits. NaN
+// coordinates are never valid.
+func ValidCoordinates(lat, lon float64) bool {
+	return LatMin <= lat && lat <= LatMax && LonMin <= lon && lon <= LonMax
+}
+
+func deg2rad(d float64) float64 { return d * math.Pi / 180 }
+func rad2deg(r float64) float64 { return r * 180 / math.Pi }
@@ -0,0 +1,61 @@
+package geohashi
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Distance", func() {
+
+	It("should calculate distances", func() {
+		Expect(Distance(51.52463, -0.08411, 51.52463, -0.08411)).To(Equal(0.0))
+		Expect(Distance(51.5007, -0.1246, 48.8584, 2.2945)).To(BeNumerically("~", 340635, 1))
+		Expect(Distance(0, 179.9, 0, -179.9)).To(BeNumerically("~", 22245, 1))
+	})
+
+	It("should validate coordinates", func() {
+		Expect(ValidCoordinates(51.52463, -0.08411)).To(BeTrue())
+		Expect(ValidCoordinates(LatMax, LonMin)).To(BeTrue())
+		Expect(ValidCoordinates(LatMax+1e-9, 0)).To(BeFalse())
+		Expect(ValidCoordinates(0, LonMax+1e-9)).To(BeFalse())
+		Expect(ValidCoordinates(math.NaN(), 0)).To(BeFalse())
+		Expect(ValidCoordinates(0, math.Inf(-1))).To(BeFalse())
+	})
+
+	It("should calculate radius areas", func() {
+		const oneDegree = EarthRadius * math.Pi / 180
+
+		areas := radiusAreas(0, 0, oneDegree)
+		Expect(areas).To(HaveLen(1))
+		Expect(areas[0].MinLat).To(BeNumerically("~", -1, 1e-9))
+		Expect(areas[0].MaxLat).To(BeNumerically("~", 1, 1e-9))
+		Expect(areas[0].MinLon).To(BeNumerically("~", -1, 1e-9))
+		Expect(areas[0].MaxLon).To(BeNumerically("~", 1, 1e-9))
+
+		areas = radiusAreas(60, 179.5, oneDegree)
+		Expect(areas).To(HaveLen(2))
+		Expect(areas[0].MinLon).To(BeNumerically("~", 177.4997, 1e-4))
+		Expect(areas[0].MaxLon).To(Equal(180.0))
+		Expect(areas[1].MinLon).To(Equal(-180.0))
+		Expect(areas[1].MaxLon).To(BeNumerically("~", -178.4997, 1e-4))
+
+		areas = radiusAreas(84, 0, 2*oneDegree)
+		Expect(areas).To(HaveLen(1))
+		Expect(areas[0].MinLat).To(BeNumerically("~", 82, 1e-9))
+		Expect(areas[0].MaxLat).To(Equal(LatMax))
+		Expect(areas[0].MinLon).To(Equal(LonMin))
+		Expect(areas[0].MaxLon).To(Equal(LonMax))
+	})
+
+	It("should calculate radius precisions", func() {
+		Expect(RadiusPrecision(0, 0.3)).To(Equal(uint8(25)))
+		Expect(RadiusPrecision(0, 1000)).To(Equal(uint8(14)))
+		Expect(RadiusPrecision(60, 1000)).To(Equal(uint8(14)))
+		Expect(RadiusPrecision(-85, 1000)).To(Equal(uint8(11)))
+		Expect(RadiusPrecision(0, 1e7)).To(Equal(uint8(1)))
+		Expect(RadiusPrecision(0, 0)).To(Equal(uint8(26)))
+	})
+
+})
@@ -0,0 +1,233 @@
+package geohashi
+
+import (
+	"sort"
+	"time"
+)
+
+// MaxFenceCells is the maximum number of cells used to cover a single fence.
+const MaxFenceCells = 1 << 16
+
+// EventType is the type of a geofence event.
+type EventType uint8
+
+const (
+	// Enter events are emitted when an entity enters a fence.
+	Enter EventType = iota + 1
+	// Exit events are emitted when an entity leaves a fence.
+	Exit
+	// Dwell events are emitted once an entity has stayed within a fence for
+	// the configured dwell time.
+	Dwell
+)
+
+// String implements fmt.Stringer.
+func (t EventType) String() string {
+	switch t {
+	case Enter:
+		return "enter"
+	case Exit:
+		return "exit"
+	case Dwell:
+		return "dwell"
+	}
+	return "unknown"
+}
+
+// Event is a geofence event.
+type Event struct {
+	Type   EventType
+	Entity string
+	Fence  string
+	Time   time.Time
+}
+
+// --------------------------------------------------------------------
+
+type fenceRef struct {
+	id       string
+	interior bool
+}
+
+type fenceEntry struct {
+	Fence
+	Covering
+}
+
+type presence struct {
+	since   time.Time
+	dwelled bool
+}
+
+// Geofencer detects entities entering, exiting and dwelling in fences.
+// Fences are converted to coverings and points are matched by cell lookups.
+// Exact geometry tests are only performed for points in boundary cells.
+// Geofencer is not safe for concurrent use.
+type Geofencer struct {
+	prec  uint8
+	dwell time.Duration
+
+	fences map[string]fenceEntry
+	cells  map[Hash][]fenceRef
+	precs  map[uint8]int // number of cells per precision
+
+	entities map[string]map[string]*presence
+}
+
+// NewGeofencer inits a new geofencer. Fence boundaries are refined up to the
+// given precision, large fences require lower precisions. Entities trigger
+// dwell events after staying within a fence for the dwell duration, pass 0 to
+// disable dwell events.
+func NewGeofencer(prec uint8, dwell time.Duration) *Geofencer {
+	if prec < PrecisionMin || prec > PrecisionMax {
+		prec = PrecisionMax
+	}
+	return &Geofencer{
+		prec:     prec,
+		dwell:    dwell,
+		fences:   make(map[string]fenceEntry),
+		cells:    make(map[Hash][]fenceRef),
+		precs:    make(map[uint8]int),
+		entities: make(map[string]map[string]*presence),
+	}
+}
+
+// AddFence registers a fence. Existing fences with the same ID are replaced.
+// It returns an error if the fence requires more than MaxFenceCells cells at
+// the geofencer's precision.
+func (g *Geofencer) AddFence(id string, f Fence) error {
+	cov, err := CoverFenceLimit(f, g.prec, MaxFenceCells)
+	if err != nil {
+		return err
+	}
+
+	g.RemoveFence(id)
+	for _, h := range cov.Interior {
+		g.addCell(h, fenceRef{id: id, interior: true})
+	}
+	for _, h := range cov.Boundary {
+		g.addCell(h, fenceRef{id: id})
+	}
+	g.fences[id] = fenceEntry{Fence: f, Covering: cov}
+	return nil
+}
+
+// RemoveFence removes a fence. Entities within the fence are forgotten, no
+// exit events are emitted.
+func (g *Geofencer) RemoveFence(id string) {
+	f, ok := g.fences[id]
+	if !ok {
+		return
+	}
+
+	for _, hh := range [][]Hash{f.Interior, f.Boundary} {
+		for _, h := range hh {
+			g.removeCell(h, id)
+		}
+	}
+	delete(g.fences, id)
+
+	for _, fences := range g.entities {
+		delete(fences, id)
+	}
+}
+
+// Locate returns the (sorted) IDs of all fences containing the coordinates.
+func (g *Geofencer) Locate(lat, lon float64) []string {
+	if !ValidCoordinates(lat, lon) {
+		return nil
+	}
+
+	var ids []string
+	for prec := range g.precs {
+		for _, ref := range g.cells[encode(lat, lon, prec)] {
+			if ref.interior || g.fences[ref.id].Contains(lat, lon) {
+				ids = append(ids, ref.id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Update updates the location of an entity and returns the resulting events.
+// Updates for each entity must be submitted in chronological order. Updates
+// with invalid coordinates, e.g. from a bad GPS fix, are ignored and leave the
+// state of the entity unchanged.
+func (g *Geofencer) Update(entity string, lat, lon float64, t time.Time) []Event {
+	if !ValidCoordinates(lat, lon) {
+		return nil
+	}
+
+	ids := g.Locate(lat, lon)
+	prev := g.entities[entity]
+
+	var events []Event
+	for _, id := range sortedKeys(prev) {
+		if i := sort.SearchStrings(ids, id); i == len(ids) || ids[i] != id {
+			events = append(events, Event{Type: Exit, Entity: entity, Fence: id, Time: t})
+			delete(prev, id)
+		}
+	}
+
+	for _, id := range ids {
+		p, ok := prev[id]
+		if !ok {
+			if prev == nil {
+				prev = make(map[string]*presence)
+				g.entities[entity] = prev
+			}
+			p = &presence{since: t}
+			prev[id] = p
+			events = append(events, Event{Type: Enter, Entity: entity, Fence: id, Time: t})
+		}
+		if g.dwell > 0 && !p.dwelled && t.Sub(p.since) >= g.dwell {
+			p.dwelled = true
+			events = append(events, Event{Type: Dwell, Entity: entity, Fence: id, Time: t})
+		}
+	}
+
+	if len(prev) == 0 {
+		delete(g.entities, entity)
+	}
+	return events
+}
+
+// Forget removes all state of an entity without emitting events.
+func (g *Geofencer) Forget(entity string) {
+	delete(g.entities, entity)
+}
+
+func (g *Geofencer) addCell(h Hash, ref fenceRef) {
+	g.cells[h] = append(g.cells[h], ref)
+	g.precs[h.Precision()]++
+}
+
+func (g *Geofencer) removeCell(h Hash, id string) {
+	refs := g.cells[h]
+	for i, ref := range refs {
+		if ref.id == id {
+			refs = append(refs[:i], refs[i+1:]...)
+			break
+		}
+	}
+	if len(refs) == 0 {
+		delete(g.cells, h)
+	} else {
+		g.cells[h] = refs
+	}
+
+	prec := h.Precision()
+	if g.precs[prec]--; g.precs[prec] == 0 {
+		delete(g.precs, prec)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,103 @@
+package geohashi
+
+import (
+	"math"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Geofencer", func() {
+	var subject *Geofencer
+	t0 := time.Date(2018, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		subject = NewGeofencer(20, 10*time.Minute)
+		Expect(subject.AddFence("shoreditch", Circle{Lat: 51.5246, Lon: -0.0841, Radius: 1000})).To(Succeed())
+		Expect(subject.AddFence("city", Polygon{{51.5072, -0.1121}, {51.5207, -0.1121}, {51.5207, -0.0731}, {51.5072, -0.0731}})).To(Succeed())
+	})
+
+	It("should locate", func() {
+		Expect(subject.Locate(51.5256, -0.0875)).To(Equal([]string{"shoreditch"}))
+		Expect(subject.Locate(51.5178, -0.0823)).To(Equal([]string{"city", "shoreditch"}))
+		Expect(subject.Locate(51.5100, -0.1000)).To(Equal([]string{"city"}))
+		Expect(subject.Locate(51.5308, -0.1238)).To(BeEmpty())
+		Expect(subject.Locate(89, 0)).To(BeEmpty())
+	})
+
+	It("should emit events", func() {
+		Expect(subject.Update("bob", 51.5308, -0.1238, t0)).To(BeEmpty())
+		Expect(subject.Update("bob", 51.5256, -0.0875, t0.Add(time.Minute))).To(Equal([]Event{
+			{Type: Enter, Entity: "bob", Fence: "shoreditch", Time: t0.Add(time.Minute)},
+		}))
+		Expect(subject.Update("bob", 51.5178, -0.0823, t0.Add(5*time.Minute))).To(Equal([]Event{
+			{Type: Enter, Entity: "bob", Fence: "city", Time: t0.Add(5 * time.Minute)},
+		}))
+		Expect(subject.Update("bob", 51.5178, -0.0823, t0.Add(11*time.Minute))).To(Equal([]Event{
+			{Type: Dwell, Entity: "bob", Fence: "shoreditch", Time: t0.Add(11 * time.Minute)},
+		}))
+		Expect(subject.Update("bob", 51.5178, -0.0823, t0.Add(12*time.Minute))).To(BeEmpty())
+		Expect(subject.Update("bob", 51.5100, -0.1000, t0.Add(15*time.Minute))).To(Equal([]Event{
+			{Type: Exit, Entity: "bob", Fence: "shoreditch", Time: t0.Add(15 * time.Minute)},
+			{Type: Dwell, Entity: "bob", Fence: "city", Time: t0.Add(15 * time.Minute)},
+		}))
+		Expect(subject.Update("bob", 51.5308, -0.1238, t0.Add(20*time.Minute))).To(Equal([]Event{
+			{Type: Exit, Entity: "bob", Fence: "city", Time: t0.Add(20 * time.Minute)},
+		}))
+		Expect(subject.entities).To(BeEmpty())
+	})
+
+	It("should ignore invalid coordinates", func() {
+		Expect(subject.Update("bob", 51.5178, -0.0823, t0)).To(HaveLen(2))
+		Expect(subject.Update("bob", math.NaN(), -0.0823, t0.Add(time.Minute))).To(BeEmpty())
+		Expect(subject.Update("bob", 51.5178, math.Inf(1), t0.Add(2*time.Minute))).To(BeEmpty())
+		Expect(subject.Update("bob", 91, -0.0823, t0.Add(3*time.Minute))).To(BeEmpty())
+		Expect(subject.entities["bob"]).To(HaveLen(2))
+
+		Expect(subject.Update("bob", 51.5178, -0.0823, t0.Add(10*time.Minute))).To(Equal([]Event{
+			{Type: Dwell, Entity: "bob", Fence: "city", Time: t0.Add(10 * time.Minute)},
+			{Type: Dwell, Entity: "bob", Fence: "shoreditch", Time: t0.Add(10 * time.Minute)},
+		}))
+		Expect(subject.Update("alice", math.NaN(), math.NaN(), t0)).To(BeEmpty())
+		Expect(subject.entities).NotTo(HaveKey("alice"))
+	})
+
+	It("should replace/remove fences", func() {
+		Expect(subject.Update("bob", 51.5256, -0.0875, t0)).To(HaveLen(1))
+
+		Expect(subject.AddFence("city", Circle{Lat: 51.5256, Lon: -0.0875, Radius: 100})).To(Succeed())
+		Expect(subject.Locate(51.5100, -0.1000)).To(BeEmpty())
+		Expect(subject.Locate(51.5256, -0.0875)).To(Equal([]string{"city", "shoreditch"}))
+
+		subject.RemoveFence("shoreditch")
+		subject.RemoveFence("unknown")
+		Expect(subject.Locate(51.5256, -0.0875)).To(Equal([]string{"city"}))
+		Expect(subject.Update("bob", 51.5256, -0.0875, t0.Add(time.Minute))).To(Equal([]Event{
+			{Type: Enter, Entity: "bob", Fence: "city", Time: t0.Add(time.Minute)},
+		}))
+
+		subject.RemoveFence("city")
+		Expect(subject.cells).To(BeEmpty())
+		Expect(subject.precs).To(BeEmpty())
+	})
+
+	It("should reject large fences", func() {
+		g := NewGeofencer(PrecisionMax, 0)
+		err := g.AddFence("london", Polygon{{51.28, -0.51}, {51.69, -0.51}, {51.69, 0.33}, {51.28, 0.33}})
+		Expect(err).To(MatchError(errTooManyCells))
+		Expect(g.fences).To(BeEmpty())
+		Expect(g.cells).To(BeEmpty())
+
+		Expect(subject.AddFence("city", Circle{Lat: 51.5256, Lon: -0.0875, Radius: 1000000})).To(MatchError(errTooManyCells))
+		Expect(subject.Locate(51.5100, -0.1000)).To(Equal([]string{"city"}))
+	})
+
+	It("should print event types", func() {
+		Expect(Enter.String()).To(Equal("enter"))
+		Expect(Exit.String()).To(Equal("exit"))
+		Expect(Dwell.String()).To(Equal("dwell"))
+		Expect(EventType(0).String()).To(Equal("unknown"))
+	})
+
+})
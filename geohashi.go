@@ -21,7 +21,13 @@ const (
 	lonScale = LonMax - LonMin
 )
 
-var errInvalidPrecision = errors.New("geohashi: invalid precision")
+var (
+	errInvalidPrecision = errors.New("geohashi: invalid precision")
+	errInvalidHash      = errors.New("geohashi: invalid hash")
+	errInvalidArea      = errors.New("geohashi: invalid area")
+
+	errUnsupportedGeometry = errors.New("geohashi: unsupported geometry")
+)
 
 // --------------------------------------------------------------------
 
@@ -60,18 +66,13 @@ func Encode(lat, lon float64) Hash {
 	return EncodeWithPrecision(lat, lon, PrecisionMax)
 }
 
-// EncodeWithPrecision converts a lat/lon to an numeric geohash
+// EncodeWithPrecision converts a lat/lon to an numeric geohash. Coordinates
+// are clamped at the limits, NaN coordinates and invalid precisions return 0.
 func EncodeWithPrecision(lat, lon float64, prec uint8) Hash {
-	if prec < PrecisionMin || prec > PrecisionMax {
+	if prec < PrecisionMin || prec > PrecisionMax || math.IsNaN(lat) || math.IsNaN(lon) {
 		return 0
 	}
-
-	dx := (lat - LatMin) / latScale
-	dy := (lon - LonMin) / lonScale
-	gn := float64(uint64(1) << prec)
-
-	base := interleave64(uint64(dx*gn), uint64(dy*gn))
-	return newHash(base, prec)
+	return encode(lat, lon, prec)
 }
 
 // Precision returns the prec level
@@ -104,6 +105,15 @@ func (h Hash) Parent() Hash {
 	return newHash(h.base()>>2, prec-1)
 }
 
+// ancestor returns the ancestor hash at the given precision. Returns h if
+// prec is not lower than the precision of h.
+func (h Hash) ancestor(prec uint8) Hash {
+	if own := h.Precision(); prec < own {
+		return newHash(h.base()>>(2*(own-prec)), prec)
+	}
+	return h
+}
+
 // Children zooms in, returning four child hashes, in the following order SW, SE, NW, NE.
 // This function may return nil if unable to zoom in further
 func (h Hash) Children() []Hash {
@@ -177,3 +187,26 @@ func (h Hash) MoveY(n int) Hash {
 	}
 	return newHash(base, prec)
 }
+
+// Neighbors returns the adjacent hashes, in the following order N, NE, E, SE,
+// S, SW, W, NW. Neighbors beyond the latitude limits are omitted.
+func (h Hash) Neighbors() []Hash {
+	// MoveY wraps around the poles
+	minLat := h.Decode().MinLat
+	north := h.MoveY(1).Decode().MinLat > minLat
+	south := h.MoveY(-1).Decode().MinLat < minLat
+
+	res := make([]Hash, 0, 8)
+	if north {
+		res = append(res, h.MoveY(1), h.MoveY(1).MoveX(1))
+	}
+	res = append(res, h.MoveX(1))
+	if south {
+		res = append(res, h.MoveY(-1).MoveX(1), h.MoveY(-1), h.MoveY(-1).MoveX(-1))
+	}
+	res = append(res, h.MoveX(-1))
+	if north {
+		res = append(res, h.MoveY(1).MoveX(-1))
+	}
+	return res
+}
@@ -1,6 +1,7 @@
 package geohashi
 
 import (
+	"math"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
@@ -16,6 +17,23 @@ var _ = Describe("Hash", func() {
 		Expect(EncodeWithPrecision(lat, lon, 27)).To(Equal(Hash(0)))
 	})
 
+	It("should not encode NaN coordinates", func() {
+		Expect(EncodeWithPrecision(math.NaN(), lon, 10)).To(Equal(Hash(0)))
+		Expect(EncodeWithPrecision(lat, math.NaN(), 10)).To(Equal(Hash(0)))
+		Expect(Encode(math.NaN(), math.NaN())).To(Equal(Hash(0)))
+	})
+
+	It("should clamp coordinates at the limits", func() {
+		for _, prec := range []uint8{1, 20, PrecisionMax} {
+			hash := EncodeWithPrecision(LatMax, LonMax, prec)
+			Expect(hash.Precision()).To(Equal(prec))
+			Expect(hash.Decode().MaxLat).To(BeNumerically("~", LatMax, 1e-9))
+			Expect(hash.Decode().MaxLon).To(BeNumerically("~", LonMax, 1e-9))
+			Expect(EncodeWithPrecision(LatMax+1, LonMax+1, prec)).To(Equal(hash))
+			Expect(EncodeWithPrecision(LatMin-1, LonMin-1, prec)).To(Equal(EncodeWithPrecision(LatMin, LonMin, prec)))
+		}
+	})
+
 	DescribeTable("should encode",
 		func(lat, lon float64, prec int, exp Hash) {
 			hash := EncodeWithPrecision(lat, lon, uint8(prec))
@@ -447,6 +465,21 @@ var _ = Describe("Hash", func() {
 		Expect(south.MoveY(-1)).To(Equal(hash.MoveY(-2)))
 	})
 
+	It("should find neighbors", func() {
+		hash := Hash(108221613442698053)
+		Expect(hash.Neighbors()).To(Equal([]Hash{
+			hash.MoveY(1), hash.MoveY(1).MoveX(1), hash.MoveX(1), hash.MoveY(-1).MoveX(1),
+			hash.MoveY(-1), hash.MoveY(-1).MoveX(-1), hash.MoveX(-1), hash.MoveY(1).MoveX(-1),
+		}))
+
+		north := EncodeWithPrecision(LatMax, 0, 3)
+		Expect(north.Neighbors()).To(Equal([]Hash{
+			north.MoveX(1), north.MoveY(-1).MoveX(1), north.MoveY(-1), north.MoveY(-1).MoveX(-1), north.MoveX(-1),
+		}))
+		south := EncodeWithPrecision(LatMin, 0, 3)
+		Expect(south.Neighbors()).To(HaveLen(5))
+	})
+
 })
 
 // --------------------------------------------------------------------
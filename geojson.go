@@ -0,0 +1,212 @@
+package geohashi
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var errInvalidGeoJSON = errors.New("geohashi: invalid GeoJSON")
+
+// Properties are GeoJSON feature properties.
+type Properties map[string]interface{}
+
+// Geometry is a GeoJSON geometry.
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// Feature is a GeoJSON feature.
+type Feature struct {
+	Type       string     `json:"type"`
+	Geometry   Geometry   `json:"geometry"`
+	Properties Properties `json:"properties"`
+}
+
+// FeatureCollection is a GeoJSON feature collection.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// NewFeatureCollection renders hashes as a feature collection. The optional
+// props function may return additional properties for each hash.
+func NewFeatureCollection(hashes []Hash, props func(Hash) Properties) FeatureCollection {
+	fc := FeatureCollection{Type: "FeatureCollection", Features: make([]Feature, 0, len(hashes))}
+	for _, h := range hashes {
+		var p Properties
+		if props != nil {
+			p = props(h)
+		}
+		fc.Features = append(fc.Features, h.Feature(p))
+	}
+	return fc
+}
+
+// GeoJSON returns the Polygon geometry of the area.
+func (a Area) GeoJSON() Geometry { return geojsonPolygon(a.ring()) }
+
+// Feature renders the area as a Polygon feature with the given properties.
+func (a Area) Feature(props Properties) Feature {
+	if props == nil {
+		props = Properties{}
+	}
+	return Feature{Type: "Feature", Geometry: a.GeoJSON(), Properties: props}
+}
+
+// GeoJSON returns the Polygon geometry of the hash.
+func (h Hash) GeoJSON() Geometry { return h.Decode().GeoJSON() }
+
+// Feature renders the hash as a Polygon feature. The properties include the
+// hash value and precision, in addition to the given properties.
+func (h Hash) Feature(props Properties) Feature {
+	p := make(Properties, len(props)+2)
+	for k, v := range props {
+		p[k] = v
+	}
+	p["hash"] = h
+	p["precision"] = h.Precision()
+	return h.Decode().Feature(p)
+}
+
+// GeoJSON returns the Polygon geometry of the polygon.
+func (p Polygon) GeoJSON() Geometry { return geojsonPolygon(p.ring()) }
+
+// geojsonPolygon returns the Polygon geometry of a closed ring.
+func geojsonPolygon(ring []Point) Geometry {
+	coords := make([][2]float64, 0, len(ring))
+	for _, pt := range ring {
+		coords = append(coords, [2]float64{pt.Lon, pt.Lat})
+	}
+	return Geometry{Type: "Polygon", Coordinates: [][][2]float64{coords}}
+}
+
+// --------------------------------------------------------------------
+
+// ParseGeoJSON extracts points and polygons from a GeoJSON geometry, feature,
+// or feature collection. Point, MultiPoint, Polygon, MultiPolygon and
+// GeometryCollection geometries are supported. Holes of polygons are ignored,
+// the parsed polygons can therefore be used to produce (conservative)
+// coverings.
+func ParseGeoJSON(data []byte) (points []Point, polygons []Polygon, err error) {
+	var obj geojsonObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, nil, err
+	}
+
+	var p geojsonParser
+	if err := p.parse(&obj); err != nil {
+		return nil, nil, err
+	}
+	return p.points, p.polygons, nil
+}
+
+type geojsonObject struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geojsonObject  `json:"geometry"`
+	Geometries  []geojsonObject `json:"geometries"`
+	Features    []geojsonObject `json:"features"`
+}
+
+type geojsonParser struct {
+	points   []Point
+	polygons []Polygon
+}
+
+func (p *geojsonParser) parse(obj *geojsonObject) error {
+	switch obj.Type {
+	case "FeatureCollection":
+		for i := range obj.Features {
+			if err := p.parse(&obj.Features[i]); err != nil {
+				return err
+			}
+		}
+	case "Feature":
+		if obj.Geometry != nil {
+			return p.parse(obj.Geometry)
+		}
+	case "GeometryCollection":
+		for i := range obj.Geometries {
+			if err := p.parse(&obj.Geometries[i]); err != nil {
+				return err
+			}
+		}
+	case "Point":
+		var c []float64
+		if err := json.Unmarshal(obj.Coordinates, &c); err != nil {
+			return err
+		}
+		return p.addPoints(c)
+	case "MultiPoint":
+		var cc [][]float64
+		if err := json.Unmarshal(obj.Coordinates, &cc); err != nil {
+			return err
+		}
+		return p.addPoints(cc...)
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &rings); err != nil {
+			return err
+		}
+		return p.addPolygons(rings)
+	case "MultiPolygon":
+		var polys [][][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &polys); err != nil {
+			return err
+		}
+		return p.addPolygons(polys...)
+	case "LineString", "MultiLineString":
+		return errUnsupportedGeometry
+	default:
+		return errInvalidGeoJSON
+	}
+	return nil
+}
+
+func (p *geojsonParser) addPoints(coords ...[]float64) error {
+	for _, c := range coords {
+		pt, err := geojsonPoint(c)
+		if err != nil {
+			return err
+		}
+		p.points = append(p.points, pt)
+	}
+	return nil
+}
+
+func (p *geojsonParser) addPolygons(polys ...[][][]float64) error {
+	for _, rings := range polys {
+		if len(rings) == 0 {
+			return errInvalidGeoJSON
+		}
+
+		// use the outer ring only
+		ring := make([]Point, 0, len(rings[0]))
+		for _, c := range rings[0] {
+			pt, err := geojsonPoint(c)
+			if err != nil {
+				return err
+			}
+			ring = append(ring, pt)
+		}
+
+		poly, ok := ringPolygon(ring)
+		if !ok {
+			return errInvalidGeoJSON
+		}
+		p.polygons = append(p.polygons, poly)
+	}
+	return nil
+}
+
+// geojsonPoint converts a GeoJSON position into a point.
+func geojsonPoint(c []float64) (Point, error) {
+	if len(c) < 2 {
+		return Point{}, errInvalidGeoJSON
+	}
+	if pt := (Point{Lat: c[1], Lon: c[0]}); pt.valid() {
+		return pt, nil
+	}
+	return Point{}, errInvalidGeoJSON
+}
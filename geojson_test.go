@@ -0,0 +1,113 @@
+package geohashi
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GeoJSON", func() {
+	area := Area{MinLat: 51.5, MaxLat: 51.6, MinLon: -0.2, MaxLon: 0.1}
+
+	marshal := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		Expect(err).NotTo(HaveOccurred())
+		return string(data)
+	}
+
+	It("should render areas", func() {
+		Expect(marshal(area.GeoJSON())).To(MatchJSON(`{
+			"type": "Polygon",
+			"coordinates": [[[-0.2, 51.5], [0.1, 51.5], [0.1, 51.6], [-0.2, 51.6], [-0.2, 51.5]]]
+		}`))
+		Expect(marshal(area.Feature(nil))).To(MatchJSON(`{
+			"type": "Feature",
+			"geometry": {
+				"type": "Polygon",
+				"coordinates": [[[-0.2, 51.5], [0.1, 51.5], [0.1, 51.6], [-0.2, 51.6], [-0.2, 51.5]]]
+			},
+			"properties": {}
+		}`))
+	})
+
+	It("should render hashes", func() {
+		h := EncodeWithPrecision(1, 1, 1)
+		Expect(marshal(h.Feature(Properties{"name": "ne", "hash": "overridden"}))).To(MatchJSON(`{
+			"type": "Feature",
+			"geometry": {
+				"type": "Polygon",
+				"coordinates": [[[0, 0], [180, 0], [180, 85.05112878], [0, 85.05112878], [0, 0]]]
+			},
+			"properties": {"hash": "4503599627370499", "precision": 1, "name": "ne"}
+		}`))
+	})
+
+	It("should render hash collections", func() {
+		fc := NewFeatureCollection(EncodeWithPrecision(1, 1, 1).Children(), func(h Hash) Properties {
+			return Properties{"level": 2}
+		})
+		Expect(fc.Type).To(Equal("FeatureCollection"))
+		Expect(fc.Features).To(HaveLen(4))
+		Expect(fc.Features[0].Properties).To(HaveKeyWithValue("level", 2))
+		Expect(fc.Features[0].Properties).To(HaveKeyWithValue("precision", uint8(2)))
+
+		Expect(marshal(NewFeatureCollection(nil, nil))).To(MatchJSON(`{"type": "FeatureCollection", "features": []}`))
+	})
+
+	It("should render polygons", func() {
+		poly := Polygon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 1}, {Lat: 1, Lon: 0}}
+		Expect(marshal(poly.GeoJSON())).To(MatchJSON(`{
+			"type": "Polygon",
+			"coordinates": [[[0, 0], [1, 0], [0, 1], [0, 0]]]
+		}`))
+	})
+
+	It("should parse", func() {
+		points, polys, err := ParseGeoJSON([]byte(`{
+			"type": "FeatureCollection",
+			"features": [
+				{"type": "Feature", "geometry": {"type": "Point", "coordinates": [-0.1, 51.5]}, "properties": null},
+				{"type": "Feature", "geometry": {"type": "Polygon", "coordinates": [
+					[[0, 0], [1, 0], [0, 1], [0, 0]],
+					[[0.1, 0.1], [0.2, 0.1], [0.1, 0.2], [0.1, 0.1]]
+				]}, "properties": null},
+				{"type": "Feature", "geometry": null, "properties": null},
+				{"type": "Feature", "geometry": {"type": "GeometryCollection", "geometries": [
+					{"type": "MultiPoint", "coordinates": [[1, 2, 100], [3, 4]]},
+					{"type": "MultiPolygon", "coordinates": [[[[5, 5], [6, 5], [6, 6], [5, 5]]]]}
+				]}, "properties": null}
+			]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(points).To(Equal([]Point{{Lat: 51.5, Lon: -0.1}, {Lat: 2, Lon: 1}, {Lat: 4, Lon: 3}}))
+		Expect(polys).To(Equal([]Polygon{
+			{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 1}, {Lat: 1, Lon: 0}},
+			{{Lat: 5, Lon: 5}, {Lat: 5, Lon: 6}, {Lat: 6, Lon: 6}},
+		}))
+	})
+
+	It("should round-trip", func() {
+		_, polys, err := ParseGeoJSON([]byte(marshal(area.Feature(nil))))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(polys).To(HaveLen(1))
+		Expect(polys[0].Bounds()).To(Equal(area))
+	})
+
+	It("should reject bad inputs", func() {
+		parse := func(s string) error {
+			_, _, err := ParseGeoJSON([]byte(s))
+			return err
+		}
+
+		Expect(parse(`{"type": "LineString", "coordinates": [[0, 0], [1, 1]]}`)).To(MatchError(errUnsupportedGeometry))
+		Expect(parse(`{"type": "Circle"}`)).To(MatchError(errInvalidGeoJSON))
+		Expect(parse(`{"type": "Point", "coordinates": [0]}`)).To(MatchError(errInvalidGeoJSON))
+		Expect(parse(`{"type": "Point", "coordinates": [0, 91]}`)).To(MatchError(errInvalidGeoJSON))
+		Expect(parse(`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [0, 0]]]}`)).To(MatchError(errInvalidGeoJSON))
+		Expect(parse(`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [0, 1], [1, 1]]]}`)).To(MatchError(errInvalidGeoJSON))
+		Expect(parse(`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 0], [0, 0]]]}`)).To(MatchError(errInvalidGeoJSON))
+		Expect(parse(`{"type": "Polygon", "coordinates": "x"}`)).To(HaveOccurred())
+		Expect(parse(`not json`)).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,1236 @@
+package geohashi
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+)
+
+var errInvalidH3Cell = errors.New("geohashi: invalid H3 cell")
+
+// H3 grid constants, see https://h3geo.org/docs/core-library/overview
+const (
+	H3ResolutionMax = 15 // maximum H3 resolution, cells are roughly 1m²
+
+	h3ModeCell       = 1 << 59
+	h3ResOffset      = 52
+	h3BaseCellOffset = 45
+	h3UnusedDigits   = 1<<h3BaseCellOffset - 1 // all digits set to 7
+	h3NumBaseCells   = 122
+
+	h3Epsilon    = 1e-16
+	h3FltEpsilon = 0x1p-23
+	h3Res0U      = 0.38196601125010500003 // gnomonic edge length at resolution 0
+	h3InvRes0U   = 2.61803398874989588842
+	h3Ap7Rot     = 0.333473172251832115336090755351601070065900389 // class III rotation
+	h3Sqrt7      = 2.6457513110645905905016157536392604257102
+	h3RSqrt7     = 0.37796447300922722721451653623418006081576
+	h3Sqrt3_2    = 0.8660254037844386467637231707529361834714
+	h3RSin60     = 1.1547005383792515290182975610039149112953
+	h3OneThird   = 0.333333333333333333333333333333333333333
+	h3OneSeventh = 0.14285714285714285714285714285714285
+)
+
+// H3Cell identifies a cell of Uber's H3 grid. H3 projects the twenty faces
+// of an icosahedron onto the sphere and tiles them with hexagons, plus twelve
+// pentagons at the icosahedron vertices. Each cell has seven children, six
+// for pentagons, which only approximately cover it.
+//
+// Like S2 cells, H3 cells are bridged to hashes via the coverer: H3Cell
+// implements Fence, so CoverFence returns the hashes which cover a cell,
+// while Area.H3Cells returns the cells which cover an area or hash.
+type H3Cell uint64
+
+// H3CellFromLatLon returns the cell at the given resolution (0..15) which
+// contains a location.
+func H3CellFromLatLon(lat, lon float64, res int) (H3Cell, error) {
+	if !(Point{Lat: lat, Lon: lon}).valid() {
+		return 0, errInvalidCoordinates
+	}
+	if res < 0 || res > H3ResolutionMax {
+		return 0, errInvalidH3Cell
+	}
+	return h3CellFromLatLon(lat, lon, res), nil
+}
+
+// H3CellFromString parses the hex representation of a cell, e.g.
+// "8928308280fffff".
+func H3CellFromString(s string) (H3Cell, error) {
+	n, err := strconv.ParseUint(s, 16, 64)
+	if err != nil || !H3Cell(n).IsValid() {
+		return 0, errInvalidH3Cell
+	}
+	return H3Cell(n), nil
+}
+
+// IsValid returns true if the index identifies a cell.
+func (c H3Cell) IsValid() bool {
+	if c>>56 != h3ModeCell>>56 || c.baseCell() >= h3NumBaseCells {
+		return false
+	}
+
+	res, leading := c.Resolution(), 0
+	for r := 1; r <= H3ResolutionMax; r++ {
+		d := c.digit(r)
+		if r > res {
+			if d != 7 {
+				return false
+			}
+			continue
+		}
+		if d == 7 {
+			return false
+		}
+		if leading == 0 {
+			leading = d
+		}
+	}
+
+	// pentagons skip the sub-sequence of the k axis
+	return !(h3BaseCells[c.baseCell()].pentagon && leading == 1)
+}
+
+// Resolution returns the resolution of the cell, between 0 and 15.
+func (c H3Cell) Resolution() int { return int(c >> h3ResOffset & 15) }
+
+// IsPentagon returns true if the cell is one of the twelve pentagons of its
+// resolution.
+func (c H3Cell) IsPentagon() bool {
+	return h3BaseCells[c.baseCell()%h3NumBaseCells].pentagon && c.leadingDigit() == 0
+}
+
+// String returns the hex representation of the cell.
+func (c H3Cell) String() string { return strconv.FormatUint(uint64(c), 16) }
+
+// Parent returns the parent cell. Returns 0 for resolution 0 cells.
+func (c H3Cell) Parent() H3Cell {
+	res := c.Resolution()
+	if res == 0 {
+		return 0
+	}
+	return c.setResolution(res-1).setDigit(res, 7)
+}
+
+// Children returns the seven child cells, six for pentagons, in ascending
+// order. Returns nil at the maximum resolution.
+func (c H3Cell) Children() []H3Cell {
+	res := c.Resolution()
+	if res >= H3ResolutionMax {
+		return nil
+	}
+
+	pentagon := c.IsPentagon()
+	children := make([]H3Cell, 0, 7)
+	for d := 0; d < 7; d++ {
+		if d == 1 && pentagon {
+			continue
+		}
+		children = append(children, c.setResolution(res+1).setDigit(res+1, d))
+	}
+	return children
+}
+
+// Center returns the center of the cell.
+func (c H3Cell) Center() (lat, lon float64) {
+	f := c.faceIJK()
+	x, y := f.ijk.hex2d()
+	φ, λ := h3Hex2dToGeo(x, y, f.face, c.Resolution(), false)
+	return rad2deg(φ), rad2deg(λ)
+}
+
+// Vertices returns the corners of the cell, counter-clockwise. Edges which
+// cross an icosahedron edge are split by an additional vertex. Note that
+// cell edges are geodesics.
+func (c H3Cell) Vertices() []Point {
+	var pts []Point
+	for _, v := range c.boundary() {
+		pts = append(pts, Point{Lat: rad2deg(v[0]), Lon: rad2deg(v[1])})
+	}
+	return pts
+}
+
+// Bounds implements Fence. Cells crossing the antimeridian or containing a
+// pole span all longitudes.
+func (c H3Cell) Bounds() Area {
+	return c.bounds(c.vertices())
+}
+
+// Contains implements Fence.
+func (c H3Cell) Contains(lat, lon float64) bool {
+	return (Point{Lat: lat, Lon: lon}).valid() && h3CellFromLatLon(lat, lon, c.Resolution()) == c
+}
+
+// Relate implements Fence.
+func (c H3Cell) Relate(a Area) Relation {
+	vs := c.vertices()
+	return s2RelatePolygon(vs, c.bounds(vs), a, c.Contains)
+}
+
+// H3Cells returns the H3 cells at the given resolution which intersect the
+// area, in ascending order. The number of cells grows by a factor of seven
+// with each resolution, callers should choose a resolution appropriate to the
+// size of the area. It returns nil if the area intersects more than
+// MaxCoverCells cells, use H3CellsLimit to choose a different limit.
+func (a Area) H3Cells(res int) []H3Cell {
+	cells, _ := a.H3CellsLimit(res, MaxCoverCells)
+	return cells
+}
+
+// H3CellsLimit is like H3Cells, but returns an error if the area intersects
+// more than maxCells cells.
+func (a Area) H3CellsLimit(res, maxCells int) ([]H3Cell, error) {
+	if res < 0 || res > H3ResolutionMax {
+		return nil, errInvalidH3Cell
+	}
+	if !(a.MinLat <= a.MaxLat && a.MinLon <= a.MaxLon) {
+		return nil, errInvalidArea
+	}
+
+	// children do not nest within their parents, so rather than descending
+	// the hierarchy, flood fill across cell edges, starting at the center
+	lat := math.Max(-90, math.Min(90, (a.MinLat+a.MaxLat)/2))
+	lon := math.Max(-180, math.Min(180, (a.MinLon+a.MaxLon)/2))
+	start := h3CellFromLatLon(lat, lon, res)
+
+	var cells []H3Cell
+	seen := map[H3Cell]bool{start: true}
+	for queue := []H3Cell{start}; len(queue) != 0; queue = queue[1:] {
+		c := queue[0]
+		if c.Relate(a) == Outside {
+			continue
+		}
+		if cells = append(cells, c); len(cells) > maxCells {
+			return nil, errTooManyCells
+		}
+		for _, n := range c.neighbors() {
+			if !seen[n] {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+
+	sort.Slice(cells, func(i, j int) bool { return cells[i] < cells[j] })
+	return cells, nil
+}
+
+// H3Cells returns the H3 cells at the given resolution which intersect the
+// hash, in ascending order.
+func (h Hash) H3Cells(res int) []H3Cell {
+	return h.Decode().H3Cells(res)
+}
+
+func (c H3Cell) baseCell() int { return int(c >> h3BaseCellOffset & 127) }
+
+// digit returns the digit of the cell at resolution r, i.e. the direction
+// from the center of its parent.
+func (c H3Cell) digit(r int) int { return int(c >> (3 * (H3ResolutionMax - r)) & 7) }
+
+func (c H3Cell) setDigit(r, d int) H3Cell {
+	shift := 3 * (H3ResolutionMax - r)
+	return c&^(7<<shift) | H3Cell(d)<<shift
+}
+
+func (c H3Cell) setResolution(res int) H3Cell {
+	return c&^(15<<h3ResOffset) | H3Cell(res)<<h3ResOffset
+}
+
+// leadingDigit returns the first non-zero digit of the cell.
+func (c H3Cell) leadingDigit() int {
+	for r := 1; r <= c.Resolution(); r++ {
+		if d := c.digit(r); d != 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// rotate60ccw and rotate60cw rotate all digits of the cell.
+func (c H3Cell) rotate60ccw() H3Cell {
+	for r := 1; r <= c.Resolution(); r++ {
+		c = c.setDigit(r, h3DigitCCW[c.digit(r)])
+	}
+	return c
+}
+
+func (c H3Cell) rotate60cw() H3Cell {
+	for r := 1; r <= c.Resolution(); r++ {
+		c = c.setDigit(r, h3DigitCW[c.digit(r)])
+	}
+	return c
+}
+
+// rotatePent60ccw rotates all digits of a cell about a pentagonal center,
+// skipping the deleted sub-sequence of the k axis.
+func (c H3Cell) rotatePent60ccw() H3Cell {
+	found := false
+	for r := 1; r <= c.Resolution(); r++ {
+		c = c.setDigit(r, h3DigitCCW[c.digit(r)])
+		if !found && c.digit(r) != 0 {
+			found = true
+			if c.leadingDigit() == 1 {
+				c = c.rotate60ccw()
+			}
+		}
+	}
+	return c
+}
+
+// faceIJK returns the face and the ijk coordinates of the cell center.
+func (c H3Cell) faceIJK() h3FaceIJK {
+	bc := c.baseCell()
+	if bc >= h3NumBaseCells {
+		return h3FaceIJK{}
+	}
+
+	// all of the sub-sequence 5 of pentagons needs to be adjusted for the
+	// deleted sub-sequence, sub-sequence 4 is handled below
+	base := h3BaseCells[bc]
+	if base.pentagon && c.leadingDigit() == 5 {
+		c = c.rotate60cw()
+	}
+
+	res := c.Resolution()
+	f := h3FaceIJK{face: base.face, ijk: base.ijk}
+	overage := base.pentagon || (res != 0 && f.ijk != h3IJK{})
+	for r := 1; r <= res; r++ {
+		if r%2 == 1 {
+			f.ijk = f.ijk.downAp7()
+		} else {
+			f.ijk = f.ijk.downAp7r()
+		}
+		f.ijk = f.ijk.neighbor(c.digit(r))
+	}
+	if !overage {
+		return f
+	}
+
+	// the cell may be on an adjacent face, drop class III cells into the
+	// next finer class II grid to check
+	orig, adjRes := f.ijk, res
+	if res%2 == 1 {
+		f.ijk = f.ijk.downAp7r()
+		adjRes++
+	}
+
+	if f.adjustOverage(adjRes, base.pentagon && c.leadingDigit() == 4, false) != h3NoOverage {
+		// pentagons may overflow into a second face
+		if base.pentagon {
+			for f.adjustOverage(adjRes, false, false) != h3NoOverage {
+			}
+		}
+		if adjRes != res {
+			f.ijk = f.ijk.upAp7r()
+		}
+	} else if adjRes != res {
+		f.ijk = orig
+	}
+	return f
+}
+
+// boundary returns the vertices of the cell in radians.
+func (c H3Cell) boundary() [][2]float64 {
+	if c.IsPentagon() {
+		return c.pentagonBoundary()
+	}
+
+	res, center := c.Resolution(), c.faceIJK()
+	verts, adjRes := center.vertices(res, 6)
+
+	var pts [][2]float64
+	lastFace, lastOverage := -1, h3NoOverage
+	for k := 0; k <= len(verts); k++ {
+		v := k % len(verts)
+		f := verts[v]
+		overage := f.adjustOverage(adjRes, false, true)
+
+		// edges of class III cells may cross an icosahedron edge, in which
+		// case the intersection is added as a vertex, unless it is one of the
+		// edge's vertices anyway
+		if res%2 == 1 && k > 0 && f.face != lastFace && lastOverage != h3FaceEdge {
+			x0, y0 := verts[(v+5)%6].ijk.hex2d()
+			x1, y1 := verts[v].ijk.hex2d()
+			face := lastFace
+			if lastFace == center.face {
+				face = f.face
+			}
+			e0, e1 := h3FaceEdge2d(adjRes, h3AdjacentFaceDir(center.face, face))
+			x, y := h3Intersect(x0, y0, x1, y1, e0[0], e0[1], e1[0], e1[1])
+			if !h3AlmostEqual(x0, y0, x, y) && !h3AlmostEqual(x1, y1, x, y) {
+				φ, λ := h3Hex2dToGeo(x, y, center.face, adjRes, true)
+				pts = append(pts, [2]float64{φ, λ})
+			}
+		}
+
+		if k < len(verts) {
+			x, y := f.ijk.hex2d()
+			φ, λ := h3Hex2dToGeo(x, y, f.face, adjRes, true)
+			pts = append(pts, [2]float64{φ, λ})
+		}
+		lastFace, lastOverage = f.face, overage
+	}
+	return pts
+}
+
+func (c H3Cell) pentagonBoundary() [][2]float64 {
+	res := c.Resolution()
+	verts, adjRes := c.faceIJK().vertices(res, 5)
+
+	var pts [][2]float64
+	var last h3FaceIJK
+	for k := 0; k <= len(verts); k++ {
+		f := verts[k%len(verts)]
+		for f.adjustOverage(adjRes, false, true) == h3NewFace {
+		}
+
+		// all edges of class III pentagons cross icosahedron edges
+		if res%2 == 1 && k > 0 {
+			x0, y0 := last.ijk.hex2d()
+
+			// move the vertex into the coordinates of the last face
+			o := h3FaceNeighbors[f.face][h3AdjacentFaceDir(f.face, last.face)]
+			ijk := f.ijk
+			for i := 0; i < o.rot; i++ {
+				ijk = ijk.rotate60ccw()
+			}
+			ijk = ijk.add(o.translate.scale(3 * h3UnitScale(adjRes))).normalize()
+			x1, y1 := ijk.hex2d()
+
+			e0, e1 := h3FaceEdge2d(adjRes, h3AdjacentFaceDir(o.face, f.face))
+			x, y := h3Intersect(x0, y0, x1, y1, e0[0], e0[1], e1[0], e1[1])
+			φ, λ := h3Hex2dToGeo(x, y, o.face, adjRes, true)
+			pts = append(pts, [2]float64{φ, λ})
+		}
+
+		if k < len(verts) {
+			x, y := f.ijk.hex2d()
+			φ, λ := h3Hex2dToGeo(x, y, f.face, adjRes, true)
+			pts = append(pts, [2]float64{φ, λ})
+		}
+		last = f
+	}
+	return pts
+}
+
+// vertices returns the unit vectors of the cell corners, counter-clockwise.
+func (c H3Cell) vertices() []s2Point {
+	var vs []s2Point
+	for _, v := range c.boundary() {
+		vs = append(vs, h3RadsToXYZ(v[0], v[1]))
+	}
+	return vs
+}
+
+// bounds returns the bounding box of the cell with the given vertices.
+func (c H3Cell) bounds(vs []s2Point) Area {
+	_, center := c.Center()
+	return s2PolygonBounds(vs, center, c.Contains(90, 0), c.Contains(-90, 0))
+}
+
+// neighbors returns the cells across the edges of the cell, found by
+// stepping just beyond the middle of each edge. Edges split at icosahedron
+// edges may return the same neighbor twice, or a cell which only shares a
+// vertex.
+func (c H3Cell) neighbors() []H3Cell {
+	res, vs := c.Resolution(), c.vertices()
+	lat, lon := c.Center()
+	center := s2LatLonToXYZ(lat, lon)
+
+	cells := make([]H3Cell, 0, len(vs))
+	for k := range vs {
+		a, b := vs[k], vs[(k+1)%len(vs)]
+		m := s2Point{a[0] + b[0], a[1] + b[1], a[2] + b[2]}.normalize()
+		p := s2Point{m[0] + (m[0]-center[0])/8, m[1] + (m[1]-center[1])/8, m[2] + (m[2]-center[2])/8}
+		if n := h3CellFromLatLon(rad2deg(math.Atan2(p[2], math.Hypot(p[0], p[1]))), rad2deg(math.Atan2(p[1], p[0])), res); n != c {
+			cells = append(cells, n)
+		}
+	}
+	return cells
+}
+
+// h3CellFromLatLon returns the cell which contains a valid location.
+func h3CellFromLatLon(lat, lon float64, res int) H3Cell {
+	face, x, y := h3GeoToHex2d(deg2rad(lat), deg2rad(lon), res)
+	return h3CellFromFaceIJK(h3FaceIJK{face: face, ijk: h3Hex2dToIJK(x, y)}, res)
+}
+
+// h3CellFromFaceIJK returns the cell at the given face and ijk coordinates.
+// It returns 0 if the coordinates are out of range.
+func h3CellFromFaceIJK(f h3FaceIJK, res int) H3Cell {
+	c := H3Cell(h3ModeCell|h3UnusedDigits) | H3Cell(res)<<h3ResOffset
+
+	// build the digits from the finest resolution up, until the ijk
+	// coordinates are those of the base cell
+	ijk := f.ijk
+	for r := res; r > 0; r-- {
+		last := ijk
+		var center h3IJK
+		if r%2 == 1 {
+			ijk = ijk.upAp7()
+			center = ijk.downAp7()
+		} else {
+			ijk = ijk.upAp7r()
+			center = ijk.downAp7r()
+		}
+		c = c.setDigit(r, last.sub(center).normalize().digit())
+	}
+	if ijk.i > 2 || ijk.j > 2 || ijk.k > 2 {
+		return 0
+	}
+
+	// rotate into the orientation of the base cell
+	bc := h3FaceBaseCells[f.face][ijk.i][ijk.j][ijk.k]
+	c |= H3Cell(bc.cell) << h3BaseCellOffset
+	if base := h3BaseCells[bc.cell]; base.pentagon {
+		// force rotation out of the deleted sub-sequence of the k axis
+		if c.leadingDigit() == 1 {
+			if base.cwOffsets[0] == f.face || base.cwOffsets[1] == f.face {
+				c = c.rotate60cw()
+			} else {
+				c = c.rotate60ccw()
+			}
+		}
+		for i := 0; i < bc.rot; i++ {
+			c = c.rotatePent60ccw()
+		}
+	} else {
+		for i := 0; i < bc.rot; i++ {
+			c = c.rotate60ccw()
+		}
+	}
+	return c
+}
+
+// h3GeoToHex2d returns the closest icosahedron face of a location, given in
+// radians, and its 2D hex coordinates on that face.
+func h3GeoToHex2d(lat, lon float64, res int) (face int, x, y float64) {
+	p := h3RadsToXYZ(lat, lon)
+	sqd := 5.0
+	for f, fc := range h3FaceCenters {
+		d := fc.point[0] - p[0]
+		e := fc.point[1] - p[1]
+		g := fc.point[2] - p[2]
+		if n := d*d + e*e + g*g; n < sqd {
+			face, sqd = f, n
+		}
+	}
+
+	// cos(r) = 1 - 2 * sin^2(r/2) = 1 - sqd/2
+	r := math.Acos(1 - sqd*0.5)
+	if r < h3Epsilon {
+		return face, 0, 0
+	}
+
+	fc := h3FaceCenters[face]
+	theta := h3PosAngle(fc.axisAz - h3PosAngle(h3Azimuth(fc.lat, fc.lon, lat, lon)))
+	if res%2 == 1 {
+		theta = h3PosAngle(theta - h3Ap7Rot)
+	}
+
+	// gnomonic scaling, for the unit length of the resolution
+	r = math.Tan(r) * h3InvRes0U
+	for i := 0; i < res; i++ {
+		r *= h3Sqrt7
+	}
+	return face, r * math.Cos(theta), r * math.Sin(theta)
+}
+
+// h3Hex2dToGeo returns the location, in radians, of 2D hex coordinates on a
+// face. Substrate grids have three times the resolution, and are already
+// rotated for class III.
+func h3Hex2dToGeo(x, y float64, face, res int, substrate bool) (lat, lon float64) {
+	fc := h3FaceCenters[face]
+	r := math.Sqrt(x*x + y*y)
+	if r < h3Epsilon {
+		return fc.lat, fc.lon
+	}
+
+	theta := math.Atan2(y, x)
+	for i := 0; i < res; i++ {
+		r *= h3RSqrt7
+	}
+	if substrate {
+		r *= h3OneThird
+		if res%2 == 1 {
+			r *= h3RSqrt7
+		}
+	}
+	r = math.Atan(r * h3Res0U)
+
+	if !substrate && res%2 == 1 {
+		theta = h3PosAngle(theta + h3Ap7Rot)
+	}
+	theta = h3PosAngle(fc.axisAz - theta)
+	return h3AzDistance(fc.lat, fc.lon, theta, r)
+}
+
+// h3Azimuth returns the azimuth from one location to another, in radians.
+func h3Azimuth(lat1, lon1, lat2, lon2 float64) float64 {
+	return math.Atan2(math.Cos(lat2)*math.Sin(lon2-lon1),
+		math.Cos(lat1)*math.Sin(lat2)-math.Sin(lat1)*math.Cos(lat2)*math.Cos(lon2-lon1))
+}
+
+// h3AzDistance returns the location at the given azimuth and angular
+// distance from another, in radians.
+func h3AzDistance(lat1, lon1, az, distance float64) (lat, lon float64) {
+	if distance < h3Epsilon {
+		return lat1, lon1
+	}
+
+	az = h3PosAngle(az)
+	if az < h3Epsilon || math.Abs(az-math.Pi) < h3Epsilon {
+		// due north or south
+		if az < h3Epsilon {
+			lat = lat1 + distance
+		} else {
+			lat = lat1 - distance
+		}
+		if math.Abs(lat-math.Pi/2) < h3Epsilon {
+			return math.Pi / 2, 0
+		} else if math.Abs(lat+math.Pi/2) < h3Epsilon {
+			return -math.Pi / 2, 0
+		}
+		return lat, h3ConstrainLon(lon1)
+	}
+
+	sinlat := math.Sin(lat1)*math.Cos(distance) + math.Cos(lat1)*math.Sin(distance)*math.Cos(az)
+	lat = math.Asin(math.Max(-1, math.Min(1, sinlat)))
+	if math.Abs(lat-math.Pi/2) < h3Epsilon {
+		return math.Pi / 2, 0
+	} else if math.Abs(lat+math.Pi/2) < h3Epsilon {
+		return -math.Pi / 2, 0
+	}
+
+	invcos := 1.0 / math.Cos(lat)
+	sinlon := math.Sin(az) * math.Sin(distance) * invcos
+	coslon := (math.Cos(distance) - math.Sin(lat1)*math.Sin(lat)) / math.Cos(lat1) * invcos
+	sinlon = math.Max(-1, math.Min(1, sinlon))
+	coslon = math.Max(-1, math.Min(1, coslon))
+	return lat, h3ConstrainLon(lon1 + math.Atan2(sinlon, coslon))
+}
+
+func h3PosAngle(rads float64) float64 {
+	tmp := rads
+	if rads < 0 {
+		tmp = rads + 2*math.Pi
+	}
+	if rads >= 2*math.Pi {
+		tmp -= 2 * math.Pi
+	}
+	return tmp
+}
+
+func h3ConstrainLon(lon float64) float64 {
+	for lon > math.Pi {
+		lon -= 2 * math.Pi
+	}
+	for lon < -math.Pi {
+		lon += 2 * math.Pi
+	}
+	return lon
+}
+
+func h3RadsToXYZ(lat, lon float64) s2Point {
+	r := math.Cos(lat)
+	return s2Point{math.Cos(lon) * r, math.Sin(lon) * r, math.Sin(lat)}
+}
+
+// h3Intersect returns the intersection of the lines through p0, p1 and
+// through p2, p3.
+func h3Intersect(x0, y0, x1, y1, x2, y2, x3, y3 float64) (x, y float64) {
+	s1x, s1y := x1-x0, y1-y0
+	s2x, s2y := x3-x2, y3-y2
+	t := (s2x*(y0-y2) - s2y*(x0-x2)) / (-s2x*s1y + s1x*s2y)
+	return x0 + t*s1x, y0 + t*s1y
+}
+
+func h3AlmostEqual(x0, y0, x1, y1 float64) bool {
+	return math.Abs(x0-x1) < h3FltEpsilon && math.Abs(y0-y1) < h3FltEpsilon
+}
+
+// h3UnitScale returns the length of a class II resolution 0 unit vector in
+// units of the given class II resolution.
+func h3UnitScale(res int) int {
+	n := 1
+	for i := 0; i < res; i += 2 {
+		n *= 7
+	}
+	return n
+}
+
+// h3FaceEdge2d returns the 2D hex coordinates of the ends of a face edge,
+// in a class II substrate grid.
+func h3FaceEdge2d(res, dir int) (a, b [2]float64) {
+	maxDim := float64(2 * h3UnitScale(res))
+	v0 := [2]float64{3 * maxDim, 0}
+	v1 := [2]float64{-1.5 * maxDim, 3 * h3Sqrt3_2 * maxDim}
+	v2 := [2]float64{-1.5 * maxDim, -3 * h3Sqrt3_2 * maxDim}
+	switch dir {
+	case h3IJ:
+		return v0, v1
+	case h3JK:
+		return v1, v2
+	default:
+		return v2, v0
+	}
+}
+
+// h3AdjacentFaceDir returns the direction of an adjacent face, 0 for the
+// face itself and -1 if the faces are not adjacent.
+func h3AdjacentFaceDir(from, to int) int {
+	for dir, o := range h3FaceNeighbors[from] {
+		if o.face == to {
+			return dir
+		}
+	}
+	return -1
+}
+
+// --------------------------------------------------------------------
+
+// h3IJK are hex coordinates along three axes, 120° apart. Normalized
+// coordinates are non-negative, with at least one zero.
+type h3IJK struct{ i, j, k int }
+
+// h3FaceIJK are hex coordinates on an icosahedron face.
+type h3FaceIJK struct {
+	face int
+	ijk  h3IJK
+}
+
+// h3FaceOrient describes the transformation into the coordinates of an
+// adjacent face.
+type h3FaceOrient struct {
+	face      int
+	translate h3IJK
+	rot       int // ccw rotations by 60°
+}
+
+// Directions of the faceNeighbors table.
+const (
+	h3IJ = 1
+	h3KI = 2
+	h3JK = 3
+)
+
+// Overages of face coordinates.
+const (
+	h3NoOverage = iota
+	h3FaceEdge
+	h3NewFace
+)
+
+// h3UnitVecs are the unit vectors of the digits, i.e. directions.
+var h3UnitVecs = [7]h3IJK{{0, 0, 0}, {0, 0, 1}, {0, 1, 0}, {0, 1, 1}, {1, 0, 0}, {1, 0, 1}, {1, 1, 0}}
+
+// h3DigitCCW and h3DigitCW rotate digits by 60°.
+var (
+	h3DigitCCW = [8]int{0, 5, 3, 1, 6, 4, 2, 7}
+	h3DigitCW  = [8]int{0, 3, 6, 2, 5, 1, 4, 7}
+)
+
+// h3VertexOffsets are the vertices of an origin-centered cell in a class II
+// substrate grid with aperture sequence 33r for class II resolutions and
+// 33r7r for class III resolutions, counter-clockwise from the i axis.
+var h3VertexOffsets = [2][6]h3IJK{
+	{{2, 1, 0}, {1, 2, 0}, {0, 2, 1}, {0, 1, 2}, {1, 0, 2}, {2, 0, 1}},
+	{{5, 4, 0}, {1, 5, 0}, {0, 5, 4}, {0, 1, 5}, {4, 0, 5}, {5, 0, 1}},
+}
+
+// h3Hex2dToIJK returns the coordinates of the cell which contains 2D hex
+// coordinates.
+func h3Hex2dToIJK(x, y float64) h3IJK {
+	// quantize into the ij system
+	a1, a2 := math.Abs(x), math.Abs(y)
+	x2 := a2 * h3RSin60
+	x1 := a1 + x2/2
+	m1, m2 := int(x1), int(x2)
+	r1, r2 := x1-float64(m1), x2-float64(m2)
+
+	var h h3IJK
+	if r1 < 0.5 {
+		if r1 < 1.0/3.0 {
+			h.i, h.j = m1, m2
+			if r2 >= (1.0+r1)/2.0 {
+				h.j++
+			}
+		} else {
+			h.i, h.j = m1, m2
+			if r2 >= 1.0-r1 {
+				h.j++
+			}
+			if 1.0-r1 <= r2 && r2 < 2.0*r1 {
+				h.i++
+			}
+		}
+	} else {
+		if r1 < 2.0/3.0 {
+			h.i, h.j = m1+1, m2
+			if r2 >= 1.0-r1 {
+				h.j++
+			}
+			if 2.0*r1-1.0 < r2 && r2 < 1.0-r1 {
+				h.i--
+			}
+		} else {
+			h.i, h.j = m1+1, m2
+			if r2 >= r1/2.0 {
+				h.j++
+			}
+		}
+	}
+
+	// fold across the axes if necessary
+	if x < 0 {
+		if h.j%2 == 0 {
+			h.i -= 2 * (h.i - h.j/2)
+		} else {
+			h.i -= 2*(h.i-(h.j+1)/2) + 1
+		}
+	}
+	if y < 0 {
+		h.i -= (2*h.j + 1) / 2
+		h.j = -h.j
+	}
+	return h.normalize()
+}
+
+func (c h3IJK) add(o h3IJK) h3IJK { return h3IJK{c.i + o.i, c.j + o.j, c.k + o.k} }
+
+func (c h3IJK) sub(o h3IJK) h3IJK { return h3IJK{c.i - o.i, c.j - o.j, c.k - o.k} }
+
+func (c h3IJK) scale(n int) h3IJK { return h3IJK{c.i * n, c.j * n, c.k * n} }
+
+func (c h3IJK) normalize() h3IJK {
+	if c.i < 0 {
+		c.j, c.k, c.i = c.j-c.i, c.k-c.i, 0
+	}
+	if c.j < 0 {
+		c.i, c.k, c.j = c.i-c.j, c.k-c.j, 0
+	}
+	if c.k < 0 {
+		c.i, c.j, c.k = c.i-c.k, c.j-c.k, 0
+	}
+	if n := min(c.i, c.j, c.k); n > 0 {
+		c.i, c.j, c.k = c.i-n, c.j-n, c.k-n
+	}
+	return c
+}
+
+// hex2d returns the 2D hex coordinates of the cell center.
+func (c h3IJK) hex2d() (x, y float64) {
+	i, j := float64(c.i-c.k), float64(c.j-c.k)
+	return i - 0.5*j, j * h3Sqrt3_2
+}
+
+// digit returns the digit of a unit vector, 7 if it is none.
+func (c h3IJK) digit() int {
+	c = c.normalize()
+	for d, v := range h3UnitVecs {
+		if c == v {
+			return d
+		}
+	}
+	return 7
+}
+
+func (c h3IJK) neighbor(digit int) h3IJK {
+	if digit > 0 && digit < 7 {
+		return c.add(h3UnitVecs[digit]).normalize()
+	}
+	return c
+}
+
+// transform returns the coordinates in the system with the given unit
+// vectors.
+func (c h3IJK) transform(i, j, k h3IJK) h3IJK {
+	return i.scale(c.i).add(j.scale(c.j)).add(k.scale(c.k)).normalize()
+}
+
+// upAp7 and upAp7r return the coordinates of the containing cell in the
+// coarser aperture 7 grid, rotated counter-clockwise or clockwise.
+func (c h3IJK) upAp7() h3IJK {
+	i, j := c.i-c.k, c.j-c.k
+	return h3IJK{
+		i: int(math.Round(float64(3*i-j) * h3OneSeventh)),
+		j: int(math.Round(float64(i+2*j) * h3OneSeventh)),
+	}.normalize()
+}
+
+func (c h3IJK) upAp7r() h3IJK {
+	i, j := c.i-c.k, c.j-c.k
+	return h3IJK{
+		i: int(math.Round(float64(2*i+j) * h3OneSeventh)),
+		j: int(math.Round(float64(3*j-i) * h3OneSeventh)),
+	}.normalize()
+}
+
+// downAp7, downAp7r, downAp3 and downAp3r return the coordinates of the
+// center cell in the finer aperture 7 or 3 grid, rotated counter-clockwise
+// or clockwise.
+func (c h3IJK) downAp7() h3IJK  { return c.transform(h3IJK{3, 0, 1}, h3IJK{1, 3, 0}, h3IJK{0, 1, 3}) }
+func (c h3IJK) downAp7r() h3IJK { return c.transform(h3IJK{3, 1, 0}, h3IJK{0, 3, 1}, h3IJK{1, 0, 3}) }
+func (c h3IJK) downAp3() h3IJK  { return c.transform(h3IJK{2, 0, 1}, h3IJK{1, 2, 0}, h3IJK{0, 1, 2}) }
+func (c h3IJK) downAp3r() h3IJK { return c.transform(h3IJK{2, 1, 0}, h3IJK{0, 2, 1}, h3IJK{1, 0, 2}) }
+
+func (c h3IJK) rotate60ccw() h3IJK {
+	return c.transform(h3IJK{1, 1, 0}, h3IJK{0, 1, 1}, h3IJK{1, 0, 1})
+}
+
+func (c h3IJK) rotate60cw() h3IJK {
+	return c.transform(h3IJK{1, 0, 1}, h3IJK{1, 1, 0}, h3IJK{0, 1, 1})
+}
+
+// vertices returns the first n vertices of the cell in a class II substrate
+// grid, and the resolution of that grid.
+func (f h3FaceIJK) vertices(res, n int) ([]h3FaceIJK, int) {
+	offsets := h3VertexOffsets[res%2]
+	center := f.ijk.downAp3().downAp3r()
+	if res%2 == 1 {
+		center = center.downAp7r()
+		res++
+	}
+
+	verts := make([]h3FaceIJK, n)
+	for v := range verts {
+		verts[v] = h3FaceIJK{face: f.face, ijk: center.add(offsets[v]).normalize()}
+	}
+	return verts, res
+}
+
+// adjustOverage moves class II coordinates which are beyond the edge of
+// their face onto the adjacent face. Pentagons with a leading 4 digit need
+// to be adjusted for the deleted sub-sequence.
+func (f *h3FaceIJK) adjustOverage(res int, pentLeading4, substrate bool) int {
+	maxDim, unit := 2*h3UnitScale(res), h3UnitScale(res)
+	if substrate {
+		maxDim, unit = 3*maxDim, 3*unit
+	}
+
+	sum := f.ijk.i + f.ijk.j + f.ijk.k
+	if substrate && sum == maxDim {
+		return h3FaceEdge
+	} else if sum <= maxDim {
+		return h3NoOverage
+	}
+
+	var o h3FaceOrient
+	switch {
+	case f.ijk.k > 0 && f.ijk.j > 0:
+		o = h3FaceNeighbors[f.face][h3JK]
+	case f.ijk.k > 0:
+		o = h3FaceNeighbors[f.face][h3KI]
+		if pentLeading4 {
+			origin := h3IJK{maxDim, 0, 0}
+			f.ijk = f.ijk.sub(origin).rotate60cw().add(origin)
+		}
+	default:
+		o = h3FaceNeighbors[f.face][h3IJ]
+	}
+
+	f.face = o.face
+	for i := 0; i < o.rot; i++ {
+		f.ijk = f.ijk.rotate60ccw()
+	}
+	f.ijk = f.ijk.add(o.translate.scale(unit)).normalize()
+
+	// vertices of pentagons may end up on an edge
+	if substrate && f.ijk.i+f.ijk.j+f.ijk.k == maxDim {
+		return h3FaceEdge
+	}
+	return h3NewFace
+}
+
+// --------------------------------------------------------------------
+
+// h3FaceCenters holds the icosahedron face centers in radians, as unit
+// vectors and the azimuths of their i axes.
+var h3FaceCenters = [20]struct {
+	lat, lon float64
+	point    s2Point
+	axisAz   float64
+}{
+	{0.803582649718989942, 1.248397419617396099, s2Point{0.2199307791404606, 0.6583691780274996, 0.7198475378926182}, 5.619958268523939882},
+	{1.307747883455638156, 2.536945009877921159, s2Point{-0.2139234834501421, 0.1478171829550703, 0.9656017935214205}, 5.760339081714187279},
+	{1.054751253523952054, -1.347517358900396623, s2Point{0.1092625278784797, -0.4811951572873210, 0.8697775121287253}, 0.780213654393430055},
+	{0.600191595538186799, -0.450603909469755746, s2Point{0.7428567301586791, -0.3593941678278028, 0.5648005936517033}, 0.430469363979999913},
+	{0.491715428198773866, 0.401988202911306943, s2Point{0.8112534709140969, 0.3448953237639384, 0.4721387736413930}, 6.130269123335111400},
+	{0.172745327415618701, 1.678146885280433686, s2Point{-0.1055498149613921, 0.9794457296411413, 0.1718874610009365}, 2.692877706530642877},
+	{0.605929321571350690, 2.953923329812411617, s2Point{-0.8075407579970092, 0.1533552485898818, 0.5695261994882688}, 2.982963003477243874},
+	{0.427370518328979641, -1.888876200336285401, s2Point{-0.2846148069787907, -0.8644080972654206, 0.4144792552473539}, 3.532912002790141181},
+	{-0.079066118549212831, -0.733429513380867741, s2Point{0.7405621473854482, -0.6673299564565524, -0.0789837646326737}, 3.494305004259568154},
+	{-0.230961644455383637, 0.506495587332349035, s2Point{0.8512303986474293, 0.4722343788582681, -0.2289137388687808}, 3.003214169499538391},
+	{0.079066118549212831, 2.408163140208925497, s2Point{-0.7405621473854481, 0.6673299564565524, 0.0789837646326737}, 5.930472956509811562},
+	{0.230961644455383637, -2.635097066257444203, s2Point{-0.8512303986474292, -0.4722343788582682, 0.2289137388687808}, 0.138378484090254847},
+	{-0.172745327415618701, -1.463445768309359553, s2Point{0.1055498149613919, -0.9794457296411413, -0.1718874610009365}, 0.448714947059150361},
+	{-0.605929321571350690, -0.187669323777381622, s2Point{0.8075407579970092, -0.1533552485898819, -0.5695261994882688}, 0.158629650112549365},
+	{-0.427370518328979641, 1.252716453253507838, s2Point{0.2846148069787908, 0.8644080972654204, -0.4144792552473539}, 5.891865957979238535},
+	{-0.600191595538186799, 2.690988744120037492, s2Point{-0.7428567301586791, 0.3593941678278027, -0.5648005936517033}, 2.711123289609793325},
+	{-0.491715428198773866, -2.739604450678486295, s2Point{-0.8112534709140971, -0.3448953237639382, -0.4721387736413930}, 3.294508837434268316},
+	{-0.803582649718989942, -1.893195233972397139, s2Point{-0.2199307791404607, -0.6583691780274996, -0.7198475378926182}, 3.804819692245439833},
+	{-1.307747883455638156, -0.604647643711872080, s2Point{0.2139234834501420, -0.1478171829550704, -0.9656017935214205}, 3.664438879055192436},
+	{-1.054751253523952054, 1.794075294689396615, s2Point{-0.1092625278784796, 0.4811951572873210, -0.8697775121287253}, 2.361378999196363184},
+}
+
+// h3FaceNeighbors holds the faces across the ij, ki and jk edges of each
+// face, with the translation and ccw rotations into their coordinates.
+var h3FaceNeighbors = [20][4]h3FaceOrient{
+	{{0, h3IJK{0, 0, 0}, 0}, {4, h3IJK{2, 0, 2}, 1}, {1, h3IJK{2, 2, 0}, 5}, {5, h3IJK{0, 2, 2}, 3}},
+	{{1, h3IJK{0, 0, 0}, 0}, {0, h3IJK{2, 0, 2}, 1}, {2, h3IJK{2, 2, 0}, 5}, {6, h3IJK{0, 2, 2}, 3}},
+	{{2, h3IJK{0, 0, 0}, 0}, {1, h3IJK{2, 0, 2}, 1}, {3, h3IJK{2, 2, 0}, 5}, {7, h3IJK{0, 2, 2}, 3}},
+	{{3, h3IJK{0, 0, 0}, 0}, {2, h3IJK{2, 0, 2}, 1}, {4, h3IJK{2, 2, 0}, 5}, {8, h3IJK{0, 2, 2}, 3}},
+	{{4, h3IJK{0, 0, 0}, 0}, {3, h3IJK{2, 0, 2}, 1}, {0, h3IJK{2, 2, 0}, 5}, {9, h3IJK{0, 2, 2}, 3}},
+	{{5, h3IJK{0, 0, 0}, 0}, {10, h3IJK{2, 2, 0}, 3}, {14, h3IJK{2, 0, 2}, 3}, {0, h3IJK{0, 2, 2}, 3}},
+	{{6, h3IJK{0, 0, 0}, 0}, {11, h3IJK{2, 2, 0}, 3}, {10, h3IJK{2, 0, 2}, 3}, {1, h3IJK{0, 2, 2}, 3}},
+	{{7, h3IJK{0, 0, 0}, 0}, {12, h3IJK{2, 2, 0}, 3}, {11, h3IJK{2, 0, 2}, 3}, {2, h3IJK{0, 2, 2}, 3}},
+	{{8, h3IJK{0, 0, 0}, 0}, {13, h3IJK{2, 2, 0}, 3}, {12, h3IJK{2, 0, 2}, 3}, {3, h3IJK{0, 2, 2}, 3}},
+	{{9, h3IJK{0, 0, 0}, 0}, {14, h3IJK{2, 2, 0}, 3}, {13, h3IJK{2, 0, 2}, 3}, {4, h3IJK{0, 2, 2}, 3}},
+	{{10, h3IJK{0, 0, 0}, 0}, {5, h3IJK{2, 2, 0}, 3}, {6, h3IJK{2, 0, 2}, 3}, {15, h3IJK{0, 2, 2}, 3}},
+	{{11, h3IJK{0, 0, 0}, 0}, {6, h3IJK{2, 2, 0}, 3}, {7, h3IJK{2, 0, 2}, 3}, {16, h3IJK{0, 2, 2}, 3}},
+	{{12, h3IJK{0, 0, 0}, 0}, {7, h3IJK{2, 2, 0}, 3}, {8, h3IJK{2, 0, 2}, 3}, {17, h3IJK{0, 2, 2}, 3}},
+	{{13, h3IJK{0, 0, 0}, 0}, {8, h3IJK{2, 2, 0}, 3}, {9, h3IJK{2, 0, 2}, 3}, {18, h3IJK{0, 2, 2}, 3}},
+	{{14, h3IJK{0, 0, 0}, 0}, {9, h3IJK{2, 2, 0}, 3}, {5, h3IJK{2, 0, 2}, 3}, {19, h3IJK{0, 2, 2}, 3}},
+	{{15, h3IJK{0, 0, 0}, 0}, {16, h3IJK{2, 0, 2}, 1}, {19, h3IJK{2, 2, 0}, 5}, {10, h3IJK{0, 2, 2}, 3}},
+	{{16, h3IJK{0, 0, 0}, 0}, {17, h3IJK{2, 0, 2}, 1}, {15, h3IJK{2, 2, 0}, 5}, {11, h3IJK{0, 2, 2}, 3}},
+	{{17, h3IJK{0, 0, 0}, 0}, {18, h3IJK{2, 0, 2}, 1}, {16, h3IJK{2, 2, 0}, 5}, {12, h3IJK{0, 2, 2}, 3}},
+	{{18, h3IJK{0, 0, 0}, 0}, {19, h3IJK{2, 0, 2}, 1}, {17, h3IJK{2, 2, 0}, 5}, {13, h3IJK{0, 2, 2}, 3}},
+	{{19, h3IJK{0, 0, 0}, 0}, {15, h3IJK{2, 0, 2}, 1}, {18, h3IJK{2, 2, 0}, 5}, {14, h3IJK{0, 2, 2}, 3}},
+}
+
+// h3FaceBaseCells holds the base cell and the ccw rotations into its
+// coordinates at each resolution 0 ijk coordinate of each face.
+var h3FaceBaseCells = [20][3][3][3]struct{ cell, rot int }{
+	{
+		{{{16, 0}, {18, 0}, {24, 0}}, {{33, 0}, {30, 0}, {32, 3}}, {{49, 1}, {48, 3}, {50, 3}}},
+		{{{8, 0}, {5, 5}, {10, 5}}, {{22, 0}, {16, 0}, {18, 0}}, {{41, 1}, {33, 0}, {30, 0}}},
+		{{{4, 0}, {0, 5}, {2, 5}}, {{15, 1}, {8, 0}, {5, 5}}, {{31, 1}, {22, 0}, {16, 0}}},
+	},
+	{
+		{{{2, 0}, {6, 0}, {14, 0}}, {{10, 0}, {11, 0}, {17, 3}}, {{24, 1}, {23, 3}, {25, 3}}},
+		{{{0, 0}, {1, 5}, {9, 5}}, {{5, 0}, {2, 0}, {6, 0}}, {{18, 1}, {10, 0}, {11, 0}}},
+		{{{4, 1}, {3, 5}, {7, 5}}, {{8, 1}, {0, 0}, {1, 5}}, {{16, 1}, {5, 0}, {2, 0}}},
+	},
+	{
+		{{{7, 0}, {21, 0}, {38, 0}}, {{9, 0}, {19, 0}, {34, 3}}, {{14, 1}, {20, 3}, {36, 3}}},
+		{{{3, 0}, {13, 5}, {29, 5}}, {{1, 0}, {7, 0}, {21, 0}}, {{6, 1}, {9, 0}, {19, 0}}},
+		{{{4, 2}, {12, 5}, {26, 5}}, {{0, 1}, {3, 0}, {13, 5}}, {{2, 1}, {1, 0}, {7, 0}}},
+	},
+	{
+		{{{26, 0}, {42, 0}, {58, 0}}, {{29, 0}, {43, 0}, {62, 3}}, {{38, 1}, {47, 3}, {64, 3}}},
+		{{{12, 0}, {28, 5}, {44, 5}}, {{13, 0}, {26, 0}, {42, 0}}, {{21, 1}, {29, 0}, {43, 0}}},
+		{{{4, 3}, {15, 5}, {31, 5}}, {{3, 1}, {12, 0}, {28, 5}}, {{7, 1}, {13, 0}, {26, 0}}},
+	},
+	{
+		{{{31, 0}, {41, 0}, {49, 0}}, {{44, 0}, {53, 0}, {61, 3}}, {{58, 1}, {65, 3}, {75, 3}}},
+		{{{15, 0}, {22, 5}, {33, 5}}, {{28, 0}, {31, 0}, {41, 0}}, {{42, 1}, {44, 0}, {53, 0}}},
+		{{{4, 4}, {8, 5}, {16, 5}}, {{12, 1}, {15, 0}, {22, 5}}, {{26, 1}, {28, 0}, {31, 0}}},
+	},
+	{
+		{{{50, 0}, {48, 0}, {49, 3}}, {{32, 0}, {30, 3}, {33, 3}}, {{24, 3}, {18, 3}, {16, 3}}},
+		{{{70, 0}, {67, 0}, {66, 3}}, {{52, 3}, {50, 0}, {48, 0}}, {{37, 3}, {32, 0}, {30, 3}}},
+		{{{83, 0}, {87, 3}, {85, 3}}, {{74, 3}, {70, 0}, {67, 0}}, {{57, 1}, {52, 3}, {50, 0}}},
+	},
+	{
+		{{{25, 0}, {23, 0}, {24, 3}}, {{17, 0}, {11, 3}, {10, 3}}, {{14, 3}, {6, 3}, {2, 3}}},
+		{{{45, 0}, {39, 0}, {37, 3}}, {{35, 3}, {25, 0}, {23, 0}}, {{27, 3}, {17, 0}, {11, 3}}},
+		{{{63, 0}, {59, 3}, {57, 3}}, {{56, 3}, {45, 0}, {39, 0}}, {{46, 3}, {35, 3}, {25, 0}}},
+	},
+	{
+		{{{36, 0}, {20, 0}, {14, 3}}, {{34, 0}, {19, 3}, {9, 3}}, {{38, 3}, {21, 3}, {7, 3}}},
+		{{{55, 0}, {40, 0}, {27, 3}}, {{54, 3}, {36, 0}, {20, 0}}, {{51, 3}, {34, 0}, {19, 3}}},
+		{{{72, 0}, {60, 3}, {46, 3}}, {{73, 3}, {55, 0}, {40, 0}}, {{71, 3}, {54, 3}, {36, 0}}},
+	},
+	{
+		{{{64, 0}, {47, 0}, {38, 3}}, {{62, 0}, {43, 3}, {29, 3}}, {{58, 3}, {42, 3}, {26, 3}}},
+		{{{84, 0}, {69, 0}, {51, 3}}, {{82, 3}, {64, 0}, {47, 0}}, {{76, 3}, {62, 0}, {43, 3}}},
+		{{{97, 0}, {89, 3}, {71, 3}}, {{98, 3}, {84, 0}, {69, 0}}, {{96, 3}, {82, 3}, {64, 0}}},
+	},
+	{
+		{{{75, 0}, {65, 0}, {58, 3}}, {{61, 0}, {53, 3}, {44, 3}}, {{49, 3}, {41, 3}, {31, 3}}},
+		{{{94, 0}, {86, 0}, {76, 3}}, {{81, 3}, {75, 0}, {65, 0}}, {{66, 3}, {61, 0}, {53, 3}}},
+		{{{107, 0}, {104, 3}, {96, 3}}, {{101, 3}, {94, 0}, {86, 0}}, {{85, 3}, {81, 3}, {75, 0}}},
+	},
+	{
+		{{{57, 0}, {59, 0}, {63, 3}}, {{74, 0}, {78, 3}, {79, 3}}, {{83, 3}, {92, 3}, {95, 3}}},
+		{{{37, 0}, {39, 3}, {45, 3}}, {{52, 0}, {57, 0}, {59, 0}}, {{70, 3}, {74, 0}, {78, 3}}},
+		{{{24, 0}, {23, 3}, {25, 3}}, {{32, 3}, {37, 0}, {39, 3}}, {{50, 3}, {52, 0}, {57, 0}}},
+	},
+	{
+		{{{46, 0}, {60, 0}, {72, 3}}, {{56, 0}, {68, 3}, {80, 3}}, {{63, 3}, {77, 3}, {90, 3}}},
+		{{{27, 0}, {40, 3}, {55, 3}}, {{35, 0}, {46, 0}, {60, 0}}, {{45, 3}, {56, 0}, {68, 3}}},
+		{{{14, 0}, {20, 3}, {36, 3}}, {{17, 3}, {27, 0}, {40, 3}}, {{25, 3}, {35, 0}, {46, 0}}},
+	},
+	{
+		{{{71, 0}, {89, 0}, {97, 3}}, {{73, 0}, {91, 3}, {103, 3}}, {{72, 3}, {88, 3}, {105, 3}}},
+		{{{51, 0}, {69, 3}, {84, 3}}, {{54, 0}, {71, 0}, {89, 0}}, {{55, 3}, {73, 0}, {91, 3}}},
+		{{{38, 0}, {47, 3}, {64, 3}}, {{34, 3}, {51, 0}, {69, 3}}, {{36, 3}, {54, 0}, {71, 0}}},
+	},
+	{
+		{{{96, 0}, {104, 0}, {107, 3}}, {{98, 0}, {110, 3}, {115, 3}}, {{97, 3}, {111, 3}, {119, 3}}},
+		{{{76, 0}, {86, 3}, {94, 3}}, {{82, 0}, {96, 0}, {104, 0}}, {{84, 3}, {98, 0}, {110, 3}}},
+		{{{58, 0}, {65, 3}, {75, 3}}, {{62, 3}, {76, 0}, {86, 3}}, {{64, 3}, {82, 0}, {96, 0}}},
+	},
+	{
+		{{{85, 0}, {87, 0}, {83, 3}}, {{101, 0}, {102, 3}, {100, 3}}, {{107, 3}, {112, 3}, {114, 3}}},
+		{{{66, 0}, {67, 3}, {70, 3}}, {{81, 0}, {85, 0}, {87, 0}}, {{94, 3}, {101, 0}, {102, 3}}},
+		{{{49, 0}, {48, 3}, {50, 3}}, {{61, 3}, {66, 0}, {67, 3}}, {{75, 3}, {81, 0}, {85, 0}}},
+	},
+	{
+		{{{95, 0}, {92, 0}, {83, 0}}, {{79, 0}, {78, 0}, {74, 3}}, {{63, 1}, {59, 3}, {57, 3}}},
+		{{{109, 0}, {108, 0}, {100, 5}}, {{93, 1}, {95, 0}, {92, 0}}, {{77, 1}, {79, 0}, {78, 0}}},
+		{{{117, 4}, {118, 5}, {114, 5}}, {{106, 1}, {109, 0}, {108, 0}}, {{90, 1}, {93, 1}, {95, 0}}},
+	},
+	{
+		{{{90, 0}, {77, 0}, {63, 0}}, {{80, 0}, {68, 0}, {56, 3}}, {{72, 1}, {60, 3}, {46, 3}}},
+		{{{106, 0}, {93, 0}, {79, 5}}, {{99, 1}, {90, 0}, {77, 0}}, {{88, 1}, {80, 0}, {68, 0}}},
+		{{{117, 3}, {109, 5}, {95, 5}}, {{113, 1}, {106, 0}, {93, 0}}, {{105, 1}, {99, 1}, {90, 0}}},
+	},
+	{
+		{{{105, 0}, {88, 0}, {72, 0}}, {{103, 0}, {91, 0}, {73, 3}}, {{97, 1}, {89, 3}, {71, 3}}},
+		{{{113, 0}, {99, 0}, {80, 5}}, {{116, 1}, {105, 0}, {88, 0}}, {{111, 1}, {103, 0}, {91, 0}}},
+		{{{117, 2}, {106, 5}, {90, 5}}, {{121, 1}, {113, 0}, {99, 0}}, {{119, 1}, {116, 1}, {105, 0}}},
+	},
+	{
+		{{{119, 0}, {111, 0}, {97, 0}}, {{115, 0}, {110, 0}, {98, 3}}, {{107, 1}, {104, 3}, {96, 3}}},
+		{{{121, 0}, {116, 0}, {103, 5}}, {{120, 1}, {119, 0}, {111, 0}}, {{112, 1}, {115, 0}, {110, 0}}},
+		{{{117, 1}, {113, 5}, {105, 5}}, {{118, 1}, {121, 0}, {116, 0}}, {{114, 1}, {120, 1}, {119, 0}}},
+	},
+	{
+		{{{114, 0}, {112, 0}, {107, 0}}, {{100, 0}, {102, 0}, {101, 3}}, {{83, 1}, {87, 3}, {85, 3}}},
+		{{{118, 0}, {120, 0}, {115, 5}}, {{108, 1}, {114, 0}, {112, 0}}, {{92, 1}, {100, 0}, {102, 0}}},
+		{{{117, 0}, {121, 5}, {119, 5}}, {{109, 1}, {118, 0}, {120, 0}}, {{95, 1}, {108, 1}, {114, 0}}},
+	},
+}
+
+// h3BaseCells holds the home face and ijk coordinates of each base cell.
+// Pentagons list the faces on which they are offset clockwise.
+var h3BaseCells = [122]struct {
+	face      int
+	ijk       h3IJK
+	pentagon  bool
+	cwOffsets [2]int
+}{
+	{1, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{2, h3IJK{1, 1, 0}, false, [2]int{0, 0}},
+	{1, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{2, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{0, h3IJK{2, 0, 0}, true, [2]int{-1, -1}},
+	{1, h3IJK{1, 1, 0}, false, [2]int{0, 0}},
+	{1, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{2, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{0, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{2, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{1, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{1, h3IJK{0, 1, 1}, false, [2]int{0, 0}},
+	{3, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{3, h3IJK{1, 1, 0}, false, [2]int{0, 0}},
+	{11, h3IJK{2, 0, 0}, true, [2]int{2, 6}},
+	{4, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{0, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{6, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{0, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{2, h3IJK{0, 1, 1}, false, [2]int{0, 0}},
+	{7, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{2, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{0, h3IJK{1, 1, 0}, false, [2]int{0, 0}},
+	{6, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{10, h3IJK{2, 0, 0}, true, [2]int{1, 5}},
+	{6, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{3, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{11, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{4, h3IJK{1, 1, 0}, false, [2]int{0, 0}},
+	{3, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{0, h3IJK{0, 1, 1}, false, [2]int{0, 0}},
+	{4, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{5, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{0, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{7, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{11, h3IJK{1, 1, 0}, false, [2]int{0, 0}},
+	{7, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{10, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{12, h3IJK{2, 0, 0}, true, [2]int{3, 7}},
+	{6, h3IJK{1, 0, 1}, false, [2]int{0, 0}},
+	{7, h3IJK{1, 0, 1}, false, [2]int{0, 0}},
+	{4, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{3, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{3, h3IJK{0, 1, 1}, false, [2]int{0, 0}},
+	{4, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{6, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{11, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{8, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{5, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{14, h3IJK{2, 0, 0}, true, [2]int{0, 9}},
+	{5, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{12, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{10, h3IJK{1, 1, 0}, false, [2]int{0, 0}},
+	{4, h3IJK{0, 1, 1}, false, [2]int{0, 0}},
+	{12, h3IJK{1, 1, 0}, false, [2]int{0, 0}},
+	{7, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{11, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{10, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{13, h3IJK{2, 0, 0}, true, [2]int{4, 8}},
+	{10, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{11, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{9, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{8, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{6, h3IJK{2, 0, 0}, true, [2]int{11, 15}},
+	{8, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{9, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{14, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{5, h3IJK{1, 0, 1}, false, [2]int{0, 0}},
+	{16, h3IJK{0, 1, 1}, false, [2]int{0, 0}},
+	{8, h3IJK{1, 0, 1}, false, [2]int{0, 0}},
+	{5, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{12, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{7, h3IJK{2, 0, 0}, true, [2]int{12, 16}},
+	{12, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{10, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{9, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{13, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{16, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{15, h3IJK{0, 1, 1}, false, [2]int{0, 0}},
+	{15, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{16, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{14, h3IJK{1, 1, 0}, false, [2]int{0, 0}},
+	{13, h3IJK{1, 1, 0}, false, [2]int{0, 0}},
+	{5, h3IJK{2, 0, 0}, true, [2]int{10, 19}},
+	{8, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{14, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{9, h3IJK{1, 0, 1}, false, [2]int{0, 0}},
+	{14, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{17, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{12, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{16, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{17, h3IJK{0, 1, 1}, false, [2]int{0, 0}},
+	{15, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{16, h3IJK{1, 0, 1}, false, [2]int{0, 0}},
+	{9, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{15, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{13, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{8, h3IJK{2, 0, 0}, true, [2]int{13, 17}},
+	{13, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{17, h3IJK{1, 0, 1}, false, [2]int{0, 0}},
+	{19, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{14, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{19, h3IJK{0, 1, 1}, false, [2]int{0, 0}},
+	{17, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{13, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{17, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{16, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{9, h3IJK{2, 0, 0}, true, [2]int{14, 18}},
+	{15, h3IJK{1, 0, 1}, false, [2]int{0, 0}},
+	{15, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{18, h3IJK{0, 1, 1}, false, [2]int{0, 0}},
+	{18, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{19, h3IJK{0, 0, 1}, false, [2]int{0, 0}},
+	{17, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{19, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{18, h3IJK{0, 1, 0}, false, [2]int{0, 0}},
+	{18, h3IJK{1, 0, 1}, false, [2]int{0, 0}},
+	{19, h3IJK{2, 0, 0}, true, [2]int{-1, -1}},
+	{19, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+	{18, h3IJK{0, 0, 0}, false, [2]int{0, 0}},
+	{19, h3IJK{1, 0, 1}, false, [2]int{0, 0}},
+	{18, h3IJK{1, 0, 0}, false, [2]int{0, 0}},
+}
@@ -0,0 +1,328 @@
+package geohashi
+
+import (
+	"bufio"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("H3Cell", func() {
+	It("should encode locations", func() {
+		for _, tc := range []struct {
+			lat, lon float64
+			res      int
+			cell     string
+		}{
+			{37.775938728915946, -122.41795063018799, 9, "8928308280fffff"}, // San Francisco
+			{51.5074, -0.1278, 7, "87195da49ffffff"},                        // London
+			{-33.8688, 151.2093, 12, "8cbe0e35cbad1ff"},                     // Sydney
+			{90, 0, 0, "8001fffffffffff"},                                   // North Pole
+			{-90, 0, 5, "85f29383fffffff"},                                  // South Pole
+			{0, 180, 15, "8f7eb57221a2bb0"},                                 // antimeridian
+			{64.7, 10.5, 1, "81083ffffffffff"},                              // pentagon
+		} {
+			c, err := H3CellFromLatLon(tc.lat, tc.lon, tc.res)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.String()).To(Equal(tc.cell))
+			Expect(c.Resolution()).To(Equal(tc.res))
+			Expect(c.IsValid()).To(BeTrue())
+		}
+
+		_, err := H3CellFromLatLon(91, 0, 10)
+		Expect(err).To(MatchError(errInvalidCoordinates))
+		_, err = H3CellFromLatLon(0, 0, 16)
+		Expect(err).To(MatchError(errInvalidH3Cell))
+	})
+
+	It("should parse strings", func() {
+		c, err := H3CellFromString("8928308280fffff")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c).To(Equal(H3Cell(0x8928308280fffff)))
+		Expect(H3CellFromString(c.String())).To(Equal(c))
+
+		for _, s := range []string{"", "x", "0", "8928308280ffff7", "892830828ffffff", "8f28308280fffff", "81087ffffffffff"} {
+			_, err := H3CellFromString(s)
+			Expect(err).To(MatchError(errInvalidH3Cell), "string %q", s)
+		}
+	})
+
+	It("should navigate the hierarchy", func() {
+		c, err := H3CellFromLatLon(51.5074, -0.1278, 7)
+		Expect(err).NotTo(HaveOccurred())
+
+		parent := c.Parent()
+		Expect(parent.Resolution()).To(Equal(6))
+		Expect(parent.Children()).To(ContainElement(c))
+
+		children := c.Children()
+		Expect(children).To(HaveLen(7))
+		for _, child := range children {
+			Expect(child.Resolution()).To(Equal(8))
+			Expect(child.Parent()).To(Equal(c))
+		}
+		Expect(sort.SliceIsSorted(children, func(i, j int) bool { return children[i] < children[j] })).To(BeTrue())
+		Expect(c.Contains(children[0].Center())).To(BeTrue())
+
+		base := H3Cell(0x8009fffffffffff)
+		Expect(base.Resolution()).To(Equal(0))
+		Expect(base.IsPentagon()).To(BeTrue())
+		Expect(base.Parent()).To(Equal(H3Cell(0)))
+		Expect(base.Children()).To(Equal([]H3Cell{
+			0x81083ffffffffff, 0x8108bffffffffff, 0x8108fffffffffff,
+			0x81093ffffffffff, 0x81097ffffffffff, 0x8109bffffffffff,
+		}))
+
+		leaf, err := H3CellFromLatLon(51.5074, -0.1278, H3ResolutionMax)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(leaf.Children()).To(BeNil())
+	})
+
+	It("should decode centers and vertices", func() {
+		c := H3Cell(0x8928308280fffff)
+		lat, lon := c.Center()
+		Expect(lat).To(BeNumerically("~", 37.776702349435695, 1e-12))
+		Expect(lon).To(BeNumerically("~", -122.41845932318309, 1e-12))
+
+		vs := c.Vertices()
+		Expect(vs).To(HaveLen(6))
+		Expect(vs[0].Lat).To(BeNumerically("~", 37.775197782893386, 1e-12))
+		Expect(vs[0].Lon).To(BeNumerically("~", -122.41719971841658, 1e-12))
+
+		// class III pentagons cross an icosahedron edge on every side
+		pent := H3Cell(0x81083ffffffffff)
+		lat, lon = pent.Center()
+		Expect(lat).To(BeNumerically("~", 64.70000012793489, 1e-12))
+		Expect(lon).To(BeNumerically("~", 10.536199075467643, 1e-12))
+
+		vs = pent.Vertices()
+		Expect(vs).To(HaveLen(10))
+		Expect(vs[0].Lat).To(BeNumerically("~", 63.3270613280184, 1e-12))
+		Expect(vs[0].Lon).To(BeNumerically("~", 4.012620898449968, 1e-12))
+
+		// class III hexagons may cross one
+		Expect(H3Cell(0x83f004fffffffff).Vertices()).To(HaveLen(7))
+	})
+
+	It("should contain its locations", func() {
+		rnd := rand.New(rand.NewSource(1))
+		for n := 0; n < 1000; n++ {
+			lat, lon := rnd.Float64()*178-89, rnd.Float64()*358-179
+			res := rnd.Intn(H3ResolutionMax + 1)
+
+			c, err := H3CellFromLatLon(lat, lon, res)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Contains(lat, lon)).To(BeTrue(), "%v, %v @ %d", lat, lon, res)
+			Expect(c.Bounds().Contains(lat, lon)).To(BeTrue(), "%v, %v @ %d", lat, lon, res)
+
+			clat, clon := c.Center()
+			Expect(c.Contains(clat, clon)).To(BeTrue())
+		}
+	})
+
+	It("should calculate bounds", func() {
+		north := H3Cell(0x8001fffffffffff)
+		Expect(north.Bounds()).To(Equal(Area{MinLat: north.Bounds().MinLat, MaxLat: 90, MinLon: LonMin, MaxLon: LonMax}))
+
+		// cells crossing the antimeridian span all longitudes
+		c, err := H3CellFromLatLon(0, 180, 3)
+		Expect(err).NotTo(HaveOccurred())
+		b := c.Bounds()
+		Expect([]float64{b.MinLon, b.MaxLon}).To(Equal([]float64{LonMin, LonMax}))
+
+		c, err = H3CellFromLatLon(51.5074, -0.1278, 7)
+		Expect(err).NotTo(HaveOccurred())
+		b = c.Bounds()
+		for _, v := range c.Vertices() {
+			Expect(v.Lat).To(BeNumerically(">=", b.MinLat-1e-9))
+			Expect(v.Lat).To(BeNumerically("<=", b.MaxLat+1e-9))
+			Expect(v.Lon).To(BeNumerically(">=", b.MinLon-1e-9))
+			Expect(v.Lon).To(BeNumerically("<=", b.MaxLon+1e-9))
+		}
+	})
+
+	It("should relate areas", func() {
+		c, err := H3CellFromLatLon(51.5074, -0.1278, 7)
+		Expect(err).NotTo(HaveOccurred())
+		b := c.Bounds()
+		lat, lon := c.Center()
+
+		Expect(c.Relate(Area{MinLat: lat - 1e-3, MaxLat: lat + 1e-3, MinLon: lon - 1e-3, MaxLon: lon + 1e-3})).To(Equal(Inside))
+		Expect(c.Relate(Area{MinLat: b.MinLat - 1, MaxLat: b.MaxLat + 1, MinLon: b.MinLon - 1, MaxLon: b.MaxLon + 1})).To(Equal(Boundary))
+		Expect(c.Relate(Area{MinLat: lat, MaxLat: b.MaxLat + 1, MinLon: lon - 1e-3, MaxLon: lon + 1e-3})).To(Equal(Boundary))
+		Expect(c.Relate(Area{MinLat: b.MaxLat + 1, MaxLat: b.MaxLat + 2, MinLon: lon, MaxLon: lon + 1})).To(Equal(Outside))
+
+		// the corners of the bounding box are outside of the cell
+		Expect(c.Relate(Area{MinLat: b.MaxLat - 1e-6, MaxLat: b.MaxLat, MinLon: b.MaxLon - 1e-6, MaxLon: b.MaxLon})).To(Equal(Outside))
+	})
+
+	It("should be covered by hashes", func() {
+		c, err := H3CellFromLatLon(51.5074, -0.1278, 7)
+		Expect(err).NotTo(HaveOccurred())
+
+		cover := CoverFence(c, 20)
+		Expect(cover.Interior).NotTo(BeEmpty())
+		Expect(cover.Boundary).NotTo(BeEmpty())
+		for _, h := range cover.Interior {
+			a := h.Decode()
+			Expect(c.Contains(a.MinLat, a.MinLon)).To(BeTrue())
+			Expect(c.Contains(a.MaxLat, a.MaxLon)).To(BeTrue())
+		}
+
+		// every location within the cell is covered
+		hashes := append(cover.Interior, cover.Boundary...)
+		b := c.Bounds()
+		rnd := rand.New(rand.NewSource(1))
+		for n := 0; n < 1000; n++ {
+			lat := b.MinLat + rnd.Float64()*(b.MaxLat-b.MinLat)
+			lon := b.MinLon + rnd.Float64()*(b.MaxLon-b.MinLon)
+			if !c.Contains(lat, lon) {
+				continue
+			}
+
+			covered := false
+			for _, h := range hashes {
+				if h.Decode().Contains(lat, lon) {
+					covered = true
+					break
+				}
+			}
+			Expect(covered).To(BeTrue(), "%v, %v", lat, lon)
+		}
+	})
+
+	It("should match the reference implementation", func() {
+		f, err := os.Open("testdata/h3.txt")
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+
+		parseFloat := func(s string) float64 {
+			v, err := strconv.ParseFloat(s, 64)
+			Expect(err).NotTo(HaveOccurred())
+			return v
+		}
+		parseCell := func(s string) H3Cell {
+			if s == "0" {
+				return 0
+			}
+			c, err := H3CellFromString(s)
+			Expect(err).NotTo(HaveOccurred())
+			return c
+		}
+
+		// compare distances in meters, longitudes are ill-conditioned near the
+		// poles, where rounding errors also grow to fractions of a millimeter
+		expectNear := func(lat, lon float64, exp []string, c H3Cell) {
+			expLat, expLon := parseFloat(exp[0]), parseFloat(exp[1])
+			tolerance := 1e-6
+			if math.Abs(expLat) > 89.9 {
+				tolerance = 1e-3
+			}
+			ExpectWithOffset(1, Distance(lat, lon, expLat, expLon)).To(BeNumerically("<", tolerance), "cell %s", c)
+		}
+
+		cells, areas := 0, 0
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+				continue
+			}
+
+			switch fields[0] {
+			case "cell":
+				lat, lon := parseFloat(fields[1]), parseFloat(fields[2])
+				res, err := strconv.Atoi(fields[3])
+				Expect(err).NotTo(HaveOccurred())
+				exp := parseCell(fields[4])
+
+				c, err := H3CellFromLatLon(lat, lon, res)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(c).To(Equal(exp), "%v, %v @ %d", lat, lon, res)
+				Expect(c.Contains(lat, lon)).To(BeTrue(), "%v, %v @ %d", lat, lon, res)
+				Expect(c.Parent()).To(Equal(parseCell(fields[7])), "cell %s", c)
+
+				clat, clon := c.Center()
+				expectNear(clat, clon, fields[5:7], c)
+
+				vs := c.Vertices()
+				Expect(vs).To(HaveLen((len(fields)-8)/2), "cell %s", c)
+				for i, v := range vs {
+					expectNear(v.Lat, v.Lon, fields[8+2*i:10+2*i], c)
+				}
+				cells++
+			case "area":
+				a := Area{MinLat: parseFloat(fields[1]), MaxLat: parseFloat(fields[2]), MinLon: parseFloat(fields[3]), MaxLon: parseFloat(fields[4])}
+				res, err := strconv.Atoi(fields[5])
+				Expect(err).NotTo(HaveOccurred())
+
+				var exp []H3Cell
+				for _, s := range fields[6:] {
+					exp = append(exp, parseCell(s))
+				}
+				Expect(a.H3Cells(res)).To(Equal(exp), "%+v @ %d", a, res)
+				areas++
+			}
+		}
+		Expect(scanner.Err()).NotTo(HaveOccurred())
+		Expect(cells).To(BeNumerically(">", 500))
+		Expect(areas).To(BeNumerically(">", 10))
+	})
+
+	It("should cover areas", func() {
+		// same as the overlapping polyfill of the reference implementation
+		Expect(Area{MinLat: 51.4, MaxLat: 51.6, MinLon: -0.3, MaxLon: 0.1}.H3Cells(7)).To(HaveLen(162))
+		Expect(Area{MinLat: 60, MaxLat: 70, MinLon: 170, MaxLon: 180}.H3Cells(3)).To(HaveLen(57))
+		Expect(Area{MinLat: -10, MaxLat: 10, MinLon: -10, MaxLon: 10}.H3Cells(2)).To(HaveLen(92))
+
+		Expect(Area{MinLat: -90, MaxLat: 90, MinLon: -180, MaxLon: 180}.H3Cells(0)).To(HaveLen(h3NumBaseCells))
+		Expect(Area{MinLat: 1, MaxLat: 0}.H3Cells(10)).To(BeNil())
+	})
+
+	It("should cover hashes", func() {
+		h := EncodeWithPrecision(40.7128, -74.006, 16)
+		cells := h.H3Cells(9)
+		Expect(cells).NotTo(BeEmpty())
+		Expect(sort.SliceIsSorted(cells, func(i, j int) bool { return cells[i] < cells[j] })).To(BeTrue())
+
+		a := h.Decode()
+		for _, c := range cells {
+			Expect(c.Resolution()).To(Equal(9))
+			Expect(c.Relate(a)).NotTo(Equal(Outside))
+		}
+
+		// every location within the hash is covered
+		rnd := rand.New(rand.NewSource(1))
+		for n := 0; n < 1000; n++ {
+			lat := a.MinLat + rnd.Float64()*(a.MaxLat-a.MinLat)
+			lon := a.MinLon + rnd.Float64()*(a.MaxLon-a.MinLon)
+
+			c, err := H3CellFromLatLon(lat, lon, 9)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cells).To(ContainElement(c), "%v, %v", lat, lon)
+		}
+
+		Expect(h.H3Cells(-1)).To(BeNil())
+	})
+
+	It("should limit covers", func() {
+		h := EncodeWithPrecision(40.7128, -74.006, 16)
+		cells, err := h.Decode().H3CellsLimit(9, 100)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cells).To(Equal(h.H3Cells(9)))
+
+		_, err = h.Decode().H3CellsLimit(9, len(cells)-1)
+		Expect(err).To(Equal(errTooManyCells))
+		_, err = h.Decode().H3CellsLimit(H3ResolutionMax+1, 16)
+		Expect(err).To(Equal(errInvalidH3Cell))
+		_, err = Area{MinLat: 1, MaxLat: 0}.H3CellsLimit(10, 16)
+		Expect(err).To(Equal(errInvalidArea))
+	})
+})
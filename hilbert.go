@@ -0,0 +1,205 @@
+package geohashi
+
+// HilbertHash is a variant of Hash which orders cells along a Hilbert curve
+// instead of a Z-order curve. It uses the same grid and precision scheme, a
+// HilbertHash identifies the same cell as the Hash with the same precision
+// and grid position, only the numeric order of cells differs.
+//
+// Unlike the Z-order curve, consecutive cells along a Hilbert curve are
+// always adjacent, which keeps range queries over bounding boxes split into
+// fewer disjoint ranges.
+type HilbertHash uint64
+
+// EncodeHilbert converts a lat/lon to a Hilbert hash with maximum precision.
+func EncodeHilbert(lat, lon float64) HilbertHash {
+	return EncodeHilbertWithPrecision(lat, lon, PrecisionMax)
+}
+
+// EncodeHilbertWithPrecision converts a lat/lon to a Hilbert hash.
+func EncodeHilbertWithPrecision(lat, lon float64, prec uint8) HilbertHash {
+	return EncodeWithPrecision(lat, lon, prec).Hilbert()
+}
+
+// Hilbert converts the hash into the Hilbert hash of the same cell.
+func (h Hash) Hilbert() HilbertHash {
+	prec := h.Precision()
+	if prec < PrecisionMin || prec > PrecisionMax {
+		return 0
+	}
+
+	x, y := deinterleave64(h.base())
+	return newHilbertHash(hilbertIndex(x, y, prec), prec)
+}
+
+func newHilbertHash(base uint64, prec uint8) HilbertHash {
+	return HilbertHash(newHash(base, prec))
+}
+
+// Precision returns the prec level
+func (h HilbertHash) Precision() uint8 { return uint8(h >> 52) }
+
+func (h HilbertHash) base() uint64 { return uint64(h & s8) }
+
+// Hash converts the Hilbert hash into the (Z-order) hash of the same cell.
+func (h HilbertHash) Hash() Hash {
+	x, y := hilbertPosition(h.base(), h.Precision())
+	return newHash(interleave64(x, y), h.Precision())
+}
+
+// Decode decodes a Hilbert hash into an area
+func (h HilbertHash) Decode() Area { return h.Hash().Decode() }
+
+// Parent zooms out, returning the parent hash, lowering the precision. This
+// function may return HilbertHash(0) if unable to zoom out further
+func (h HilbertHash) Parent() HilbertHash {
+	prec := h.Precision()
+	if prec <= PrecisionMin {
+		return 0
+	}
+	return newHilbertHash(h.base()>>2, prec-1)
+}
+
+// Children zooms in, returning four child hashes, in curve order. This
+// function may return nil if unable to zoom in further
+func (h HilbertHash) Children() []HilbertHash {
+	prec := h.Precision()
+	if prec >= PrecisionMax {
+		return nil
+	}
+
+	child := newHilbertHash(h.base()<<2, prec+1)
+	return []HilbertHash{child, child | 1, child | 2, child | 3}
+}
+
+// HilbertRange is a half-open range of Hilbert hashes with maximum precision.
+type HilbertRange struct{ Min, Max HilbertHash }
+
+// HilbertRanges returns sorted ranges of maximum precision Hilbert hashes
+// which cover the area with cells of the given precision. The ranges are
+// collected by traversing the curve's quadtree, cells within the area are
+// emitted as a whole, cells on its boundary are refined up to the given
+// precision. Adjacent ranges are merged. It returns nil if the precision or
+// area are invalid or if the area requires more than MaxCoverCells ranges,
+// use HilbertRangesLimit to choose a different limit.
+func (a Area) HilbertRanges(prec uint8) []HilbertRange {
+	res, _ := a.HilbertRangesLimit(prec, MaxCoverCells)
+	return res
+}
+
+// HilbertRangesLimit is like HilbertRanges, but returns an error if the area
+// requires more than maxRanges ranges.
+func (a Area) HilbertRangesLimit(prec uint8, maxRanges int) ([]HilbertRange, error) {
+	if prec < PrecisionMin || prec > PrecisionMax {
+		return nil, errInvalidPrecision
+	}
+	if a.MinLat > a.MaxLat || a.MinLon > a.MaxLon {
+		return nil, errInvalidArea
+	}
+
+	x0, x1, y0, y1 := a.gridBounds(prec)
+	q := hilbertQuery{prec: prec, min: [2]uint64{x0, y0}, max: [2]uint64{x1, y1}, limit: maxRanges}
+	if !q.visit(0, 0) {
+		return nil, errTooManyCells
+	}
+	return q.res, nil
+}
+
+// Contains returns true if the range contains the hash, which must have
+// maximum precision.
+func (r HilbertRange) Contains(h HilbertHash) bool {
+	return r.Min <= h && h < r.Max
+}
+
+// hilbertQuery collects the ranges of Hilbert hashes within a box of x and y
+// grid indices at a given precision.
+type hilbertQuery struct {
+	prec     uint8
+	min, max [2]uint64
+	limit    int
+	res      []HilbertRange
+}
+
+// visit visits the cell at position d along the curve of the given level,
+// i.e. precision, starting at the root, which contains everything. Children
+// are visited in curve order, so ranges are emitted in ascending order. It
+// returns false once the results exceed the limit.
+func (q *hilbertQuery) visit(d uint64, level uint8) bool {
+	shift := q.prec - level
+	x, y := hilbertPosition(d, level)
+
+	inside := true
+	for i, n := range [2]uint64{x, y} {
+		lo, hi := n<<shift, (n+1)<<shift-1
+		if hi < q.min[i] || lo > q.max[i] {
+			return true
+		}
+		inside = inside && q.min[i] <= lo && hi <= q.max[i]
+	}
+
+	if !inside && level < q.prec {
+		for i := uint64(0); i < 4; i++ {
+			if !q.visit(d<<2|i, level+1) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// the max of the last cell overflows into the precision bits, add rather
+	// than OR to sort it after all hashes with maximum precision
+	span := 2 * (PrecisionMax - level)
+	r := HilbertRange{
+		Min: newHilbertHash(d<<span, PrecisionMax),
+		Max: newHilbertHash(0, PrecisionMax) + HilbertHash((d+1)<<span),
+	}
+	if n := len(q.res); n != 0 && q.res[n-1].Max == r.Min {
+		q.res[n-1].Max = r.Max
+		return true
+	}
+	q.res = append(q.res, r)
+	return len(q.res) <= q.limit
+}
+
+// hilbertIndex returns the position of grid cell (x, y) along the Hilbert
+// curve of the given order.
+func hilbertIndex(x, y uint64, prec uint8) uint64 {
+	var d uint64
+	for s := uint64(1) << (prec - 1); s > 0; s >>= 1 {
+		var rx, ry uint64
+		if x&s != 0 {
+			rx = 1
+		}
+		if y&s != 0 {
+			ry = 1
+		}
+		d += s * s * (3*rx ^ ry)
+		x, y = hilbertRotate(s, x, y, rx, ry)
+	}
+	return d
+}
+
+// hilbertPosition returns the grid cell (x, y) at position d along the
+// Hilbert curve of the given order.
+func hilbertPosition(d uint64, prec uint8) (x, y uint64) {
+	for s := uint64(1); s < uint64(1)<<prec; s <<= 1 {
+		rx := 1 & (d >> 1)
+		ry := 1 & (d ^ rx)
+		x, y = hilbertRotate(s, x, y, rx, ry)
+		x += s * rx
+		y += s * ry
+		d >>= 2
+	}
+	return
+}
+
+// hilbertRotate rotates and flips a quadrant of size s appropriately.
+func hilbertRotate(s, x, y, rx, ry uint64) (uint64, uint64) {
+	if ry == 0 {
+		if rx == 1 {
+			x = s - 1 - x&(s-1)
+			y = s - 1 - y&(s-1)
+		}
+		return y, x
+	}
+	return x, y
+}
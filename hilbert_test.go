@@ -0,0 +1,220 @@
+package geohashi
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HilbertHash", func() {
+	const lat, lon = 51.524632318, -0.0841140747
+
+	It("should encode", func() {
+		Expect(EncodeHilbertWithPrecision(lat, lon, 0)).To(Equal(HilbertHash(0)))
+		Expect(EncodeHilbertWithPrecision(lat, lon, 27)).To(Equal(HilbertHash(0)))
+
+		// level 1 quadrants: SW, SE, NE, NW
+		Expect(EncodeHilbertWithPrecision(-10, -10, 1)).To(Equal(HilbertHash(0x0010000000000000)))
+		Expect(EncodeHilbertWithPrecision(-10, 10, 1)).To(Equal(HilbertHash(0x0010000000000001)))
+		Expect(EncodeHilbertWithPrecision(10, 10, 1)).To(Equal(HilbertHash(0x0010000000000002)))
+		Expect(EncodeHilbertWithPrecision(10, -10, 1)).To(Equal(HilbertHash(0x0010000000000003)))
+
+		h := EncodeHilbert(lat, lon)
+		Expect(h.Precision()).To(Equal(uint8(PrecisionMax)))
+		Expect(h.Decode()).To(Equal(Encode(lat, lon).Decode()))
+	})
+
+	It("should convert to and from Z-order hashes", func() {
+		rnd := rand.New(rand.NewSource(1))
+		for n := 0; n < 1000; n++ {
+			lat, lon := rnd.Float64()*170-85, rnd.Float64()*360-180
+			prec := uint8(rnd.Intn(PrecisionMax) + 1)
+
+			h := EncodeHilbertWithPrecision(lat, lon, prec)
+			Expect(h.Precision()).To(Equal(prec))
+			Expect(h.Hash()).To(Equal(EncodeWithPrecision(lat, lon, prec)))
+			Expect(h.Hash().Hilbert()).To(Equal(h))
+			Expect(h.Decode().Contains(lat, lon)).To(BeTrue())
+		}
+		Expect(Hash(0).Hilbert()).To(Equal(HilbertHash(0)))
+	})
+
+	It("should zoom out", func() {
+		for prec := uint8(PrecisionMax); prec > PrecisionMin; prec-- {
+			h := EncodeHilbertWithPrecision(lat, lon, prec)
+			Expect(h.Parent()).To(Equal(EncodeHilbertWithPrecision(lat, lon, prec-1)))
+		}
+		Expect(EncodeHilbertWithPrecision(lat, lon, 1).Parent()).To(Equal(HilbertHash(0)))
+	})
+
+	It("should zoom in", func() {
+		h := EncodeHilbertWithPrecision(lat, lon, 12)
+		area := h.Decode()
+
+		children := h.Children()
+		Expect(children).To(HaveLen(4))
+		for _, child := range children {
+			Expect(child.Precision()).To(Equal(uint8(13)))
+			Expect(child.Parent()).To(Equal(h))
+
+			lat, lon := child.Decode().Center()
+			Expect(area.Contains(lat, lon)).To(BeTrue())
+		}
+		Expect(EncodeHilbert(lat, lon).Children()).To(BeNil())
+	})
+
+	It("should visit adjacent cells in order", func() {
+		const prec = 6
+		for d := uint64(1); d < 1<<(2*prec); d++ {
+			x0, y0 := deinterleave64(newHilbertHash(d-1, prec).Hash().base())
+			x1, y1 := deinterleave64(newHilbertHash(d, prec).Hash().base())
+			Expect(absDiff(x0, x1)+absDiff(y0, y1)).To(Equal(uint64(1)), "position %d", d)
+		}
+	})
+
+	It("should calculate ranges", func() {
+		area := Area{MinLat: 51.50, MaxLat: 51.55, MinLon: -0.15, MaxLon: -0.05}
+		ranges := area.HilbertRanges(16)
+		Expect(ranges).NotTo(BeEmpty())
+		Expect(len(ranges)).To(BeNumerically("<", len(area.coverRanges(16))))
+
+		for i := 1; i < len(ranges); i++ {
+			Expect(ranges[i-1].Max).To(BeNumerically("<", ranges[i].Min))
+		}
+
+		// every location within the area is within a range
+		rnd := rand.New(rand.NewSource(1))
+		for n := 0; n < 1000; n++ {
+			h := EncodeHilbert(
+				area.MinLat+rnd.Float64()*(area.MaxLat-area.MinLat),
+				area.MinLon+rnd.Float64()*(area.MaxLon-area.MinLon),
+			)
+
+			found := false
+			for _, r := range ranges {
+				if r.Contains(h) {
+					found = true
+					break
+				}
+			}
+			Expect(found).To(BeTrue())
+		}
+
+		Expect(area.HilbertRanges(0)).To(BeNil())
+		Expect(area.HilbertRanges(PrecisionMax + 1)).To(BeNil())
+		Expect(area.HilbertRanges(255)).To(BeNil())
+		Expect(Area{MinLat: 1, MaxLat: 0}.HilbertRanges(16)).To(BeNil())
+	})
+
+	It("should limit ranges", func() {
+		area := Area{MinLat: 51.50, MaxLat: 51.55, MinLon: -0.15, MaxLon: -0.05}
+		ranges, err := area.HilbertRangesLimit(16, MaxCoverCells)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ranges).To(Equal(area.HilbertRanges(16)))
+
+		res, err := area.HilbertRangesLimit(16, len(ranges))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(Equal(ranges))
+		_, err = area.HilbertRangesLimit(16, len(ranges)-1)
+		Expect(err).To(Equal(errTooManyCells))
+
+		_, err = area.HilbertRangesLimit(PrecisionMax+1, 16)
+		Expect(err).To(Equal(errInvalidPrecision))
+		_, err = Area{MinLat: 1, MaxLat: 0}.HilbertRangesLimit(16, 16)
+		Expect(err).To(Equal(errInvalidArea))
+
+		// the boundary of large areas requires too many ranges at high precisions
+		Expect(Area{MinLat: -60.1, MaxLat: 60.3, MinLon: -170.8, MaxLon: 170.1}.HilbertRanges(24)).To(BeNil())
+	})
+
+	It("should match the ranges of covering cells", func() {
+		for _, a := range benchBoxes(20) {
+			var exp []HilbertRange
+			var hashes []HilbertHash
+			for _, c := range a.Cover(12) {
+				hashes = append(hashes, c.Hilbert())
+			}
+			sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+			for _, h := range hashes {
+				r := HilbertRange{Min: newHilbertHash(h.base()<<28, PrecisionMax), Max: newHilbertHash((h.base()+1)<<28, PrecisionMax)}
+				if n := len(exp); n != 0 && exp[n-1].Max == r.Min {
+					exp[n-1].Max = r.Max
+				} else {
+					exp = append(exp, r)
+				}
+			}
+			Expect(a.HilbertRanges(12)).To(Equal(exp))
+		}
+	})
+
+	It("should not enumerate cells within the area", func() {
+		world := Area{MinLat: LatMin, MaxLat: LatMax, MinLon: LonMin, MaxLon: LonMax}
+		Expect(world.HilbertRanges(PrecisionMax)).To(Equal([]HilbertRange{
+			{Min: newHilbertHash(0, PrecisionMax), Max: newHilbertHash(0, PrecisionMax+1)},
+		}))
+
+		// too many cells for Area.Cover
+		area := Area{MinLat: -60, MaxLat: 60, MinLon: -170, MaxLon: 170}
+		Expect(area.Cover(16)).To(BeNil())
+
+		ranges := area.HilbertRanges(16)
+		Expect(ranges).NotTo(BeEmpty())
+		Expect(len(ranges)).To(BeNumerically("<", MaxCoverCells))
+		found := false
+		for _, r := range ranges {
+			found = found || r.Contains(EncodeHilbert(0, 0))
+		}
+		Expect(found).To(BeTrue())
+	})
+
+	It("should require fewer ranges than Z-order", func() {
+		var hilbert, zorder int
+		for _, a := range benchBoxes(100) {
+			hilbert += len(a.HilbertRanges(16))
+			zorder += len(a.coverRanges(16))
+		}
+		Expect(hilbert).To(BeNumerically("<", zorder))
+	})
+})
+
+func absDiff(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// benchBoxes returns random, city-sized bounding boxes.
+func benchBoxes(n int) []Area {
+	rnd := rand.New(rand.NewSource(1))
+	boxes := make([]Area, n)
+	for i := range boxes {
+		lat, lon := rnd.Float64()*120-60, rnd.Float64()*340-170
+		h, w := 0.02+rnd.Float64()*0.1, 0.02+rnd.Float64()*0.15
+		boxes[i] = Area{MinLat: lat, MaxLat: lat + h, MinLon: lon, MaxLon: lon + w}
+	}
+	return boxes
+}
+
+func BenchmarkArea_HilbertRanges(b *testing.B) {
+	boxes := benchBoxes(100)
+	var n int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n += len(boxes[i%len(boxes)].HilbertRanges(16))
+	}
+	b.ReportMetric(float64(n)/float64(b.N), "ranges/op")
+}
+
+func BenchmarkArea_ZOrderRanges(b *testing.B) {
+	boxes := benchBoxes(100)
+	var n int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n += len(boxes[i%len(boxes)].coverRanges(16))
+	}
+	b.ReportMetric(float64(n)/float64(b.N), "ranges/op")
+}
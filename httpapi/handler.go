@@ -0,0 +1,337 @@
+// Package httpapi exposes geohash operations as a JSON HTTP service.
+//
+// Endpoints:
+//
+//	GET  /encode?lat=LAT&lon=LON[&precision=PREC]
+//	GET  /decode?hash=HASH
+//	GET  /neighbors?hash=HASH
+//	GET  /cover?bbox=MINLON,MINLAT,MAXLON,MAXLAT[&precision=PREC]
+//	GET  /cover?lat=LAT&lon=LON&radius=METERS[&precision=PREC]
+//	POST /cover[?precision=PREC] with a GeoJSON body
+//	GET  /precision?lat=LAT&radius=METERS
+//
+// Hashes are encoded as decimal strings. Errors are returned as
+// {"error": "message"} objects.
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bsm/geohashi"
+)
+
+// Options configure the handler.
+type Options struct {
+	// DefaultPrecision is used when no precision is given, default: 16.
+	DefaultPrecision uint8
+	// MaxCells limits the number of cells of a covering, across all
+	// fences of a request, default: 100,000.
+	MaxCells int
+	// MaxBodySize limits the size of request bodies, default: 1MiB.
+	MaxBodySize int64
+}
+
+func (o *Options) norm() *Options {
+	var oo Options
+	if o != nil {
+		oo = *o
+	}
+	if oo.DefaultPrecision < geohashi.PrecisionMin || oo.DefaultPrecision > geohashi.PrecisionMax {
+		oo.DefaultPrecision = 16
+	}
+	if oo.MaxCells <= 0 {
+		oo.MaxCells = 100000
+	}
+	if oo.MaxBodySize <= 0 {
+		oo.MaxBodySize = 1 << 20
+	}
+	return &oo
+}
+
+// NewHandler returns a new handler. Options are optional.
+func NewHandler(o *Options) http.Handler {
+	h := &handler{opt: o.norm()}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /encode", h.encode)
+	mux.HandleFunc("GET /decode", h.decode)
+	mux.HandleFunc("GET /neighbors", h.neighbors)
+	mux.HandleFunc("GET /cover", h.cover)
+	mux.HandleFunc("POST /cover", h.cover)
+	mux.HandleFunc("GET /precision", h.precision)
+	return mux
+}
+
+// Cell is the JSON representation of a hash.
+type Cell struct {
+	Hash      geohashi.Hash `json:"hash"`
+	Precision uint8         `json:"precision"`
+	// BBox is [minLon, minLat, maxLon, maxLat].
+	BBox [4]float64 `json:"bbox"`
+	// Center is [lon, lat].
+	Center [2]float64 `json:"center"`
+}
+
+func newCell(h geohashi.Hash) Cell {
+	a := h.Decode()
+	lat, lon := a.Center()
+	return Cell{
+		Hash:      h,
+		Precision: h.Precision(),
+		BBox:      [4]float64{a.MinLon, a.MinLat, a.MaxLon, a.MaxLat},
+		Center:    [2]float64{lon, lat},
+	}
+}
+
+// Covering is the JSON representation of a covering.
+type Covering struct {
+	Precision uint8           `json:"precision"`
+	Interior  []geohashi.Hash `json:"interior"`
+	Boundary  []geohashi.Hash `json:"boundary"`
+}
+
+// --------------------------------------------------------------------
+
+type handler struct {
+	opt *Options
+}
+
+func (h *handler) encode(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	lat, lon, err := parseCoordinates(q.Get("lat"), q.Get("lon"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	prec, err := h.parsePrecision(q.Get("precision"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newCell(geohashi.EncodeWithPrecision(lat, lon, prec)))
+}
+
+func (h *handler) decode(w http.ResponseWriter, r *http.Request) {
+	hash, err := parseHash(r.URL.Query().Get("hash"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newCell(hash))
+}
+
+func (h *handler) neighbors(w http.ResponseWriter, r *http.Request) {
+	hash, err := parseHash(r.URL.Query().Get("hash"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	hashes := hash.Neighbors()
+	cells := make([]Cell, 0, len(hashes))
+	for _, n := range hashes {
+		cells = append(cells, newCell(n))
+	}
+	writeJSON(w, http.StatusOK, cells)
+}
+
+func (h *handler) cover(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	prec, err := h.parsePrecision(q.Get("precision"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var fences []geohashi.Fence
+	var points []geohashi.Point
+	switch {
+	case r.Method == http.MethodPost:
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.opt.MaxBodySize))
+		if err != nil {
+			writeError(w, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		pts, polys, err := geohashi.ParseGeoJSON(data)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		for _, p := range pts {
+			if !geohashi.ValidCoordinates(p.Lat, p.Lon) {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid coordinates %v,%v", p.Lat, p.Lon))
+				return
+			}
+		}
+		for _, p := range polys {
+			fences = append(fences, p)
+		}
+		points = pts
+	case q.Has("bbox"):
+		a, err := parseBBox(q.Get("bbox"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		fences = append(fences, a)
+	case q.Has("radius"):
+		lat, lon, err := parseCoordinates(q.Get("lat"), q.Get("lon"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		radius, err := parseRadius(q.Get("radius"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		fences = append(fences, geohashi.Circle{Lat: lat, Lon: lon, Radius: radius})
+	default:
+		writeError(w, http.StatusBadRequest, errors.New("either bbox or radius is required"))
+		return
+	}
+
+	// reject obviously expensive requests early, then enforce the limit
+	// across all fences while covering
+	tooMany := fmt.Errorf("precision %d is too high for the area", prec)
+	estimate := float64(len(points))
+	for _, f := range fences {
+		estimate += geohashi.EstimateCells(f, prec)
+	}
+	if estimate > float64(h.opt.MaxCells) {
+		writeError(w, http.StatusUnprocessableEntity, tooMany)
+		return
+	}
+
+	res := Covering{Precision: prec, Interior: []geohashi.Hash{}, Boundary: []geohashi.Hash{}}
+	budget := h.opt.MaxCells - len(points)
+	for _, f := range fences {
+		if budget <= 0 {
+			writeError(w, http.StatusUnprocessableEntity, tooMany)
+			return
+		}
+		cov, err := geohashi.CoverFenceLimit(f, prec, budget)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, tooMany)
+			return
+		}
+		res.Interior = append(res.Interior, cov.Interior...)
+		res.Boundary = append(res.Boundary, cov.Boundary...)
+		budget -= len(cov.Interior) + len(cov.Boundary)
+	}
+	for _, p := range points {
+		res.Boundary = append(res.Boundary, geohashi.EncodeWithPrecision(p.Lat, p.Lon, prec))
+	}
+	res.Interior = sortHashes(res.Interior)
+	res.Boundary = sortHashes(res.Boundary)
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *handler) precision(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	lat, err := parseFloat("lat", q.Get("lat"), -90, 90)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	radius, err := parseRadius(q.Get("radius"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]uint8{"precision": geohashi.RadiusPrecision(lat, radius)})
+}
+
+func (h *handler) parsePrecision(s string) (uint8, error) {
+	if s == "" {
+		return h.opt.DefaultPrecision, nil
+	}
+
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || v < geohashi.PrecisionMin || v > geohashi.PrecisionMax {
+		return 0, fmt.Errorf("precision must be between %d and %d", geohashi.PrecisionMin, geohashi.PrecisionMax)
+	}
+	return uint8(v), nil
+}
+
+// --------------------------------------------------------------------
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func parseHash(s string) (geohashi.Hash, error) {
+	var h geohashi.Hash
+	if err := h.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid hash %q", s)
+	}
+	return h, nil
+}
+
+func parseCoordinates(lat, lon string) (float64, float64, error) {
+	φ, err := parseFloat("lat", lat, geohashi.LatMin, geohashi.LatMax)
+	if err != nil {
+		return 0, 0, err
+	}
+	λ, err := parseFloat("lon", lon, geohashi.LonMin, geohashi.LonMax)
+	if err != nil {
+		return 0, 0, err
+	}
+	return φ, λ, nil
+}
+
+func parseRadius(s string) (float64, error) {
+	return parseFloat("radius", s, 0, math.MaxFloat64)
+}
+
+func parseFloat(name, s string, min, max float64) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || !(min <= v && v <= max) {
+		return 0, fmt.Errorf("invalid %s %q", name, s)
+	}
+	return v, nil
+}
+
+func parseBBox(s string) (geohashi.Area, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return geohashi.Area{}, fmt.Errorf("invalid bbox %q", s)
+	}
+
+	var vv [4]float64
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || math.IsNaN(v) {
+			return geohashi.Area{}, fmt.Errorf("invalid bbox %q", s)
+		}
+		vv[i] = v
+	}
+
+	a := geohashi.Area{MinLon: vv[0], MinLat: vv[1], MaxLon: vv[2], MaxLat: vv[3]}
+	if a.MinLat > a.MaxLat || a.MinLon > a.MaxLon {
+		return geohashi.Area{}, fmt.Errorf("invalid bbox %q", s)
+	}
+	return a, nil
+}
+
+func sortHashes(hashes []geohashi.Hash) []geohashi.Hash {
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+	return hashes
+}
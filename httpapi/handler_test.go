@@ -0,0 +1,201 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bsm/geohashi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(NewHandler(&Options{MaxCells: 1000}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	do := func(method, path, body string) (int, string) {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp.StatusCode, string(data)
+	}
+
+	It("should encode", func() {
+		status, body := do("GET", "/encode?lat=51.52463&lon=-0.08411&precision=10", "")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(MatchJSON(`{
+			"hash": "45035996274208702",
+			"precision": 10,
+			"bbox": [-0.3515625, 51.49580062851564, 0, 51.66191611441408],
+			"center": [-0.17578125, 51.57885837146486]
+		}`))
+
+		status, body = do("GET", "/encode?lat=51.52463&lon=-0.08411", "")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(ContainSubstring(`"precision":16`))
+
+		status, body = do("GET", "/encode?lat=91&lon=0", "")
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(body).To(MatchJSON(`{"error": "invalid lat \"91\""}`))
+
+		status, body = do("GET", "/encode?lat=NaN&lon=0", "")
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(body).To(MatchJSON(`{"error": "invalid lat \"NaN\""}`))
+
+		status, body = do("GET", "/encode?lat=1&lon=1&precision=27", "")
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(body).To(MatchJSON(`{"error": "precision must be between 1 and 26"}`))
+	})
+
+	It("should decode", func() {
+		status, body := do("GET", "/decode?hash=4503599627370499", "")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(MatchJSON(`{
+			"hash": "4503599627370499",
+			"precision": 1,
+			"bbox": [0, 0, 180, 85.05112878],
+			"center": [90, 42.52556439]
+		}`))
+
+		status, body = do("GET", "/decode?hash=x", "")
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(body).To(MatchJSON(`{"error": "invalid hash \"x\""}`))
+	})
+
+	It("should return neighbors", func() {
+		status, body := do("GET", "/neighbors?hash=45035996274208702", "")
+		Expect(status).To(Equal(http.StatusOK))
+
+		var cells []Cell
+		Expect(json.Unmarshal([]byte(body), &cells)).To(Succeed())
+		Expect(cells).To(HaveLen(8))
+		Expect(cells[0].Hash).To(Equal(geohashi.Hash(45035996274208702).MoveY(1)))
+	})
+
+	It("should cover bboxes", func() {
+		status, body := do("GET", "/cover?bbox=0,0,180,85&precision=1", "")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(MatchJSON(`{"precision": 1, "interior": [], "boundary": ["4503599627370499"]}`))
+
+		status, body = do("GET", "/cover?bbox=-180,-85.05112878,180,85.05112878&precision=2", "")
+		Expect(status).To(Equal(http.StatusOK))
+
+		var cov Covering
+		Expect(json.Unmarshal([]byte(body), &cov)).To(Succeed())
+		Expect(len(cov.Interior) + len(cov.Boundary)).To(BeNumerically(">=", 4))
+
+		status, _ = do("GET", "/cover?bbox=1,1,0,0", "")
+		Expect(status).To(Equal(http.StatusBadRequest))
+
+		status, body = do("GET", "/cover?bbox=-180,-80,180,80&precision=20", "")
+		Expect(status).To(Equal(http.StatusUnprocessableEntity))
+		Expect(body).To(MatchJSON(`{"error": "precision 20 is too high for the area"}`))
+	})
+
+	It("should cover radius", func() {
+		status, body := do("GET", "/cover?lat=51.52463&lon=-0.08411&radius=1000&precision=16", "")
+		Expect(status).To(Equal(http.StatusOK))
+
+		var cov Covering
+		Expect(json.Unmarshal([]byte(body), &cov)).To(Succeed())
+		Expect(cov.Precision).To(Equal(uint8(16)))
+		Expect(cov.Boundary).NotTo(BeEmpty())
+		for _, h := range cov.Boundary {
+			Expect(h.Precision()).To(Equal(uint8(16)))
+		}
+
+		status, _ = do("GET", "/cover?lat=51.52463&lon=-0.08411&radius=-1", "")
+		Expect(status).To(Equal(http.StatusBadRequest))
+		status, _ = do("GET", "/cover?lat=51.52463&lon=-0.08411&radius=NaN", "")
+		Expect(status).To(Equal(http.StatusBadRequest))
+		status, _ = do("GET", "/cover?bbox=0,0,NaN,1", "")
+		Expect(status).To(Equal(http.StatusBadRequest))
+
+		status, _ = do("GET", "/cover", "")
+		Expect(status).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should cover GeoJSON", func() {
+		status, body := do("POST", "/cover?precision=4", `{"type": "Polygon", "coordinates": [[[0, 0], [180, 0], [180, 85], [0, 0]]]}`)
+		Expect(status).To(Equal(http.StatusOK))
+
+		var cov Covering
+		Expect(json.Unmarshal([]byte(body), &cov)).To(Succeed())
+		Expect(cov.Interior).NotTo(BeEmpty())
+		Expect(cov.Boundary).NotTo(BeEmpty())
+
+		status, body = do("POST", "/cover?precision=10", `{"type": "Point", "coordinates": [-0.08411, 51.52463]}`)
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(MatchJSON(`{"precision": 10, "interior": [], "boundary": ["45035996274208702"]}`))
+
+		status, _ = do("POST", "/cover?precision=26", `{"type": "Point", "coordinates": [0, 89]}`)
+		Expect(status).To(Equal(http.StatusBadRequest))
+
+		// many polygons, each within the limit
+		squares := make([]string, 0, 20)
+		for i := 0; i < 20; i++ {
+			squares = append(squares, fmt.Sprintf("[[[%[1]d, 0], [%[2]d, 0], [%[2]d, 1], [%[1]d, 1], [%[1]d, 0]]]", 2*i, 2*i+1))
+		}
+		status, _ = do("POST", "/cover?precision=14", `{"type": "MultiPolygon", "coordinates": [`+strings.Join(squares, ",")+`]}`)
+		Expect(status).To(Equal(http.StatusUnprocessableEntity))
+		status, _ = do("POST", "/cover?precision=14", `{"type": "MultiPolygon", "coordinates": [`+squares[0]+`]}`)
+		Expect(status).To(Equal(http.StatusOK))
+
+		// a comb, with a small bounding box but a long boundary
+		comb := []string{"[0, 0]"}
+		for i := 0; i < 40; i++ {
+			x := float64(i) / 40
+			comb = append(comb, fmt.Sprintf("[%[1]g, 1], [%[2]g, 1], [%[2]g, 0.1]", x, x+0.0125))
+		}
+		comb = append(comb, "[1, 0]", "[0, 0]")
+		status, _ = do("POST", "/cover?precision=14", `{"type": "Polygon", "coordinates": [[`+strings.Join(comb, ",")+`]]}`)
+		Expect(status).To(Equal(http.StatusUnprocessableEntity))
+
+		status, _ = do("POST", "/cover", `{"type": "LineString", "coordinates": [[0, 0], [1, 1]]}`)
+		Expect(status).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should calculate precisions", func() {
+		status, body := do("GET", "/precision?lat=0&radius=1000", "")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(MatchJSON(`{"precision": 14}`))
+
+		status, _ = do("GET", "/precision?lat=0", "")
+		Expect(status).To(Equal(http.StatusBadRequest))
+		status, _ = do("GET", "/precision?lat=NaN&radius=1000", "")
+		Expect(status).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should reject bad requests", func() {
+		status, _ := do("GET", "/unknown", "")
+		Expect(status).To(Equal(http.StatusNotFound))
+
+		status, _ = do("DELETE", "/encode", "")
+		Expect(status).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
+
+// --------------------------------------------------------------------
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "github.com/bsm/geohashi/httpapi")
+}
@@ -0,0 +1,212 @@
+package geohashi
+
+import (
+	"errors"
+	"math/bits"
+	"math/rand/v2"
+	"sort"
+)
+
+var (
+	errInvalidCoordinates = errors.New("geohashi: invalid coordinates")
+	errNotFound           = errors.New("geohashi: value not found")
+)
+
+// Item is a value with a location.
+type Item[T any] struct {
+	Lat, Lon float64
+	Value    T
+}
+
+// Neighbor is an item with a distance (in meters) to a query point.
+type Neighbor[T any] struct {
+	Item[T]
+	Distance float64
+}
+
+// indexMaxLevel is the maximum number of skip list levels, enough for 4^16
+// items.
+const indexMaxLevel = 16
+
+// indexKey orders items by hash, then by insertion.
+type indexKey struct {
+	hash Hash
+	seq  uint64
+}
+
+func (k indexKey) less(o indexKey) bool {
+	return k.hash < o.hash || (k.hash == o.hash && k.seq < o.seq)
+}
+
+type indexNode[T any] struct {
+	key indexKey
+	Item[T]
+	next []*indexNode[T]
+}
+
+// Index is an in-memory spatial index of values. Values are keyed by their
+// maximum precision hash and stored in a skip list, inserts and deletes take
+// O(log n). Index is not safe for concurrent use.
+type Index[T comparable] struct {
+	head  indexNode[T]
+	level int
+	seq   uint64
+	nodes map[T]*indexNode[T]
+}
+
+// NewIndex inits a new index.
+func NewIndex[T comparable]() *Index[T] {
+	return &Index[T]{
+		head:  indexNode[T]{next: make([]*indexNode[T], indexMaxLevel)},
+		level: 1,
+		nodes: make(map[T]*indexNode[T]),
+	}
+}
+
+// Len returns the number of values in the index.
+func (x *Index[T]) Len() int { return len(x.nodes) }
+
+// Get returns the location of a value.
+func (x *Index[T]) Get(v T) (lat, lon float64, ok bool) {
+	if n, ok := x.nodes[v]; ok {
+		return n.Lat, n.Lon, true
+	}
+	return 0, 0, false
+}
+
+// Insert inserts a value at a location. If the value already exists, it is
+// moved to the new location.
+func (x *Index[T]) Insert(v T, lat, lon float64) error {
+	if !ValidCoordinates(lat, lon) {
+		return errInvalidCoordinates
+	}
+
+	if n, ok := x.nodes[v]; ok {
+		x.remove(n)
+	}
+
+	x.seq++
+	key := indexKey{hash: encode(lat, lon, PrecisionMax), seq: x.seq}
+	level := 1 + bits.TrailingZeros64(rand.Uint64()|1<<(2*indexMaxLevel-2))/2
+	if level > x.level {
+		x.level = level
+	}
+
+	n := &indexNode[T]{key: key, Item: Item[T]{Lat: lat, Lon: lon, Value: v}, next: make([]*indexNode[T], level)}
+	prev := x.seek(key)
+	for i := 0; i < level; i++ {
+		n.next[i] = prev[i].next[i]
+		prev[i].next[i] = n
+	}
+	x.nodes[v] = n
+	return nil
+}
+
+// Move updates the location of an existing value.
+func (x *Index[T]) Move(v T, lat, lon float64) error {
+	if _, ok := x.nodes[v]; !ok {
+		return errNotFound
+	}
+	return x.Insert(v, lat, lon)
+}
+
+// Delete removes a value from the index. It returns true if the value existed.
+func (x *Index[T]) Delete(v T) bool {
+	n, ok := x.nodes[v]
+	if !ok {
+		return false
+	}
+	x.remove(n)
+	return true
+}
+
+// Iterate calls fn for each item in the index, in Z-order. Iteration stops
+// if fn returns false.
+func (x *Index[T]) Iterate(fn func(Item[T]) bool) {
+	for n := x.head.next[0]; n != nil; n = n.next[0] {
+		if !fn(n.Item) {
+			return
+		}
+	}
+}
+
+// Within returns all items within the area, in Z-order.
+func (x *Index[T]) Within(a Area) []Item[T] {
+	var res []Item[T]
+	for _, r := range a.ranges() {
+		x.scan(r, func(e *indexNode[T]) {
+			if a.Contains(e.Lat, e.Lon) {
+				res = append(res, e.Item)
+			}
+		})
+	}
+	return res
+}
+
+// Radius returns all items within radius meters of a location, ordered by
+// distance.
+func (x *Index[T]) Radius(lat, lon, radius float64) []Neighbor[T] {
+	var res []Neighbor[T]
+	for _, a := range radiusAreas(lat, lon, radius) {
+		for _, r := range a.ranges() {
+			x.scan(r, func(e *indexNode[T]) {
+				if dist := Distance(lat, lon, e.Lat, e.Lon); dist <= radius {
+					res = append(res, Neighbor[T]{Item: e.Item, Distance: dist})
+				}
+			})
+		}
+	}
+	sortNeighbors(res)
+	return res
+}
+
+// Nearest returns the k nearest items to a location, ordered by distance.
+func (x *Index[T]) Nearest(lat, lon float64, k int) []Neighbor[T] {
+	if k <= 0 || x.Len() == 0 {
+		return nil
+	}
+	return KNN(lat, lon, k, knnPrecision(x.Len(), k), x.lookup)
+}
+
+// lookup returns all items within a cell.
+func (x *Index[T]) lookup(cell Hash) []Item[T] {
+	var res []Item[T]
+	x.scan(cell.span(), func(e *indexNode[T]) {
+		res = append(res, e.Item)
+	})
+	return res
+}
+
+func (x *Index[T]) remove(n *indexNode[T]) {
+	delete(x.nodes, n.Value)
+
+	prev := x.seek(n.key)
+	for i := range n.next {
+		prev[i].next[i] = n.next[i]
+	}
+}
+
+// seek returns the last node with a key < k at each level.
+func (x *Index[T]) seek(k indexKey) (prev [indexMaxLevel]*indexNode[T]) {
+	n := &x.head
+	for i := x.level - 1; i >= 0; i-- {
+		for n.next[i] != nil && n.next[i].key.less(k) {
+			n = n.next[i]
+		}
+		prev[i] = n
+	}
+	for i := x.level; i < indexMaxLevel; i++ {
+		prev[i] = &x.head
+	}
+	return
+}
+
+func (x *Index[T]) scan(r hashRange, fn func(*indexNode[T])) {
+	for n := x.seek(indexKey{hash: r.min})[0].next[0]; n != nil && n.key.hash < r.max; n = n.next[0] {
+		fn(n)
+	}
+}
+
+func sortNeighbors[T any](nn []Neighbor[T]) {
+	sort.SliceStable(nn, func(i, j int) bool { return nn[i].Distance < nn[j].Distance })
+}
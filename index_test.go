@@ -0,0 +1,144 @@
+package geohashi
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Index", func() {
+	var subject *Index[string]
+
+	values := func(ii []Item[string]) []string {
+		res := make([]string, 0, len(ii))
+		for _, e := range ii {
+			res = append(res, e.Value)
+		}
+		return res
+	}
+
+	neighbors := func(nn []Neighbor[string]) []string {
+		res := make([]string, 0, len(nn))
+		for _, n := range nn {
+			res = append(res, n.Value)
+		}
+		return res
+	}
+
+	BeforeEach(func() {
+		subject = NewIndex[string]()
+		Expect(subject.Insert("liverpool-st", 51.5178, -0.0823)).To(Succeed())
+		Expect(subject.Insert("old-street", 51.5256, -0.0875)).To(Succeed())
+		Expect(subject.Insert("kings-cross", 51.5308, -0.1238)).To(Succeed())
+		Expect(subject.Insert("paris", 48.8584, 2.2945)).To(Succeed())
+		Expect(subject.Insert("fiji", -17.7134, 178.065)).To(Succeed())
+		Expect(subject.Insert("samoa", -13.759, -172.1046)).To(Succeed())
+	})
+
+	It("should insert/delete", func() {
+		Expect(subject.Len()).To(Equal(6))
+		Expect(subject.Insert("x", 89, 0)).To(MatchError(errInvalidCoordinates))
+		Expect(subject.Insert("x", 0, 181)).To(MatchError(errInvalidCoordinates))
+
+		Expect(subject.Insert("kings-cross", 51.5308, -0.1238)).To(Succeed())
+		Expect(subject.Len()).To(Equal(6))
+
+		Expect(subject.Delete("paris")).To(BeTrue())
+		Expect(subject.Delete("paris")).To(BeFalse())
+		Expect(subject.Len()).To(Equal(5))
+
+		_, _, ok := subject.Get("paris")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should move", func() {
+		Expect(subject.Move("paris", 51.5014, -0.1419)).To(Succeed())
+		Expect(subject.Move("berlin", 52.52, 13.405)).To(MatchError(errNotFound))
+		Expect(subject.Len()).To(Equal(6))
+
+		lat, lon, ok := subject.Get("paris")
+		Expect(ok).To(BeTrue())
+		Expect(lat).To(Equal(51.5014))
+		Expect(lon).To(Equal(-0.1419))
+	})
+
+	It("should iterate", func() {
+		var vv []string
+		subject.Iterate(func(e Item[string]) bool {
+			vv = append(vv, e.Value)
+			return len(vv) < 4
+		})
+		Expect(vv).To(Equal([]string{"samoa", "kings-cross", "liverpool-st", "old-street"}))
+	})
+
+	It("should query by area", func() {
+		Expect(values(subject.Within(Area{MinLat: 51.5, MaxLat: 51.6, MinLon: -0.1, MaxLon: 0}))).To(Equal([]string{
+			"liverpool-st", "old-street",
+		}))
+		Expect(values(subject.Within(Area{MinLat: -20, MaxLat: 60, MinLon: -10, MaxLon: 10}))).To(ConsistOf(
+			"liverpool-st", "old-street", "kings-cross", "paris",
+		))
+		Expect(subject.Within(Area{MinLat: 10, MaxLat: 20, MinLon: 10, MaxLon: 20})).To(BeEmpty())
+	})
+
+	It("should query by radius", func() {
+		res := subject.Radius(51.5246, -0.0841, 1000)
+		Expect(neighbors(res)).To(Equal([]string{"old-street", "liverpool-st"}))
+		Expect(res[0].Distance).To(BeNumerically("~", 260, 1))
+		Expect(res[1].Distance).To(BeNumerically("~", 767, 1))
+
+		Expect(neighbors(subject.Radius(-15, 180, 900000))).To(Equal([]string{"fiji", "samoa"}))
+	})
+
+	It("should find nearest", func() {
+		Expect(neighbors(subject.Nearest(51.5246, -0.0841, 1))).To(Equal([]string{"old-street"}))
+		Expect(neighbors(subject.Nearest(51.5246, -0.0841, 4))).To(Equal([]string{"old-street", "liverpool-st", "kings-cross", "paris"}))
+		Expect(neighbors(subject.Nearest(0, 0, 10))).To(HaveLen(6))
+		Expect(subject.Nearest(0, 0, 0)).To(BeEmpty())
+	})
+
+	It("should match brute-force results", func() {
+		rnd := rand.New(rand.NewSource(1))
+		index := NewIndex[int]()
+		for i := 0; i < 2000; i++ {
+			Expect(index.Insert(i, rnd.Float64()*20+40, rnd.Float64()*20-10)).To(Succeed())
+		}
+
+		for i := 0; i < 20; i++ {
+			lat, lon := rnd.Float64()*20+40, rnd.Float64()*20-10
+
+			var exp []float64
+			index.Iterate(func(e Item[int]) bool {
+				exp = append(exp, Distance(lat, lon, e.Lat, e.Lon))
+				return true
+			})
+			sort.Float64s(exp)
+
+			res := index.Nearest(lat, lon, 10)
+			Expect(res).To(HaveLen(10))
+			for j, n := range res {
+				Expect(n.Distance).To(Equal(exp[j]))
+			}
+
+			radius := exp[25]
+			Expect(index.Radius(lat, lon, radius)).To(HaveLen(26))
+		}
+	})
+
+})
+
+func BenchmarkIndex_Radius(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	index := NewIndex[int]()
+	for i := 0; i < 100000; i++ {
+		_ = index.Insert(i, rnd.Float64()*20+40, rnd.Float64()*20-10)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index.Radius(51.5246, -0.0841, 10000)
+	}
+}
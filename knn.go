@@ -0,0 +1,166 @@
+package geohashi
+
+import (
+	"math"
+	"sort"
+)
+
+// LookupFunc returns all items located within a cell. Cells passed to the
+// function may be of any precision up to the initial search precision.
+type LookupFunc[T any] func(cell Hash) []Item[T]
+
+// maxRings is the number of rings explored at a precision level before KNN
+// zooms out to the parent level.
+const maxRings = 4
+
+// KNN returns the k nearest items to a location, ordered by distance. The
+// search starts at the cell of the given precision which contains the
+// location and expands outwards, ring by ring, zooming out to coarser
+// precisions if necessary. It stops as soon as no unexplored cell can be
+// closer than the k-th nearest candidate.
+func KNN[T any](lat, lon float64, k int, prec uint8, lookup LookupFunc[T]) []Neighbor[T] {
+	if k <= 0 || !ValidCoordinates(lat, lon) {
+		return nil
+	}
+	if prec < PrecisionMin || prec > PrecisionMax {
+		prec = PrecisionMax
+	}
+
+	for center := encode(lat, lon, prec); ; center = center.Parent() {
+		s := knnSearch[T]{lat: lat, lon: lon, k: k, center: center, lookup: lookup}
+		if s.run() || center.Precision() == PrecisionMin {
+			return s.res
+		}
+	}
+}
+
+// knnPrecision returns a suitable initial search precision for finding k of
+// n items. It picks the precision at which a cell would contain roughly k
+// items if they were evenly distributed and then zooms in a few levels, to
+// account for clustering.
+func knnPrecision(n, k int) uint8 {
+	if n < 1 || k < 1 {
+		return PrecisionMax
+	}
+
+	prec := 4 + int(math.Log(float64(n)/float64(k))/math.Log(4))
+	if prec < PrecisionMin {
+		return PrecisionMin
+	} else if prec > PrecisionMax {
+		return PrecisionMax
+	}
+	return uint8(prec)
+}
+
+type knnSearch[T any] struct {
+	lat, lon float64
+	k        int
+	center   Hash
+	lookup   LookupFunc[T]
+	res      []Neighbor[T]
+}
+
+// run explores up to maxRings rings around the centre cell and returns true
+// if the search is complete.
+func (s *knnSearch[T]) run() bool {
+	prec := s.center.Precision()
+	cx, cy := deinterleave64(s.center.base())
+	size := int64(1) << prec
+
+	seen := make(map[Hash]struct{})
+	for r := int64(0); r <= maxRings; r++ {
+		for _, cell := range ringCells(int64(cx), int64(cy), r, size) {
+			h := newHash(interleave64(cell[0], cell[1]), prec)
+			if _, ok := seen[h]; ok {
+				continue
+			}
+			seen[h] = struct{}{}
+
+			for _, item := range s.lookup(h) {
+				s.add(item)
+			}
+		}
+
+		bound := s.bound(int64(cx), int64(cy), r, size)
+		if math.IsInf(bound, 1) || (len(s.res) == s.k && s.res[s.k-1].Distance <= bound) {
+			return true
+		}
+	}
+	return false
+}
+
+// add adds an item to the (sorted) result if it is among the k nearest.
+func (s *knnSearch[T]) add(item Item[T]) {
+	dist := Distance(s.lat, s.lon, item.Lat, item.Lon)
+	if len(s.res) == s.k && s.res[s.k-1].Distance <= dist {
+		return
+	}
+
+	i := sort.Search(len(s.res), func(i int) bool { return s.res[i].Distance > dist })
+	if len(s.res) < s.k {
+		s.res = append(s.res, Neighbor[T]{})
+	}
+	copy(s.res[i+1:], s.res[i:])
+	s.res[i] = Neighbor[T]{Item: item, Distance: dist}
+}
+
+// bound returns the minimum distance from the location to any point outside
+// the block of cells within r rings of the centre.
+func (s *knnSearch[T]) bound(cx, cy, r, size int64) float64 {
+	cellLat := latScale / float64(size)
+	cellLon := lonScale / float64(size)
+	φ := deg2rad(s.lat)
+
+	bound := math.Inf(1)
+	if x := cx - r; x > 0 {
+		south := LatMin + float64(x)*cellLat
+		bound = math.Min(bound, deg2rad(s.lat-south)*EarthRadius)
+	}
+	if x := cx + r + 1; x < size {
+		north := LatMin + float64(x)*cellLat
+		bound = math.Min(bound, deg2rad(north-s.lat)*EarthRadius)
+	}
+	if 2*r+1 < size {
+		west := LonMin + float64(cy-r)*cellLon
+		east := LonMin + float64(cy+r+1)*cellLon
+		for _, dLon := range []float64{s.lon - west, east - s.lon} {
+			bound = math.Min(bound, meridianDistance(φ, deg2rad(dLon)))
+		}
+	}
+	return bound
+}
+
+// meridianDistance returns the minimum distance from a point at latitude φ
+// to a meridian dλ radians away.
+func meridianDistance(φ, dλ float64) float64 {
+	if dλ >= math.Pi/2 {
+		return (math.Pi/2 - math.Abs(φ)) * EarthRadius
+	}
+	return math.Asin(math.Sin(dλ)*math.Cos(φ)) * EarthRadius
+}
+
+// ringCells returns the grid coordinates of all cells exactly r rings away
+// from the centre. Longitudes wrap around, latitudes are clipped.
+func ringCells(cx, cy, r, size int64) [][2]uint64 {
+	var res [][2]uint64
+	add := func(x, y int64) {
+		if x < 0 || x >= size {
+			return
+		}
+		res = append(res, [2]uint64{uint64(x), uint64(((y % size) + size) % size)})
+	}
+
+	if r == 0 {
+		add(cx, cy)
+		return res
+	}
+	for y := cy - r; y <= cy+r; y++ {
+		add(cx-r, y)
+		add(cx+r, y)
+	}
+	for x := cx - r + 1; x < cx+r; x++ {
+		add(x, cy-r)
+		add(x, cy+r)
+	}
+	return res
+}
@@ -0,0 +1,105 @@
+package geohashi
+
+import (
+	"math/rand"
+	"sort"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KNN", func() {
+	var items []Item[int]
+	var lookups int
+
+	lookup := func(cell Hash) []Item[int] {
+		lookups++
+
+		var res []Item[int]
+		area := cell.Decode()
+		for _, item := range items {
+			if encode(item.Lat, item.Lon, cell.Precision()) == cell {
+				Expect(area.Contains(item.Lat, item.Lon)).To(BeTrue())
+				res = append(res, item)
+			}
+		}
+		return res
+	}
+
+	bruteForce := func(lat, lon float64, k int) []float64 {
+		var res []float64
+		for _, item := range items {
+			res = append(res, Distance(lat, lon, item.Lat, item.Lon))
+		}
+		sort.Float64s(res)
+		if len(res) > k {
+			res = res[:k]
+		}
+		return res
+	}
+
+	distances := func(nn []Neighbor[int]) []float64 {
+		res := make([]float64, 0, len(nn))
+		for _, n := range nn {
+			res = append(res, n.Distance)
+		}
+		return res
+	}
+
+	BeforeEach(func() {
+		lookups = 0
+		rnd := rand.New(rand.NewSource(1))
+		items = make([]Item[int], 1000)
+		for i := range items {
+			items[i] = Item[int]{Lat: rnd.Float64()*10 + 45, Lon: rnd.Float64()*10 - 5, Value: i}
+		}
+	})
+
+	It("should find nearest neighbors", func() {
+		res := KNN(50, 0, 5, 10, lookup)
+		Expect(res).To(HaveLen(5))
+		Expect(distances(res)).To(Equal(bruteForce(50, 0, 5)))
+		Expect(lookups).To(BeNumerically("<", 60))
+	})
+
+	It("should zoom out", func() {
+		res := KNN(50, 0, 5, 20, lookup)
+		Expect(distances(res)).To(Equal(bruteForce(50, 0, 5)))
+
+		res = KNN(-50, 100, 3, 20, lookup)
+		Expect(distances(res)).To(Equal(bruteForce(-50, 100, 3)))
+
+		res = KNN(0, 0, 2000, 20, lookup)
+		Expect(res).To(HaveLen(1000))
+		Expect(distances(res)).To(Equal(bruteForce(0, 0, 2000)))
+	})
+
+	It("should wrap around the antimeridian", func() {
+		items = []Item[int]{
+			{Lat: 10, Lon: 179.9, Value: 1},
+			{Lat: 10, Lon: -179.9, Value: 2},
+			{Lat: 10, Lon: 170, Value: 3},
+		}
+		res := KNN(10, -179.95, 2, 12, lookup)
+		Expect(res).To(HaveLen(2))
+		Expect(res[0].Value).To(Equal(2))
+		Expect(res[1].Value).To(Equal(1))
+	})
+
+	It("should match brute-force results", func() {
+		rnd := rand.New(rand.NewSource(2))
+		for i := 0; i < 50; i++ {
+			lat, lon := rnd.Float64()*12+44, rnd.Float64()*12-6
+			k := rnd.Intn(20) + 1
+			prec := uint8(rnd.Intn(PrecisionMax) + 1)
+			Expect(distances(KNN(lat, lon, k, prec, lookup))).To(Equal(bruteForce(lat, lon, k)), "for %v,%v k=%d prec=%d", lat, lon, k, prec)
+		}
+	})
+
+	It("should reject bad inputs", func() {
+		Expect(KNN(50, 0, 0, 10, lookup)).To(BeEmpty())
+		Expect(KNN(89, 0, 1, 10, lookup)).To(BeEmpty())
+		Expect(KNN(50, 0, 1, 0, lookup)).To(HaveLen(1))
+	})
+
+})
@@ -0,0 +1,148 @@
+package geohashi
+
+import (
+	"errors"
+	"math"
+)
+
+var errInvalidLocator = errors.New("geohashi: invalid locator")
+
+// Maidenhead locators divide the globe into 18x18 fields (letters A-R), each
+// field into 10x10 squares (digits), each square into 24x24 subsquares
+// (letters a-x) and so on, alternating between 10 and 24 divisions. Both axes
+// are therefore expressed in units of the finest supported level, the fifth
+// pair, which is 1/2880 degrees of longitude and 1/5760 degrees of latitude.
+const (
+	maidenheadMaxPairs = 5
+	maidenheadLonUnits = 2880 // units per degree of longitude
+	maidenheadLatUnits = 5760 // units per degree of latitude
+)
+
+// maidenheadLevels are the sizes and number of divisions of each pair, in units.
+var maidenheadLevels = [maidenheadMaxPairs]struct {
+	size, divs int64
+}{
+	{57600, 18},
+	{5760, 10},
+	{240, 24},
+	{24, 10},
+	{1, 24},
+}
+
+// EncodeMaidenhead encodes a location as a Maidenhead locator with 1 to 5
+// pairs, e.g. "JN58td" for a locator with 3 pairs. NaN and infinite
+// coordinates are rejected.
+func EncodeMaidenhead(lat, lon float64, pairs int) (string, error) {
+	if pairs < 1 || pairs > maidenheadMaxPairs {
+		return "", errInvalidLocator
+	}
+	if math.IsNaN(lat) || math.IsNaN(lon) || math.IsInf(lat, 0) || math.IsInf(lon, 0) {
+		return "", errInvalidLocator
+	}
+
+	// clip and wrap before converting, large values overflow int64
+	lat = math.Max(-90, math.Min(90, lat))
+	lon = math.Mod(lon, 360)
+
+	const max = 18 * 57600
+	x := int64(math.Floor(math.Round((lon+180)*maidenheadLonUnits*1e6) / 1e6))
+	y := int64(math.Floor(math.Round((lat+90)*maidenheadLatUnits*1e6) / 1e6))
+	if x %= max; x < 0 {
+		x += max
+	}
+	if y < 0 {
+		y = 0
+	} else if y >= max {
+		y = max - 1
+	}
+
+	buf := make([]byte, 0, 2*pairs)
+	for i, lvl := range maidenheadLevels[:pairs] {
+		var base byte
+		switch {
+		case i == 0:
+			base = 'A'
+		case lvl.divs == 10:
+			base = '0'
+		default:
+			base = 'a'
+		}
+		buf = append(buf, base+byte(x/lvl.size%lvl.divs), base+byte(y/lvl.size%lvl.divs))
+	}
+	return string(buf), nil
+}
+
+// DecodeMaidenhead decodes a Maidenhead locator into the area it identifies.
+// Letters are case-insensitive.
+func DecodeMaidenhead(loc string) (Area, error) {
+	if len(loc) < 2 || len(loc) > 2*maidenheadMaxPairs || len(loc)%2 != 0 {
+		return Area{}, errInvalidLocator
+	}
+
+	var x, y, size int64
+	for i := 0; i < len(loc); i += 2 {
+		lvl := maidenheadLevels[i/2]
+		dx, ok1 := maidenheadDigit(loc[i], lvl.divs)
+		dy, ok2 := maidenheadDigit(loc[i+1], lvl.divs)
+		if !ok1 || !ok2 {
+			return Area{}, errInvalidLocator
+		}
+		x += dx * lvl.size
+		y += dy * lvl.size
+		size = lvl.size
+	}
+
+	return Area{
+		MinLat: float64(y)/maidenheadLatUnits - 90,
+		MaxLat: float64(y+size)/maidenheadLatUnits - 90,
+		MinLon: float64(x)/maidenheadLonUnits - 180,
+		MaxLon: float64(x+size)/maidenheadLonUnits - 180,
+	}, nil
+}
+
+// CoverMaidenhead returns the hashes which cover the area of a Maidenhead
+// locator, in ascending order. The precision is chosen as the highest at
+// which the area is covered by no more than 16 hashes.
+func CoverMaidenhead(loc string) ([]Hash, error) {
+	a, err := DecodeMaidenhead(loc)
+	if err != nil {
+		return nil, err
+	}
+	return a.coverCells(locatorCoverCells)
+}
+
+// Maidenhead returns the most precise Maidenhead locator which encloses the
+// entire area. It returns an error if the area crosses the boundary of the
+// 20 x 10 degree fields.
+func (a Area) Maidenhead() (string, error) {
+	return a.enclosingCode([]int{5, 4, 3, 2, 1}, EncodeMaidenhead, DecodeMaidenhead)
+}
+
+// Maidenhead returns the most precise Maidenhead locator which encloses the
+// hash.
+func (h Hash) Maidenhead() (string, error) {
+	return h.Decode().Maidenhead()
+}
+
+// maidenheadDigit returns the value of a single locator character.
+func maidenheadDigit(c byte, divs int64) (int64, bool) {
+	var d int64
+	switch {
+	case divs == 10:
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		return int64(c - '0'), true
+	case c >= 'a' && c <= 'z':
+		d = int64(c - 'a')
+	case c >= 'A' && c <= 'Z':
+		d = int64(c - 'A')
+	default:
+		return 0, false
+	}
+
+	if d >= divs {
+		return 0, false
+	}
+	return d, true
+}
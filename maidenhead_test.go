@@ -0,0 +1,111 @@
+package geohashi
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Maidenhead", func() {
+	DescribeTable("should encode",
+		func(lat, lon float64, pairs int, exp string) {
+			Expect(EncodeMaidenhead(lat, lon, pairs)).To(Equal(exp))
+		},
+		Entry("Munich", 48.14666, 11.60833, 3, "JN58td"),
+		Entry("Montevideo", -34.91, -56.21166, 3, "GF15vc"),
+		Entry("Washington, DC", 38.92, -77.065, 3, "FM18lw"),
+		Entry("Wellington", -41.28333, 174.745, 3, "RE78ir"),
+		Entry("Newington, CT", 41.714775, -72.727260, 3, "FN31pr"),
+		Entry("Palo Alto", 37.413708, -122.1073236, 3, "CM87wj"),
+		Entry("field", 48.14666, 11.60833, 1, "JN"),
+		Entry("square", 48.14666, 11.60833, 2, "JN58"),
+		Entry("south-west", -90.0, -180.0, 5, "AA00aa00aa"),
+		Entry("north-east", 90.0, 180.0, 2, "AR09"),
+		Entry("north pole", 90.0, 179.9999, 5, "RR99xx99xx"),
+	)
+
+	It("should reject invalid pairs", func() {
+		for _, n := range []int{0, 6} {
+			_, err := EncodeMaidenhead(0, 0, n)
+			Expect(err).To(MatchError(errInvalidLocator))
+		}
+	})
+
+	It("should reject invalid coordinates", func() {
+		for _, pt := range []Point{{math.NaN(), 11}, {48, math.NaN()}, {math.Inf(-1), 11}, {48, math.Inf(1)}} {
+			_, err := EncodeMaidenhead(pt.Lat, pt.Lon, 3)
+			Expect(err).To(MatchError(errInvalidLocator), "point %v", pt)
+		}
+	})
+
+	It("should clip and normalise huge coordinates", func() {
+		for _, tc := range []struct{ lat, lon, expLat, expLon float64 }{
+			{1e20, 11, 90, 11},
+			{-1e20, 11, -90, 11},
+			{48, 11 + 360*1e6, 48, 11},
+			{48, math.MaxFloat64, 48, 128},
+			{48, -math.MaxFloat64, 48, -128},
+		} {
+			exp, err := EncodeMaidenhead(tc.expLat, tc.expLon, 5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(EncodeMaidenhead(tc.lat, tc.lon, 5)).To(Equal(exp), "%v, %v", tc.lat, tc.lon)
+		}
+	})
+
+	It("should decode", func() {
+		a, err := DecodeMaidenhead("JN58td")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.MinLat).To(BeNumerically("~", 48.125, 1e-9))
+		Expect(a.MaxLat).To(BeNumerically("~", 48.166667, 1e-6))
+		Expect(a.MinLon).To(BeNumerically("~", 11.583333, 1e-6))
+		Expect(a.MaxLon).To(BeNumerically("~", 11.666667, 1e-6))
+
+		Expect(DecodeMaidenhead("jn")).To(Equal(Area{MinLat: 40, MaxLat: 50, MinLon: 0, MaxLon: 20}))
+		Expect(DecodeMaidenhead("AA00aa00aa")).To(Equal(Area{MinLat: -90, MaxLat: -90 + 1.0/5760, MinLon: -180, MaxLon: -180 + 1.0/2880}))
+
+		for _, loc := range []string{"", "J", "JN5", "SN58", "JN5A", "JN58ty", "JN58td0a", "JN58td00aa00", "J!"} {
+			_, err := DecodeMaidenhead(loc)
+			Expect(err).To(MatchError(errInvalidLocator), loc)
+		}
+	})
+
+	It("should round-trip", func() {
+		for n := 1; n <= 5; n++ {
+			loc, err := EncodeMaidenhead(-33.8568, 151.2153, n)
+			Expect(err).NotTo(HaveOccurred())
+
+			a, err := DecodeMaidenhead(loc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Contains(-33.8568, 151.2153)).To(BeTrue(), loc)
+			Expect(a.Maidenhead()).To(Equal(loc))
+		}
+	})
+
+	It("should cover locators with hashes", func() {
+		hashes, err := CoverMaidenhead("JN58td")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(hashes)).To(BeNumerically("<=", 16))
+		Expect(len(hashes)).To(BeNumerically(">", 1))
+
+		a, _ := DecodeMaidenhead("JN58td")
+		for _, h := range hashes {
+			Expect(h.Decode().Relate(a)).NotTo(Equal(Outside))
+		}
+
+		_, err = CoverMaidenhead("JN58t")
+		Expect(err).To(MatchError(errInvalidLocator))
+		_, err = CoverMaidenhead("AR99")
+		Expect(err).To(MatchError(errInvalidArea))
+	})
+
+	It("should find enclosing locators", func() {
+		Expect(EncodeWithPrecision(48.14666, 11.60833, 12).Maidenhead()).To(Equal("JN58"))
+		Expect(EncodeWithPrecision(48.14666, 11.60833, 14).Maidenhead()).To(Equal("JN58td"))
+		Expect(EncodeWithPrecision(48.147, 11.61, 20).Maidenhead()).To(Equal("JN58td35"))
+
+		_, err := Area{MinLat: 39, MaxLat: 41, MinLon: 1, MaxLon: 2}.Maidenhead()
+		Expect(err).To(MatchError(errInvalidArea))
+	})
+})
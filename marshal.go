@@ -0,0 +1,146 @@
+package geohashi
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"strconv"
+)
+
+// validate checks that the hash has a valid precision and no stray bits
+// outside the range covered by its precision.
+func (h Hash) validate() error {
+	prec := uint64(h) >> recisionOffset
+	if prec < PrecisionMin || prec > PrecisionMax {
+		return errInvalidPrecision
+	}
+	if h.base()>>(2*prec) != 0 {
+		return errInvalidHash
+	}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler. Hashes are encoded as
+// decimal strings.
+func (h Hash) MarshalText() ([]byte, error) {
+	return strconv.AppendUint(nil, uint64(h), 10), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (h *Hash) UnmarshalText(data []byte) error {
+	n, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil {
+		return errInvalidHash
+	}
+	return h.set(Hash(n))
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Hashes are encoded as
+// 8 big-endian bytes.
+func (h Hash) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, uint64(h))
+	return data, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (h *Hash) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return errInvalidHash
+	}
+	return h.set(Hash(binary.BigEndian.Uint64(data)))
+}
+
+// MarshalJSON implements json.Marshaler. Hashes are encoded as JSON strings
+// to prevent loss of precision in clients which treat numbers as doubles.
+// The zero Hash is encoded as null, so zero values round-trip.
+func (h Hash) MarshalJSON() ([]byte, error) {
+	if h == 0 {
+		return []byte("null"), nil
+	}
+
+	data := make([]byte, 0, 20)
+	data = append(data, '"')
+	data = strconv.AppendUint(data, uint64(h), 10)
+	data = append(data, '"')
+	return data, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts JSON strings and
+// numbers as well as the object notation produced by DetailedHash. By
+// convention, null is a no-op.
+func (h *Hash) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) != 0 && data[0] == '{' {
+		return (*DetailedHash)(h).UnmarshalJSON(data)
+	}
+	if len(data) > 1 && data[0] == '"' && data[len(data)-1] == '"' {
+		data = data[1 : len(data)-1]
+	}
+	return h.UnmarshalText(data)
+}
+
+func (h *Hash) set(v Hash) error {
+	if err := v.validate(); err != nil {
+		return err
+	}
+	*h = v
+	return nil
+}
+
+// --------------------------------------------------------------------
+
+// DetailedHash is a Hash which is encoded as a JSON object, including its
+// precision and bounding box, e.g.:
+//
+//	{"hash":"90072520759854475","precision":20,"bbox":[-0.0847,51.5244,-0.0841,51.5248]}
+//
+// The bounding box is in GeoJSON order: min lon, min lat, max lon, max lat.
+type DetailedHash Hash
+
+type detailedHashJSON struct {
+	Hash      Hash       `json:"hash"`
+	Precision uint8      `json:"precision"`
+	BBox      [4]float64 `json:"bbox"`
+}
+
+// MarshalJSON implements json.Marshaler. Like Hash, the zero DetailedHash is
+// encoded as null.
+func (d DetailedHash) MarshalJSON() ([]byte, error) {
+	if d == 0 {
+		return []byte("null"), nil
+	}
+
+	h := Hash(d)
+	a := h.Decode()
+	return json.Marshal(detailedHashJSON{
+		Hash:      h,
+		Precision: h.Precision(),
+		BBox:      [4]float64{a.MinLon, a.MinLat, a.MaxLon, a.MaxLat},
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The bounding box is ignored,
+// but the precision must match the hash if given.
+func (d *DetailedHash) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var v struct {
+		Hash      *Hash  `json:"hash"`
+		Precision *uint8 `json:"precision"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Hash == nil {
+		return errInvalidHash
+	}
+	if v.Precision != nil && *v.Precision != v.Hash.Precision() {
+		return errInvalidPrecision
+	}
+	*d = DetailedHash(*v.Hash)
+	return nil
+}
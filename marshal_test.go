@@ -0,0 +1,99 @@
+package geohashi
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hash marshaling", func() {
+	const hash = Hash(90072520759854475)
+
+	It("should marshal text", func() {
+		data, err := hash.MarshalText()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("90072520759854475"))
+
+		var h Hash
+		Expect(h.UnmarshalText(data)).To(Succeed())
+		Expect(h).To(Equal(hash))
+	})
+
+	It("should marshal binary", func() {
+		data, err := hash.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal([]byte{0x01, 0x40, 0x00, 0x7a, 0xfb, 0xea, 0x45, 0x8b}))
+
+		var h Hash
+		Expect(h.UnmarshalBinary(data)).To(Succeed())
+		Expect(h).To(Equal(hash))
+		Expect(h.UnmarshalBinary(data[:7])).To(MatchError(errInvalidHash))
+	})
+
+	It("should marshal JSON", func() {
+		data, err := json.Marshal(map[string]Hash{"h": hash})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"h":"90072520759854475"}`))
+
+		var v map[string]Hash
+		Expect(json.Unmarshal(data, &v)).To(Succeed())
+		Expect(v).To(Equal(map[string]Hash{"h": hash}))
+
+		var h Hash
+		Expect(json.Unmarshal([]byte(`90072520759854475`), &h)).To(Succeed())
+		Expect(h).To(Equal(hash))
+
+		Expect(json.Unmarshal([]byte(`null`), &h)).To(Succeed())
+		Expect(h).To(Equal(hash))
+
+		var p struct{ H *Hash }
+		Expect(json.Unmarshal([]byte(`{"H":null}`), &p)).To(Succeed())
+		Expect(p.H).To(BeNil())
+	})
+
+	It("should round-trip zero values through JSON", func() {
+		type record struct {
+			H Hash
+			D DetailedHash
+		}
+
+		data, err := json.Marshal(record{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"H":null,"D":null}`))
+
+		var v record
+		Expect(json.Unmarshal(data, &v)).To(Succeed())
+		Expect(v).To(Equal(record{}))
+	})
+
+	It("should marshal detailed JSON", func() {
+		data, err := json.Marshal(DetailedHash(EncodeWithPrecision(0, 0, 1)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(MatchJSON(`{"hash":"4503599627370499","precision":1,"bbox":[0,0,180,85.05112878]}`))
+
+		var d DetailedHash
+		Expect(json.Unmarshal(data, &d)).To(Succeed())
+		Expect(d).To(Equal(DetailedHash(4503599627370499)))
+
+		var h Hash
+		Expect(json.Unmarshal(data, &h)).To(Succeed())
+		Expect(h).To(Equal(Hash(4503599627370499)))
+
+		Expect(json.Unmarshal([]byte(`{"hash":"4503599627370499","precision":2}`), &d)).To(MatchError(errInvalidPrecision))
+		Expect(json.Unmarshal([]byte(`{"precision":1}`), &d)).To(MatchError(errInvalidHash))
+		Expect(json.Unmarshal([]byte(`{"hash":null}`), &d)).To(MatchError(errInvalidHash))
+		Expect(json.Unmarshal([]byte(`null`), &d)).To(Succeed())
+		Expect(d).To(Equal(DetailedHash(4503599627370499)))
+	})
+
+	It("should validate", func() {
+		var h Hash
+		Expect(h.UnmarshalText([]byte("x"))).To(MatchError(errInvalidHash))
+		Expect(h.UnmarshalText([]byte("0"))).To(MatchError(errInvalidPrecision))
+		Expect(h.UnmarshalText([]byte("121597189939003392"))).To(MatchError(errInvalidPrecision)) // 0x01b0000000000000
+		Expect(h.UnmarshalText([]byte("4503599627370500"))).To(MatchError(errInvalidHash))        // 0x0010000000000004
+		Expect(h).To(Equal(Hash(0)))
+	})
+
+})
@@ -0,0 +1,195 @@
+package geohashi
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var errInvalidMGRS = errors.New("geohashi: invalid MGRS reference")
+
+const (
+	mgrsBands     = "CDEFGHJKLMNPQRSTUVWX"
+	mgrsRows      = "ABCDEFGHJKLMNPQRSTUV"
+	mgrsMaxDigits = 5
+	mgrsSquare    = 100000.0 // size of a 100km square, in meters
+)
+
+// mgrsCols are the column letters of 100km squares, by zone set.
+var mgrsCols = [3]string{"ABCDEFGH", "JKLMNPQR", "STUVWXYZ"}
+
+// EncodeMGRS encodes a location as an MGRS grid reference with the given
+// number of digits per axis, between 0 (100km) and 5 (1m). References are
+// returned without spaces, e.g. "38SMB4414084706". Polar regions, which use
+// UPS rather than UTM, are not supported.
+func EncodeMGRS(lat, lon float64, digits int) (string, error) {
+	if digits < 0 || digits > mgrsMaxDigits {
+		return "", errInvalidMGRS
+	}
+
+	u, err := ToUTM(lat, lon)
+	if err != nil {
+		return "", err
+	}
+
+	band := int(math.Floor((lat - utmLatMin) / 8))
+	if band >= len(mgrsBands) {
+		band = len(mgrsBands) - 1 // band X spans 12 degrees
+	}
+
+	e, n := int64(u.Easting), int64(u.Northing)
+	col := e/int64(mgrsSquare) - 1
+	row := n / int64(mgrsSquare) % 20
+	if u.Zone%2 == 0 {
+		row = (row + 5) % 20
+	}
+
+	buf := make([]byte, 0, 5+2*digits)
+	buf = strconv.AppendInt(buf, int64(u.Zone), 10)
+	buf = append(buf, mgrsBands[band], mgrsCols[(u.Zone-1)%3][col], mgrsRows[row])
+	if digits != 0 {
+		scale := int64(math.Pow10(mgrsMaxDigits - digits))
+		buf = appendPadded(buf, e%int64(mgrsSquare)/scale, digits)
+		buf = appendPadded(buf, n%int64(mgrsSquare)/scale, digits)
+	}
+	return string(buf), nil
+}
+
+// DecodeMGRS decodes an MGRS grid reference into the bounding box of the grid
+// square it identifies. Spaces are ignored and letters are case-insensitive.
+func DecodeMGRS(ref string) (Area, error) {
+	u, size, err := parseMGRS(ref)
+	if err != nil {
+		return Area{}, err
+	}
+
+	a := Area{MinLat: 90, MaxLat: -90, MinLon: 180, MaxLon: -180}
+	for _, c := range [4][2]float64{{0, 0}, {size, 0}, {0, size}, {size, size}} {
+		corner := u
+		corner.Easting += c[0]
+		corner.Northing += c[1]
+
+		lat, lon, err := corner.LatLon()
+		if err != nil {
+			return Area{}, err
+		}
+		a.MinLat, a.MaxLat = math.Min(a.MinLat, lat), math.Max(a.MaxLat, lat)
+		a.MinLon, a.MaxLon = math.Min(a.MinLon, lon), math.Max(a.MaxLon, lon)
+	}
+	return a, nil
+}
+
+// CoverMGRS returns the hashes which cover the bounding box of an MGRS grid
+// square, in ascending order. The precision is chosen as the highest at which
+// the area is covered by no more than 16 hashes.
+func CoverMGRS(ref string) ([]Hash, error) {
+	a, err := DecodeMGRS(ref)
+	if err != nil {
+		return nil, err
+	}
+	return a.coverCells(locatorCoverCells)
+}
+
+// MGRS returns the most precise MGRS grid reference whose square contains all
+// four corners of the area.
+func (a Area) MGRS() (string, error) {
+	if a.MinLat > a.MaxLat || a.MinLon > a.MaxLon {
+		return "", errInvalidArea
+	}
+
+	for digits := mgrsMaxDigits; digits >= 0; digits-- {
+		ref, err := EncodeMGRS(a.MinLat, a.MinLon, digits)
+		if err != nil {
+			return "", errInvalidArea
+		}
+
+		ok := true
+		for _, c := range [3][2]float64{{a.MinLat, a.MaxLon}, {a.MaxLat, a.MinLon}, {a.MaxLat, a.MaxLon}} {
+			if s, err := EncodeMGRS(c[0], c[1], digits); err != nil || s != ref {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return ref, nil
+		}
+	}
+	return "", errInvalidArea
+}
+
+// MGRS returns the most precise MGRS grid reference whose square contains the
+// hash.
+func (h Hash) MGRS() (string, error) {
+	return h.Decode().MGRS()
+}
+
+// parseMGRS parses a reference and returns the south-west corner of its grid
+// square and the size of the square, in meters.
+func parseMGRS(ref string) (UTM, float64, error) {
+	ref = strings.ToUpper(strings.ReplaceAll(ref, " ", ""))
+
+	// zone
+	i := 0
+	for i < len(ref) && i < 2 && ref[i] >= '0' && ref[i] <= '9' {
+		i++
+	}
+	zone, err := strconv.Atoi(ref[:i])
+	if err != nil || zone < 1 || zone > 60 || len(ref) < i+3 {
+		return UTM{}, 0, errInvalidMGRS
+	}
+
+	// band and 100km square
+	band := strings.IndexByte(mgrsBands, ref[i])
+	col := strings.IndexByte(mgrsCols[(zone-1)%3], ref[i+1])
+	row := strings.IndexByte(mgrsRows, ref[i+2])
+	if band < 0 || col < 0 || row < 0 {
+		return UTM{}, 0, errInvalidMGRS
+	}
+	if zone%2 == 0 {
+		row = (row + 15) % 20
+	}
+
+	// numerical location
+	nums := ref[i+3:]
+	if len(nums)%2 != 0 || len(nums) > 2*mgrsMaxDigits {
+		return UTM{}, 0, errInvalidMGRS
+	}
+	digits := len(nums) / 2
+	size := math.Pow10(mgrsMaxDigits - digits)
+
+	var e, n float64
+	if digits != 0 {
+		ei, err1 := strconv.ParseUint(nums[:digits], 10, 32)
+		ni, err2 := strconv.ParseUint(nums[digits:], 10, 32)
+		if err1 != nil || err2 != nil {
+			return UTM{}, 0, errInvalidMGRS
+		}
+		e, n = float64(ei)*size, float64(ni)*size
+	}
+
+	// row letters repeat every 2000km, find the cycle which contains the
+	// band; the margin accounts for the curvature of parallels
+	bandLat := utmLatMin + float64(band)*8
+	minNorthing := toUTMZone(bandLat, utmCentralMeridian(zone), zone).Northing - mgrsSquare
+	northing := float64(row)*mgrsSquare + n
+	for northing < minNorthing {
+		northing += 20 * mgrsSquare
+	}
+
+	return UTM{
+		Zone:     zone,
+		North:    bandLat >= 0,
+		Easting:  float64(col+1)*mgrsSquare + e,
+		Northing: northing,
+	}, size, nil
+}
+
+// appendPadded appends a zero-padded number with the given width.
+func appendPadded(buf []byte, v int64, width int) []byte {
+	s := strconv.FormatInt(v, 10)
+	for i := len(s); i < width; i++ {
+		buf = append(buf, '0')
+	}
+	return append(buf, s...)
+}
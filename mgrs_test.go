@@ -0,0 +1,101 @@
+package geohashi
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MGRS", func() {
+	DescribeTable("should encode",
+		func(lat, lon float64, digits int, exp string) {
+			Expect(EncodeMGRS(lat, lon, digits)).To(Equal(exp))
+		},
+		// GeographicLib: echo 33.3 44.4 | GeoConvert -m
+		Entry("1m", 33.3, 44.4, 5, "38SMB4414084706"),
+		Entry("100m", 33.3, 44.4, 3, "38SMB441847"),
+		Entry("100km", 33.3, 44.4, 0, "38SMB"),
+		Entry("CN Tower", 43.642567, -79.387139, 5, "17TPJ3008433438"),
+		Entry("equator", 0.0, 0.0, 5, "31NAA6602100000"),
+	)
+
+	It("should reject invalid inputs", func() {
+		_, err := EncodeMGRS(33.3, 44.4, 6)
+		Expect(err).To(MatchError(errInvalidMGRS))
+		_, err = EncodeMGRS(85, 44.4, 5)
+		Expect(err).To(MatchError(errInvalidCoordinates))
+		_, err = EncodeMGRS(math.NaN(), 44.4, 5)
+		Expect(err).To(MatchError(errInvalidCoordinates))
+	})
+
+	It("should decode", func() {
+		a, err := DecodeMGRS("38SMB4414084706")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.Contains(33.3, 44.4)).To(BeTrue())
+		Expect(a.MaxLat - a.MinLat).To(BeNumerically("~", 0.00001, 0.000005))
+
+		b, err := DecodeMGRS("38s mb 44140 84706")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b).To(Equal(a))
+
+		a, err = DecodeMGRS("38SMB")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.Contains(33.3, 44.4)).To(BeTrue())
+		Expect(a.MaxLat - a.MinLat).To(BeNumerically("~", 0.9, 0.1))
+
+		for _, ref := range []string{"", "38", "61SMB", "0SMB", "38IMB", "38SAB", "38SMI", "38SMB441", "38SMB4414084706123456", "38SMB44X4"} {
+			_, err := DecodeMGRS(ref)
+			Expect(err).To(MatchError(errInvalidMGRS), ref)
+		}
+	})
+
+	It("should round-trip", func() {
+		for _, pt := range []Point{
+			{Lat: -33.8568, Lon: 151.2153},
+			{Lat: -79.5, Lon: -70},
+			{Lat: 83.5, Lon: 20},
+			{Lat: 0.0001, Lon: 0},
+			{Lat: -0.0001, Lon: 0},
+			{Lat: 63.9, Lon: 11.9},
+			{Lat: 51.5074, Lon: -0.1278},
+		} {
+			for digits := 0; digits <= 5; digits++ {
+				ref, err := EncodeMGRS(pt.Lat, pt.Lon, digits)
+				Expect(err).NotTo(HaveOccurred())
+
+				a, err := DecodeMGRS(ref)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(a.Contains(pt.Lat, pt.Lon)).To(BeTrue(), ref)
+			}
+		}
+	})
+
+	It("should cover grid squares with hashes", func() {
+		hashes, err := CoverMGRS("38SMB441847")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(hashes)).To(BeNumerically("<=", 16))
+		Expect(len(hashes)).To(BeNumerically(">", 1))
+
+		a, _ := DecodeMGRS("38SMB441847")
+		for _, h := range hashes {
+			Expect(h.Decode().Relate(a)).NotTo(Equal(Outside))
+		}
+
+		_, err = CoverMGRS("38SMB441")
+		Expect(err).To(MatchError(errInvalidMGRS))
+	})
+
+	It("should find enclosing grid references", func() {
+		h := EncodeWithPrecision(33.3, 44.4, 20)
+		ref, err := h.MGRS()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ref).To(Equal("38SMB441847"))
+
+		_, err = Area{MinLat: 33, MaxLat: 34, MinLon: 44, MaxLon: 46}.MGRS()
+		Expect(err).To(MatchError(errInvalidArea))
+		_, err = Area{MinLat: 85, MaxLat: 86, MinLon: 44, MaxLon: 46}.MGRS()
+		Expect(err).To(MatchError(errInvalidArea))
+	})
+})
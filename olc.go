@@ -0,0 +1,211 @@
+package geohashi
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+var errInvalidPlusCode = errors.New("geohashi: invalid plus code")
+
+// Open Location Code constants, see
+// https://github.com/google/open-location-code/blob/main/docs/specification.md
+const (
+	olcAlphabet     = "23456789CFGHJMPQRVWX"
+	olcSeparator    = '+'
+	olcSeparatorPos = 8
+	olcPadding      = '0'
+
+	olcPairLen      = 10          // number of digits in the pair section
+	olcMaxLen       = 15          // maximum number of significant digits
+	olcGridRows     = 5           // rows of grid refinement digits
+	olcGridCols     = 4           // columns of grid refinement digits
+	olcPairPrec     = 8000        // 20^3, pair section precision per degree
+	olcFinalLatPrec = 8000 * 3125 // olcPairPrec * olcGridRows^5
+	olcFinalLonPrec = 8000 * 1024 // olcPairPrec * olcGridCols^5
+)
+
+// EncodePlusCode encodes a location as a full Open Location Code (Plus Code)
+// with the given number of digits. Valid lengths are 2, 4, 6, 8, 10 and
+// 11 to 15. Codes with 10 digits, e.g. "8FVC9G8F+6X", identify an area of
+// roughly 14 x 14 meters. Latitudes are clipped at the poles, longitudes are
+// normalised, but NaN and infinite coordinates are rejected.
+func EncodePlusCode(lat, lon float64, length int) (string, error) {
+	if length < 2 || length > olcMaxLen || (length < olcPairLen && length%2 == 1) {
+		return "", errInvalidPlusCode
+	}
+	if math.IsNaN(lat) || math.IsNaN(lon) || math.IsInf(lat, 0) || math.IsInf(lon, 0) {
+		return "", errInvalidPlusCode
+	}
+
+	// clip and wrap before converting, large values overflow int64
+	lat = math.Max(-90, math.Min(90, lat))
+	lon = math.Mod(lon, 360)
+
+	latVal := int64(math.Floor(math.Round((lat+90)*olcFinalLatPrec*1e6) / 1e6))
+	lonVal := int64(math.Floor(math.Round((lon+180)*olcFinalLonPrec*1e6) / 1e6))
+
+	// clip latitude, normalise longitude
+	if latVal < 0 {
+		latVal = 0
+	} else if latVal >= 180*olcFinalLatPrec {
+		latVal = 180*olcFinalLatPrec - 1
+	}
+	if lonVal %= 360 * olcFinalLonPrec; lonVal < 0 {
+		lonVal += 360 * olcFinalLonPrec
+	}
+
+	var digits [olcMaxLen]byte
+	for i := olcMaxLen - 1; i >= olcPairLen; i-- {
+		digits[i] = olcAlphabet[(latVal%olcGridRows)*olcGridCols+lonVal%olcGridCols]
+		latVal /= olcGridRows
+		lonVal /= olcGridCols
+	}
+	for i := olcPairLen - 2; i >= 0; i -= 2 {
+		digits[i] = olcAlphabet[latVal%20]
+		digits[i+1] = olcAlphabet[lonVal%20]
+		latVal /= 20
+		lonVal /= 20
+	}
+
+	var sb strings.Builder
+	sb.Grow(length + 2)
+	for i := 0; i < olcSeparatorPos; i++ {
+		if i < length {
+			sb.WriteByte(digits[i])
+		} else {
+			sb.WriteByte(olcPadding)
+		}
+	}
+	sb.WriteByte(olcSeparator)
+	if length > olcSeparatorPos {
+		sb.Write(digits[olcSeparatorPos:length])
+	}
+	return sb.String(), nil
+}
+
+// DecodePlusCode decodes a full Open Location Code (Plus Code) into the area
+// it identifies. Short codes, which require a reference location, are not
+// supported. Digits beyond the 15th are ignored.
+func DecodePlusCode(code string) (Area, error) {
+	digits, err := parsePlusCode(code)
+	if err != nil {
+		return Area{}, err
+	}
+	if len(digits) > olcMaxLen {
+		digits = digits[:olcMaxLen]
+	}
+
+	// pair section, in units of 1/olcPairPrec degrees
+	var lat, lon int64 = -90 * olcPairPrec, -180 * olcPairPrec
+	pv := int64(20 * 20 * 20 * 20)
+	n := len(digits)
+	if n > olcPairLen {
+		n = olcPairLen
+	}
+	for i := 0; i < n; i += 2 {
+		lat += int64(strings.IndexByte(olcAlphabet, digits[i])) * pv
+		lon += int64(strings.IndexByte(olcAlphabet, digits[i+1])) * pv
+		if i < n-2 {
+			pv /= 20
+		}
+	}
+	latSize := float64(pv) / olcPairPrec
+	lonSize := float64(pv) / olcPairPrec
+
+	// grid section, in units of 1/olcFinal*Prec degrees
+	var latGrid, lonGrid int64
+	if len(digits) > olcPairLen {
+		rpv, cpv := int64(625), int64(256)
+		for i := olcPairLen; i < len(digits); i++ {
+			d := int64(strings.IndexByte(olcAlphabet, digits[i]))
+			latGrid += d / olcGridCols * rpv
+			lonGrid += d % olcGridCols * cpv
+			if i < len(digits)-1 {
+				rpv /= olcGridRows
+				cpv /= olcGridCols
+			}
+		}
+		latSize = float64(rpv) / olcFinalLatPrec
+		lonSize = float64(cpv) / olcFinalLonPrec
+	}
+
+	minLat := float64(lat)/olcPairPrec + float64(latGrid)/olcFinalLatPrec
+	minLon := float64(lon)/olcPairPrec + float64(lonGrid)/olcFinalLonPrec
+	return Area{
+		MinLat: roundPlusCode(minLat),
+		MaxLat: roundPlusCode(minLat + latSize),
+		MinLon: roundPlusCode(minLon),
+		MaxLon: roundPlusCode(minLon + lonSize),
+	}, nil
+}
+
+// CoverPlusCode returns the hashes which cover the area of a Plus Code, in
+// ascending order. The precision is chosen as the highest at which the area
+// is covered by no more than 16 hashes. Hashes which only touch the edges of
+// the code area are not included.
+func CoverPlusCode(code string) ([]Hash, error) {
+	a, err := DecodePlusCode(code)
+	if err != nil {
+		return nil, err
+	}
+	return a.coverCells(locatorCoverCells)
+}
+
+// PlusCode returns the most precise full Plus Code which encloses the entire
+// area. It returns an error if the area is not enclosed by any code, i.e.
+// when it crosses the boundary of the 20 x 20 degree cells identified by
+// the first two digits.
+func (a Area) PlusCode() (string, error) {
+	return a.enclosingCode([]int{15, 14, 13, 12, 11, 10, 8, 6, 4, 2}, EncodePlusCode, DecodePlusCode)
+}
+
+// PlusCode returns the most precise full Plus Code which encloses the hash.
+func (h Hash) PlusCode() (string, error) {
+	return h.Decode().PlusCode()
+}
+
+// parsePlusCode validates a full code and returns its significant digits, in
+// upper case and without the separator and padding.
+func parsePlusCode(code string) (string, error) {
+	code = strings.ToUpper(code)
+
+	sep := strings.IndexByte(code, olcSeparator)
+	if sep != olcSeparatorPos || strings.LastIndexByte(code, olcSeparator) != sep {
+		return "", errInvalidPlusCode
+	}
+
+	head, tail := code[:sep], code[sep+1:]
+	if len(tail) == 1 {
+		return "", errInvalidPlusCode
+	}
+
+	// padding must start at an even position, fill the rest of the pair
+	// section and must not be followed by further digits
+	if pad := strings.IndexByte(head, olcPadding); pad > -1 {
+		if pad == 0 || pad%2 == 1 || strings.Trim(head[pad:], string(olcPadding)) != "" || tail != "" {
+			return "", errInvalidPlusCode
+		}
+		head = head[:pad]
+	}
+
+	digits := head + tail
+	for i := 0; i < len(digits); i++ {
+		if strings.IndexByte(olcAlphabet, digits[i]) < 0 {
+			return "", errInvalidPlusCode
+		}
+	}
+
+	// the first latitude digit must not exceed 90 degrees, the first
+	// longitude digit must not exceed 180 degrees
+	if strings.IndexByte(olcAlphabet, digits[0])*20 >= 180 || strings.IndexByte(olcAlphabet, digits[1])*20 >= 360 {
+		return "", errInvalidPlusCode
+	}
+	return digits, nil
+}
+
+// roundPlusCode rounds away floating point noise, like the reference
+// implementation.
+func roundPlusCode(v float64) float64 {
+	return math.Round(v*1e14) / 1e14
+}
@@ -0,0 +1,175 @@
+package geohashi
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PlusCode", func() {
+	// test vectors from github.com/google/open-location-code/test_data
+	DescribeTable("should encode",
+		func(lat, lon float64, length int, exp string) {
+			Expect(EncodePlusCode(lat, lon, length)).To(Equal(exp))
+		},
+		Entry("6 digits", 20.375, 2.775, 6, "7FG49Q00+"),
+		Entry("10 digits", 20.3700625, 2.7821875, 10, "7FG49QCJ+2V"),
+		Entry("11 digits", 20.3701125, 2.782234375, 11, "7FG49QCJ+2VX"),
+		Entry("13 digits", 20.3701135, 2.78223535156, 13, "7FG49QCJ+2VXGJ"),
+		Entry("Zurich", 47.0000625, 8.0000625, 10, "8FVC2222+22"),
+		Entry("Wellington", -41.2730625, 174.7859375, 10, "4VCPPQGP+Q9"),
+		Entry("equator", 0.5, -179.5, 4, "62G20000+"),
+		Entry("south-west", -89.5, -179.5, 4, "22220000+"),
+		Entry("south-west corner", -89.9999375, -179.9999375, 10, "22222222+22"),
+		Entry("east", 0.5, 179.5, 4, "6VGX0000+"),
+		Entry("grid", 1.0, 1.0, 11, "6FH32222+222"),
+		Entry("north pole", 90.0, 1.0, 4, "CFX30000+"),
+		Entry("north pole, precise", 90.0, 1.0, 10, "CFX3X2X2+X2"),
+		Entry("beyond north pole", 92.0, 1.0, 4, "CFX30000+"),
+		Entry("antimeridian", 1.0, 180.0, 4, "62H20000+"),
+		Entry("beyond antimeridian", 1.0, 181.0, 4, "62H30000+"),
+	)
+
+	It("should reject invalid lengths", func() {
+		for _, n := range []int{0, 1, 3, 9, 16} {
+			_, err := EncodePlusCode(47, 8, n)
+			Expect(err).To(MatchError(errInvalidPlusCode), "length %d", n)
+		}
+	})
+
+	It("should reject invalid coordinates", func() {
+		for _, pt := range []Point{{math.NaN(), 8}, {47, math.NaN()}, {math.Inf(1), 8}, {47, math.Inf(-1)}} {
+			_, err := EncodePlusCode(pt.Lat, pt.Lon, 10)
+			Expect(err).To(MatchError(errInvalidPlusCode), "point %v", pt)
+		}
+	})
+
+	It("should clip and normalise huge coordinates", func() {
+		for _, tc := range []struct{ lat, lon, expLat, expLon float64 }{
+			{1e20, 1, 90, 1},
+			{-1e20, 1, -90, 1},
+			{math.MaxFloat64, 1, 90, 1},
+			{1, 181 + 360*1e6, 1, -179},
+			{1, 1 - 360*1e6, 1, 1},
+			{1, math.MaxFloat64, 1, 128},
+			{1, -math.MaxFloat64, 1, -128},
+		} {
+			exp, err := EncodePlusCode(tc.expLat, tc.expLon, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(EncodePlusCode(tc.lat, tc.lon, 10)).To(Equal(exp), "%v, %v", tc.lat, tc.lon)
+		}
+	})
+
+	DescribeTable("should decode",
+		func(code string, exp Area) {
+			a, err := DecodePlusCode(code)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.MinLat).To(BeNumerically("~", exp.MinLat, 1e-10))
+			Expect(a.MaxLat).To(BeNumerically("~", exp.MaxLat, 1e-10))
+			Expect(a.MinLon).To(BeNumerically("~", exp.MinLon, 1e-10))
+			Expect(a.MaxLon).To(BeNumerically("~", exp.MaxLon, 1e-10))
+		},
+		Entry("6 digits", "7FG49Q00+", Area{MinLat: 20.35, MaxLat: 20.4, MinLon: 2.75, MaxLon: 2.8}),
+		Entry("10 digits", "7FG49QCJ+2V", Area{MinLat: 20.37, MaxLat: 20.370125, MinLon: 2.782125, MaxLon: 2.78225}),
+		Entry("11 digits", "7FG49QCJ+2VX", Area{MinLat: 20.3701, MaxLat: 20.370125, MinLon: 2.78221875, MaxLon: 2.78225}),
+		Entry("13 digits", "7FG49QCJ+2VXGJ", Area{MinLat: 20.370113, MaxLat: 20.370114, MinLon: 2.782234375, MaxLon: 2.78223632812}),
+		Entry("lower case", "8fvc2222+22", Area{MinLat: 47, MaxLat: 47.000125, MinLon: 8, MaxLon: 8.000125}),
+		Entry("southern", "4VCPPQGP+Q9", Area{MinLat: -41.273125, MaxLat: -41.273, MinLon: 174.785875, MaxLon: 174.786}),
+		Entry("2 digits", "62000000+", Area{MinLat: -10, MaxLat: 10, MinLon: -180, MaxLon: -160}),
+		Entry("north pole", "CFX3X2X2+X2", Area{MinLat: 89.999875, MaxLat: 90, MinLon: 1, MaxLon: 1.000125}),
+	)
+
+	It("should reject invalid codes", func() {
+		for _, code := range []string{
+			"",
+			"8FVC9G8F6X",   // no separator
+			"8FVC9G8+F6X",  // separator at the wrong position
+			"8FVC9G8F++6X", // multiple separators
+			"8FVC9G8F+6",   // single digit after separator
+			"8FVC0000+6X",  // digits after padding
+			"8FV00000+",    // padding at an odd position
+			"8F00C000+",    // interrupted padding
+			"00000000+",    // padding only
+			"8FVC9G8A+6X",  // invalid character
+			"F2000000+",    // latitude out of range
+			"2X000000+",    // longitude out of range
+			"9G8F+6X",      // short code
+		} {
+			_, err := DecodePlusCode(code)
+			Expect(err).To(MatchError(errInvalidPlusCode), code)
+		}
+	})
+
+	It("should round-trip", func() {
+		for _, n := range []int{2, 4, 6, 8, 10, 11, 12, 13, 14, 15} {
+			code, err := EncodePlusCode(51.5074, -0.1278, n)
+			Expect(err).NotTo(HaveOccurred())
+
+			a, err := DecodePlusCode(code)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Contains(51.5074, -0.1278)).To(BeTrue(), code)
+			Expect(a.PlusCode()).To(Equal(code))
+		}
+	})
+
+	It("should cover codes with hashes", func() {
+		hashes, err := CoverPlusCode("9C3XGV00+")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(hashes)).To(BeNumerically("<=", 16))
+		Expect(len(hashes)).To(BeNumerically(">", 1))
+
+		a, _ := DecodePlusCode("9C3XGV00+")
+		for _, h := range hashes {
+			Expect(h.Precision()).To(Equal(hashes[0].Precision()))
+			Expect(h.Decode().Relate(a)).NotTo(Equal(Outside))
+		}
+		Expect(Area{
+			MinLat: hashes[0].Decode().MinLat,
+			MaxLat: hashes[len(hashes)-1].Decode().MaxLat,
+			MinLon: hashes[0].Decode().MinLon,
+			MaxLon: hashes[len(hashes)-1].Decode().MaxLon,
+		}.Relate(a)).NotTo(Equal(Outside))
+
+		_, err = CoverPlusCode("9C3XGV")
+		Expect(err).To(MatchError(errInvalidPlusCode))
+	})
+
+	It("should clip codes at the latitude limits", func() {
+		hashes, err := CoverPlusCode("CF000000+")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hashes).NotTo(BeEmpty())
+		for _, h := range hashes {
+			Expect(h.Decode().MaxLat).To(BeNumerically(">", 70))
+		}
+
+		_, err = CoverPlusCode("CFX30000+")
+		Expect(err).To(MatchError(errInvalidArea))
+	})
+
+	It("should find enclosing codes", func() {
+		h := EncodeWithPrecision(51.5074, -0.1278, 20)
+		code, err := h.PlusCode()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code).To(Equal("9C3XGV4C+"))
+
+		a, _ := DecodePlusCode(code)
+		b := h.Decode()
+		Expect(a.Contains(b.MinLat, b.MinLon)).To(BeTrue())
+		Expect(a.Contains(b.MaxLat, b.MaxLon)).To(BeTrue())
+
+		longer, _ := EncodePlusCode(b.MinLat, b.MinLon, 10)
+		a, _ = DecodePlusCode(longer)
+		Expect(a.Contains(b.MaxLat, b.MaxLon)).To(BeFalse())
+
+		Expect(Area{MinLat: 47.0000625, MaxLat: 47.0000625, MinLon: 8.0000625, MaxLon: 8.0000625}.PlusCode()).To(Equal("8FVC2222+22GCCCC"))
+		Expect(Area{MinLat: 47, MaxLat: 47.0001, MinLon: 8, MaxLon: 8.0001}.PlusCode()).To(Equal("8FVC2222+22"))
+		Expect(Area{MinLat: -1, MaxLat: 1, MinLon: 0, MaxLon: 1}.PlusCode()).To(Equal("6F000000+"))
+
+		_, err = Area{MinLat: 9, MaxLat: 11, MinLon: 0, MaxLon: 1}.PlusCode()
+		Expect(err).To(MatchError(errInvalidArea))
+		_, err = Area{MinLat: 1, MaxLat: 0, MinLon: 0, MaxLon: 1}.PlusCode()
+		Expect(err).To(MatchError(errInvalidArea))
+	})
+})
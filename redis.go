@@ -0,0 +1,84 @@
+package geohashi
+
+import (
+	"math"
+	"strings"
+)
+
+// Score returns the Redis GEO score of the hash, i.e. its 52-bit interleaved
+// value at maximum precision. Hashes with lower precisions return the score
+// of their south-west corner.
+func (h Hash) Score() float64 {
+	return float64(h.base() << (2 * (PrecisionMax - h.Precision())))
+}
+
+// FromScore converts a Redis GEO score into a hash with maximum precision.
+func FromScore(score float64) (Hash, error) {
+	if score < 0 || score >= 1<<(2*PrecisionMax) || score != math.Trunc(score) {
+		return 0, errInvalidHash
+	}
+	return newHash(uint64(score), PrecisionMax), nil
+}
+
+// geohashAlphabet is the base32 alphabet of standard geohash strings.
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// RedisGeohash returns the 11 character geohash string, exactly as returned
+// by the Redis GEOHASH command. Redis re-encodes the centre of the cell using
+// the standard latitude range of -90..90, the resulting string therefore
+// does not directly correspond to the hash or its score. The final character
+// only contains padding and is always '0'.
+func (h Hash) RedisGeohash() string {
+	lat, lon := h.Decode().Center()
+	x := gridIndex((lat+90)/180, PrecisionMax)
+	y := gridIndex((lon+180)/360, PrecisionMax)
+	bits := interleave64(x, y)
+
+	buf := make([]byte, 11)
+	for i := 0; i < 10; i++ {
+		buf[i] = geohashAlphabet[bits>>(2*PrecisionMax-5*(i+1))&0x1f]
+	}
+	buf[10] = geohashAlphabet[0]
+	return string(buf)
+}
+
+// FromRedisGeohash converts a geohash string, as returned by the Redis GEOHASH
+// command, back into a hash. Since the string encodes a cell in a different
+// grid, the result is only an approximation: the hash of the cell centre at
+// the highest precision supported by the string's length.
+func FromRedisGeohash(s string) (Hash, error) {
+	if len(s) == 0 || len(s) > 11 {
+		return 0, errInvalidHash
+	}
+
+	var bits uint64
+	for i := 0; i < len(s); i++ {
+		n := strings.IndexByte(geohashAlphabet, s[i])
+		if n < 0 {
+			return 0, errInvalidHash
+		}
+		bits = bits<<5 | uint64(n)
+	}
+
+	// ignore padding beyond 52 bits, bits alternate between lon and lat
+	size := 5 * len(s)
+	if size > 2*PrecisionMax {
+		bits >>= size - 2*PrecisionMax
+		size = 2 * PrecisionMax
+	}
+
+	var lat, lon uint64
+	for i := 0; i < size; i++ {
+		bit := bits >> (size - 1 - i) & 1
+		if i%2 == 0 {
+			lon = lon<<1 | bit
+		} else {
+			lat = lat<<1 | bit
+		}
+	}
+
+	latN, lonN := size/2, size-size/2
+	φ := -90 + (float64(lat)+0.5)*180/float64(uint64(1)<<latN)
+	λ := -180 + (float64(lon)+0.5)*360/float64(uint64(1)<<lonN)
+	return encode(φ, λ, uint8(latN)), nil
+}
@@ -0,0 +1,61 @@
+package geohashi
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Redis", func() {
+	// GEOADD Sicily 13.361389 38.115556 "Palermo" 15.087269 37.502669 "Catania"
+	palermo := Encode(38.115556, 13.361389)
+	catania := Encode(37.502669, 15.087269)
+
+	It("should calculate scores", func() {
+		Expect(palermo.Score()).To(Equal(3479099956230698.0))
+		Expect(catania.Score()).To(Equal(3479447370796909.0))
+		Expect(palermo.Parent().Score()).To(Equal(3479099956230696.0))
+		Expect(EncodeWithPrecision(LatMin, LonMin, 1).Score()).To(Equal(0.0))
+	})
+
+	It("should convert from scores", func() {
+		Expect(FromScore(3479099956230698)).To(Equal(palermo))
+		Expect(FromScore(0)).To(Equal(newHash(0, PrecisionMax)))
+
+		for _, score := range []float64{-1, 1 << 52, 0.5} {
+			_, err := FromScore(score)
+			Expect(err).To(MatchError(errInvalidHash))
+		}
+	})
+
+	It("should generate GEOHASH strings", func() {
+		Expect(palermo.RedisGeohash()).To(Equal("sqc8b49rny0"))
+		Expect(catania.RedisGeohash()).To(Equal("sqdtr74hyu0"))
+		Expect(Encode(42.6, -5.6).RedisGeohash()).To(Equal("ezs42e44yx0"))
+		Expect(Encode(0, 0).RedisGeohash()).To(Equal("s0000000000"))
+		Expect(Encode(LatMin, LonMin).RedisGeohash()).To(Equal("00bh0hbj200"))
+	})
+
+	It("should convert from GEOHASH strings", func() {
+		for _, h := range []Hash{palermo, catania, Encode(42.6, -5.6), Encode(-33.865143, 151.2099)} {
+			hash, err := FromRedisGeohash(h.RedisGeohash())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hash.Precision()).To(Equal(uint8(PrecisionMax)))
+
+			lat1, lon1 := h.Decode().Center()
+			lat2, lon2 := hash.Decode().Center()
+			Expect(Distance(lat1, lon1, lat2, lon2)).To(BeNumerically("<", 1))
+		}
+
+		hash, err := FromRedisGeohash("sqc8b")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hash.Precision()).To(Equal(uint8(12)))
+		lat, lon := hash.Decode().Center()
+		Expect(lat).To(BeNumerically("~", 38.115556, 0.05))
+		Expect(lon).To(BeNumerically("~", 13.361389, 0.05))
+
+		for _, s := range []string{"", "sqc8b49rny0s", "sqa", "SQC"} {
+			_, err := FromRedisGeohash(s)
+			Expect(err).To(MatchError(errInvalidHash), s)
+		}
+	})
+})
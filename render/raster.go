@@ -0,0 +1,183 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+)
+
+// Image renders all shapes onto a white background. Shapes are filled
+// semi-transparently and outlined with their opaque color.
+func (c *Canvas) Image() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, c.Width, c.Height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	project := c.project()
+	for _, s := range c.shapes {
+		pts := make([][2]float64, 0, len(s.ring))
+		for _, pt := range s.ring {
+			x, y := project(pt)
+			pts = append(pts, [2]float64{x, y})
+		}
+
+		if s.point {
+			fillDisc(img, pts[0], c.pointRadius(), s.color)
+			continue
+		}
+
+		fill := s.color
+		fill.A = fillAlpha
+		fillPolygon(img, pts, premultiply(fill))
+		for i := range pts {
+			drawLine(img, pts[i], pts[(i+1)%len(pts)], s.color)
+		}
+	}
+	return img
+}
+
+// WritePNG renders all shapes as PNG.
+func (c *Canvas) WritePNG(w io.Writer) error {
+	return png.Encode(w, c.Image())
+}
+
+// premultiply converts a non-premultiplied color into a color.RGBA, as
+// expected by the image package.
+func premultiply(c color.RGBA) color.RGBA {
+	a := uint16(c.A)
+	return color.RGBA{
+		R: uint8(uint16(c.R) * a / 0xff),
+		G: uint8(uint16(c.G) * a / 0xff),
+		B: uint8(uint16(c.B) * a / 0xff),
+		A: c.A,
+	}
+}
+
+// fillPolygon fills all pixels with centres inside the polygon, using the
+// even-odd rule.
+func fillPolygon(img *image.RGBA, pts [][2]float64, col color.RGBA) {
+	src := image.NewUniform(col)
+	bounds := img.Bounds()
+
+	var xs []float64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		cy := float64(y) + 0.5
+
+		xs = xs[:0]
+		for i, j := 0, len(pts)-1; i < len(pts); j, i = i, i+1 {
+			a, b := pts[i], pts[j]
+			if (a[1] > cy) != (b[1] > cy) {
+				xs = append(xs, a[0]+(cy-a[1])*(b[0]-a[0])/(b[1]-a[1]))
+			}
+		}
+		sort.Float64s(xs)
+
+		for i := 0; i+1 < len(xs); i += 2 {
+			x0 := clampInt(int(math.Ceil(xs[i]-0.5)), bounds.Min.X, bounds.Max.X)
+			x1 := clampInt(int(math.Ceil(xs[i+1]-0.5)), bounds.Min.X, bounds.Max.X)
+			if x0 < x1 {
+				draw.Draw(img, image.Rect(x0, y, x1, y+1), src, image.Point{}, draw.Over)
+			}
+		}
+	}
+}
+
+// drawLine draws a line between two points. Lines on the right and bottom
+// edges of the image are moved inwards by one pixel.
+func drawLine(img *image.RGBA, a, b [2]float64, col color.RGBA) {
+	a, b, ok := clipLine(a, b, img.Bounds())
+	if !ok {
+		return
+	}
+
+	steps := int(math.Ceil(math.Max(math.Abs(b[0]-a[0]), math.Abs(b[1]-a[1]))))
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps != 0 {
+			t = float64(i) / float64(steps)
+		}
+		x := a[0] + t*(b[0]-a[0])
+		y := a[1] + t*(b[1]-a[1])
+		setPixel(img, x, y, col)
+	}
+}
+
+// clipLine clips a line to the bounds, including the right and bottom edges,
+// using the Liang–Barsky algorithm. It returns false if the line lies
+// entirely outside.
+func clipLine(a, b [2]float64, bounds image.Rectangle) (_, _ [2]float64, ok bool) {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	t0, t1 := 0.0, 1.0
+	for _, e := range [4][2]float64{
+		{-dx, a[0] - float64(bounds.Min.X)},
+		{dx, float64(bounds.Max.X) - a[0]},
+		{-dy, a[1] - float64(bounds.Min.Y)},
+		{dy, float64(bounds.Max.Y) - a[1]},
+	} {
+		p, q := e[0], e[1]
+		if math.IsNaN(p) || math.IsNaN(q) {
+			return a, b, false
+		}
+
+		if p == 0 {
+			if q < 0 {
+				return a, b, false
+			}
+			continue
+		}
+
+		r := q / p
+		if p < 0 {
+			if r > t1 {
+				return a, b, false
+			} else if r > t0 {
+				t0 = r
+			}
+		} else {
+			if r < t0 {
+				return a, b, false
+			} else if r < t1 {
+				t1 = r
+			}
+		}
+	}
+
+	return [2]float64{a[0] + t0*dx, a[1] + t0*dy}, [2]float64{a[0] + t1*dx, a[1] + t1*dy}, true
+}
+
+// fillDisc draws a filled circle.
+func fillDisc(img *image.RGBA, c [2]float64, r float64, col color.RGBA) {
+	for y := math.Floor(c[1] - r); y <= c[1]+r; y++ {
+		for x := math.Floor(c[0] - r); x <= c[0]+r; x++ {
+			if dx, dy := x+0.5-c[0], y+0.5-c[1]; dx*dx+dy*dy <= r*r {
+				setPixel(img, x, y, col)
+			}
+		}
+	}
+}
+
+func setPixel(img *image.RGBA, x, y float64, col color.RGBA) {
+	bounds := img.Bounds()
+	px, py := int(math.Floor(x)), int(math.Floor(y))
+	if px == bounds.Max.X && x == float64(px) {
+		px--
+	}
+	if py == bounds.Max.Y && y == float64(py) {
+		py--
+	}
+	if image.Pt(px, py).In(bounds) {
+		img.SetRGBA(px, py, col)
+	}
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	} else if v > max {
+		return max
+	}
+	return v
+}
@@ -0,0 +1,80 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+
+	"github.com/bsm/geohashi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Raster", func() {
+	var subject *Canvas
+
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	blue := color.RGBA{B: 0xff, A: 0xff}
+
+	BeforeEach(func() {
+		subject = NewCanvas(180, 90, viewport)
+		subject.Area(geohashi.Area{MinLat: 0, MaxLat: 10, MinLon: 0, MaxLon: 20}, red)
+		subject.Area(geohashi.Area{MinLat: -45, MaxLat: 45, MinLon: 80, MaxLon: 90}, blue)
+		subject.Point(blue, geohashi.Point{Lat: -20, Lon: -50})
+	})
+
+	It("should render images", func() {
+		img := subject.Image()
+		Expect(img.Bounds().Dx()).To(Equal(180))
+		Expect(img.Bounds().Dy()).To(Equal(90))
+
+		// background
+		Expect(img.RGBAAt(10, 10)).To(Equal(white))
+
+		// fill
+		Expect(img.RGBAAt(100, 40)).To(Equal(color.RGBA{R: 0xff, G: 0xbf, B: 0xbf, A: 0xff}))
+
+		// outline
+		Expect(img.RGBAAt(90, 40)).To(Equal(red))
+		Expect(img.RGBAAt(100, 35)).To(Equal(red))
+		Expect(img.RGBAAt(110, 40)).To(Equal(red))
+
+		// outlines on the image edges are moved inwards
+		Expect(img.RGBAAt(179, 20)).To(Equal(blue))
+		Expect(img.RGBAAt(175, 89)).To(Equal(blue))
+
+		// points
+		Expect(img.RGBAAt(40, 65)).To(Equal(blue))
+		Expect(img.RGBAAt(40, 68)).To(Equal(white))
+	})
+
+	It("should clip lines", func() {
+		img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+		drawLine(img, [2]float64{-1e15, 5}, [2]float64{1e15, 5}, blue)
+		drawLine(img, [2]float64{-1e15, -1e15}, [2]float64{1e15, -1e15}, red)
+		drawLine(img, [2]float64{25, -1e15}, [2]float64{25, 1e15}, red)
+
+		for x := 0; x < 20; x++ {
+			Expect(img.RGBAAt(x, 5)).To(Equal(blue))
+			Expect(img.RGBAAt(x, 4)).To(Equal(color.RGBA{}))
+		}
+
+		_, _, ok := clipLine([2]float64{0, 0}, [2]float64{math.NaN(), 5}, img.Bounds())
+		Expect(ok).To(BeFalse())
+		a, b, ok := clipLine([2]float64{-10, -5}, [2]float64{30, 15}, img.Bounds())
+		Expect(ok).To(BeTrue())
+		Expect(a).To(Equal([2]float64{0, 0}))
+		Expect(b).To(Equal([2]float64{20, 10}))
+	})
+
+	It("should write PNGs", func() {
+		buf := new(bytes.Buffer)
+		Expect(subject.WritePNG(buf)).To(Succeed())
+
+		img, err := png.Decode(buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(color.RGBAModel.Convert(img.At(90, 40))).To(Equal(red))
+	})
+})
@@ -0,0 +1,152 @@
+// Package render draws hashes, areas, points and polygons onto SVG and PNG
+// images, mostly for debugging and reviewing coverings.
+package render
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/bsm/geohashi"
+)
+
+// Projection projects coordinates onto a plane, with x increasing eastwards
+// and y increasing southwards.
+type Projection interface {
+	Project(lat, lon float64) (x, y float64)
+}
+
+// ProjectionFunc is a function which implements Projection.
+type ProjectionFunc func(lat, lon float64) (x, y float64)
+
+// Project implements Projection.
+func (f ProjectionFunc) Project(lat, lon float64) (x, y float64) { return f(lat, lon) }
+
+var (
+	// Equirectangular projects coordinates linearly, matching the grid of
+	// hashes.
+	Equirectangular Projection = ProjectionFunc(func(lat, lon float64) (float64, float64) {
+		return lon, -lat
+	})
+
+	// Mercator is the Web Mercator projection, matching slippy map tiles.
+	Mercator Projection = ProjectionFunc(func(lat, lon float64) (float64, float64) {
+		φ := lat * math.Pi / 180
+		return lon * math.Pi / 180, -math.Log(math.Tan(math.Pi/4 + φ/2))
+	})
+)
+
+// Palette returns the color for cells of a precision.
+type Palette func(prec uint8) color.RGBA
+
+// DefaultPalette cycles through eight distinct colors.
+func DefaultPalette(prec uint8) color.RGBA {
+	return defaultColors[int(prec)%len(defaultColors)]
+}
+
+var defaultColors = []color.RGBA{
+	{R: 0xe4, G: 0x1a, B: 0x1c, A: 0xff},
+	{R: 0x37, G: 0x7e, B: 0xb8, A: 0xff},
+	{R: 0x4d, G: 0xaf, B: 0x4a, A: 0xff},
+	{R: 0x98, G: 0x4e, B: 0xa3, A: 0xff},
+	{R: 0xff, G: 0x7f, B: 0x00, A: 0xff},
+	{R: 0xa6, G: 0x56, B: 0x28, A: 0xff},
+	{R: 0xf7, G: 0x81, B: 0xbf, A: 0xff},
+	{R: 0x99, G: 0x99, B: 0x99, A: 0xff},
+}
+
+// fillAlpha is the opacity of shape fills.
+const fillAlpha = 0x40
+
+// --------------------------------------------------------------------
+
+// Canvas collects shapes to be rendered onto a viewport.
+type Canvas struct {
+	// Width and Height of the rendered image in pixels.
+	Width, Height int
+	// Viewport is the area to be rendered.
+	Viewport geohashi.Area
+	// Projection is used to project coordinates, default: Equirectangular.
+	Projection Projection
+	// Palette determines the colors of hashes, default: DefaultPalette.
+	Palette Palette
+	// PointRadius is the radius of points in pixels, default: 2.
+	PointRadius float64
+
+	shapes []shape
+}
+
+// NewCanvas inits a new canvas.
+func NewCanvas(width, height int, viewport geohashi.Area) *Canvas {
+	return &Canvas{Width: width, Height: height, Viewport: viewport}
+}
+
+// Hash adds hash cells, colored by precision.
+func (c *Canvas) Hash(hashes ...geohashi.Hash) {
+	palette := c.Palette
+	if palette == nil {
+		palette = DefaultPalette
+	}
+	for _, h := range hashes {
+		a := h.Decode()
+		c.shapes = append(c.shapes, shape{ring: areaRing(a), color: palette(h.Precision())})
+	}
+}
+
+// Area adds an area.
+func (c *Canvas) Area(a geohashi.Area, col color.RGBA) {
+	c.shapes = append(c.shapes, shape{ring: areaRing(a), color: col})
+}
+
+// Polygon adds a polygon.
+func (c *Canvas) Polygon(p geohashi.Polygon, col color.RGBA) {
+	c.shapes = append(c.shapes, shape{ring: p, color: col})
+}
+
+// Point adds points.
+func (c *Canvas) Point(col color.RGBA, points ...geohashi.Point) {
+	for _, pt := range points {
+		c.shapes = append(c.shapes, shape{ring: []geohashi.Point{pt}, color: col, point: true})
+	}
+}
+
+// Reset removes all shapes.
+func (c *Canvas) Reset() { c.shapes = c.shapes[:0] }
+
+// project returns a function which converts coordinates into pixel
+// coordinates.
+func (c *Canvas) project() func(geohashi.Point) (x, y float64) {
+	proj := c.Projection
+	if proj == nil {
+		proj = Equirectangular
+	}
+
+	x0, y0 := proj.Project(c.Viewport.MaxLat, c.Viewport.MinLon)
+	x1, y1 := proj.Project(c.Viewport.MinLat, c.Viewport.MaxLon)
+	sx, sy := float64(c.Width)/(x1-x0), float64(c.Height)/(y1-y0)
+	return func(pt geohashi.Point) (float64, float64) {
+		x, y := proj.Project(pt.Lat, pt.Lon)
+		return (x - x0) * sx, (y - y0) * sy
+	}
+}
+
+func (c *Canvas) pointRadius() float64 {
+	if c.PointRadius > 0 {
+		return c.PointRadius
+	}
+	return 2
+}
+
+type shape struct {
+	ring  []geohashi.Point
+	color color.RGBA
+	point bool
+}
+
+func areaRing(a geohashi.Area) []geohashi.Point {
+	return []geohashi.Point{
+		{Lat: a.MinLat, Lon: a.MinLon},
+		{Lat: a.MinLat, Lon: a.MaxLon},
+		{Lat: a.MaxLat, Lon: a.MaxLon},
+		{Lat: a.MaxLat, Lon: a.MinLon},
+	}
+}
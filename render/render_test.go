@@ -0,0 +1,63 @@
+package render
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/bsm/geohashi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	red      = color.RGBA{R: 0xff, A: 0xff}
+	viewport = geohashi.Area{MinLat: -45, MaxLat: 45, MinLon: -90, MaxLon: 90}
+)
+
+var _ = Describe("Canvas", func() {
+	It("should project", func() {
+		subject := NewCanvas(180, 90, viewport)
+		project := subject.project()
+		x, y := project(geohashi.Point{Lat: 45, Lon: -90})
+		Expect([]float64{x, y}).To(Equal([]float64{0, 0}))
+		x, y = project(geohashi.Point{Lat: -45, Lon: 90})
+		Expect([]float64{x, y}).To(Equal([]float64{180, 90}))
+		x, y = project(geohashi.Point{Lat: 22.5, Lon: 45})
+		Expect([]float64{x, y}).To(Equal([]float64{135, 22.5}))
+	})
+
+	It("should project with Mercator", func() {
+		subject := NewCanvas(180, 90, viewport)
+		subject.Projection = Mercator
+		project := subject.project()
+
+		x, y := project(geohashi.Point{Lat: 0, Lon: 0})
+		Expect(x).To(BeNumerically("~", 90, 1e-9))
+		Expect(y).To(BeNumerically("~", 45, 1e-9))
+
+		// latitudes are stretched towards the poles
+		_, y = project(geohashi.Point{Lat: 22.5, Lon: 0})
+		Expect(y).To(BeNumerically("~", 24.414, 1e-3))
+	})
+
+	It("should use palettes", func() {
+		Expect(DefaultPalette(1)).To(Equal(color.RGBA{R: 0x37, G: 0x7e, B: 0xb8, A: 0xff}))
+		Expect(DefaultPalette(9)).To(Equal(DefaultPalette(1)))
+
+		subject := NewCanvas(180, 90, viewport)
+		subject.Palette = func(uint8) color.RGBA { return red }
+		subject.Hash(geohashi.EncodeWithPrecision(1, 1, 5))
+		Expect(subject.shapes).To(HaveLen(1))
+		Expect(subject.shapes[0].color).To(Equal(red))
+
+		subject.Reset()
+		Expect(subject.shapes).To(BeEmpty())
+	})
+})
+
+// --------------------------------------------------------------------
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "github.com/bsm/geohashi/render")
+}
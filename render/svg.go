@@ -0,0 +1,50 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+)
+
+// WriteSVG renders all shapes as SVG. Output is deterministic and therefore
+// suitable for golden file tests.
+func (c *Canvas) WriteSVG(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	project := c.project()
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		c.Width, c.Height, c.Width, c.Height)
+	for _, s := range c.shapes {
+		if s.point {
+			x, y := project(s.ring[0])
+			fmt.Fprintf(bw, `<circle cx="%s" cy="%s" r="%s" fill="%s"/>`+"\n",
+				svgFloat(x), svgFloat(y), svgFloat(c.pointRadius()), svgColor(s.color))
+			continue
+		}
+
+		bw.WriteString(`<polygon points="`)
+		for i, pt := range s.ring {
+			if i != 0 {
+				bw.WriteByte(' ')
+			}
+			x, y := project(pt)
+			bw.WriteString(svgFloat(x))
+			bw.WriteByte(',')
+			bw.WriteString(svgFloat(y))
+		}
+		fmt.Fprintf(bw, `" fill="%s" fill-opacity="%s" stroke="%s"/>`+"\n",
+			svgColor(s.color), svgFloat(float64(fillAlpha)/0xff), svgColor(s.color))
+	}
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
+
+func svgFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,29 @@
+package render
+
+import (
+	"bytes"
+
+	"github.com/bsm/geohashi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SVG", func() {
+	It("should render", func() {
+		subject := NewCanvas(180, 90, viewport)
+		subject.Area(geohashi.Area{MinLat: 0, MaxLat: 10, MinLon: 0, MaxLon: 20}, red)
+		subject.Hash(geohashi.EncodeWithPrecision(-10, -10, 3))
+		subject.Polygon(geohashi.Polygon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 10}, {Lat: 10, Lon: 0}}, red)
+		subject.Point(red, geohashi.Point{Lat: 1, Lon: 2})
+
+		buf := new(bytes.Buffer)
+		Expect(subject.WriteSVG(buf)).To(Succeed())
+		Expect(buf.String()).To(Equal(`<svg xmlns="http://www.w3.org/2000/svg" width="180" height="90" viewBox="0 0 180 90">
+<polygon points="90.00,45.00 110.00,45.00 110.00,35.00 90.00,35.00" fill="#ff0000" fill-opacity="0.25" stroke="#ff0000"/>
+<polygon points="45.00,66.26 90.00,66.26 90.00,45.00 45.00,45.00" fill="#984ea3" fill-opacity="0.25" stroke="#984ea3"/>
+<polygon points="90.00,45.00 100.00,45.00 90.00,35.00" fill="#ff0000" fill-opacity="0.25" stroke="#ff0000"/>
+<circle cx="92.00" cy="44.00" r="2.00" fill="#ff0000"/>
+</svg>
+`))
+	})
+})
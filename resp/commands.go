@@ -0,0 +1,263 @@
+package resp
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bsm/geohashi"
+)
+
+type command struct {
+	arity int  // minimum number of arguments, including the command name
+	write bool // command modifies data
+	fn    func(*Server, []string) interface{}
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"PING":     {1, false, cmdPing},
+		"ECHO":     {2, false, cmdEcho},
+		"SELECT":   {2, false, cmdSelect},
+		"COMMAND":  {1, false, cmdCommand},
+		"CLIENT":   {2, false, cmdClient},
+		"TYPE":     {2, false, cmdType},
+		"EXISTS":   {2, false, cmdExists},
+		"DEL":      {2, true, cmdDel},
+		"FLUSHDB":  {1, true, cmdFlush},
+		"FLUSHALL": {1, true, cmdFlush},
+
+		"ZCARD":  {2, false, cmdZCard},
+		"ZSCORE": {3, false, cmdZScore},
+		"ZRANGE": {4, false, cmdZRange},
+		"ZREM":   {3, true, cmdZRem},
+
+		"GEOADD":            {5, true, cmdGeoAdd},
+		"GEOPOS":            {2, false, cmdGeoPos},
+		"GEODIST":           {4, false, cmdGeoDist},
+		"GEOHASH":           {2, false, cmdGeoHash},
+		"GEORADIUS":         {6, true, cmdGeoRadius},
+		"GEORADIUSBYMEMBER": {5, true, cmdGeoRadiusByMember},
+		"GEOSEARCH":         {7, false, cmdGeoSearch},
+		"GEOSEARCHSTORE":    {8, true, cmdGeoSearchStore},
+	}
+}
+
+// --------------------------------------------------------------------
+
+func cmdPing(_ *Server, args []string) interface{} {
+	switch len(args) {
+	case 0:
+		return simpleString("PONG")
+	case 1:
+		return bulkString(args[0])
+	}
+	return errors.New("ERR wrong number of arguments for 'ping' command")
+}
+
+func cmdEcho(_ *Server, args []string) interface{} {
+	return bulkString(args[0])
+}
+
+func cmdSelect(_ *Server, args []string) interface{} {
+	if args[0] != "0" {
+		return errors.New("ERR DB index is out of range")
+	}
+	return simpleString("OK")
+}
+
+func cmdCommand(_ *Server, _ []string) interface{} {
+	return []interface{}{}
+}
+
+func cmdClient(_ *Server, _ []string) interface{} {
+	return simpleString("OK")
+}
+
+func cmdType(s *Server, args []string) interface{} {
+	if _, ok := s.keys[args[0]]; ok {
+		return simpleString("zset")
+	}
+	return simpleString("none")
+}
+
+func cmdExists(s *Server, args []string) interface{} {
+	n := 0
+	for _, key := range args {
+		if _, ok := s.keys[key]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+func cmdDel(s *Server, args []string) interface{} {
+	n := 0
+	for _, key := range args {
+		if _, ok := s.keys[key]; ok {
+			delete(s.keys, key)
+			n++
+		}
+	}
+	return n
+}
+
+func cmdFlush(s *Server, _ []string) interface{} {
+	s.keys = make(map[string]interface{})
+	return simpleString("OK")
+}
+
+// --------------------------------------------------------------------
+
+// zmember is a member of a sorted set.
+type zmember struct {
+	name  string
+	score float64
+}
+
+// zset returns the members of the sorted set stored at key, ordered by score
+// and name.
+func (s *Server) zset(key string) ([]zmember, error) {
+	var res []zmember
+	switch v := s.keys[key].(type) {
+	case nil:
+		return nil, nil
+	case *geoSet:
+		res = make([]zmember, 0, v.Len())
+		v.Iterate(func(it geohashi.Item[string]) bool {
+			res = append(res, zmember{name: it.Value, score: geohashi.Encode(it.Lat, it.Lon).Score()})
+			return true
+		})
+	case distSet:
+		res = make([]zmember, 0, len(v))
+		for name, score := range v {
+			res = append(res, zmember{name: name, score: score})
+		}
+	default:
+		return nil, errWrongType
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].score == res[j].score {
+			return res[i].name < res[j].name
+		}
+		return res[i].score < res[j].score
+	})
+	return res, nil
+}
+
+func cmdZCard(s *Server, args []string) interface{} {
+	switch v := s.keys[args[0]].(type) {
+	case nil:
+		return 0
+	case *geoSet:
+		return v.Len()
+	case distSet:
+		return len(v)
+	}
+	return errWrongType
+}
+
+func cmdZScore(s *Server, args []string) interface{} {
+	switch v := s.keys[args[0]].(type) {
+	case nil:
+		return nil
+	case *geoSet:
+		if lat, lon, ok := v.Get(args[1]); ok {
+			return formatFloat(geohashi.Encode(lat, lon).Score())
+		}
+		return nil
+	case distSet:
+		if score, ok := v[args[1]]; ok {
+			return formatFloat(score)
+		}
+		return nil
+	}
+	return errWrongType
+}
+
+func cmdZRange(s *Server, args []string) interface{} {
+	start, err := strconv.Atoi(args[1])
+	if err != nil {
+		return errNotInt
+	}
+	stop, err := strconv.Atoi(args[2])
+	if err != nil {
+		return errNotInt
+	}
+
+	withScores := false
+	for _, opt := range args[3:] {
+		if !strings.EqualFold(opt, "WITHSCORES") {
+			return errSyntax
+		}
+		withScores = true
+	}
+
+	members, err := s.zset(args[0])
+	if err != nil {
+		return err
+	}
+
+	n := len(members)
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+
+	res := []interface{}{}
+	for i := start; i <= stop; i++ {
+		res = append(res, bulkString(members[i].name))
+		if withScores {
+			res = append(res, formatFloat(members[i].score))
+		}
+	}
+	return res
+}
+
+func cmdZRem(s *Server, args []string) interface{} {
+	key := args[0]
+
+	n := 0
+	switch v := s.keys[key].(type) {
+	case nil:
+		return 0
+	case *geoSet:
+		for _, name := range args[1:] {
+			if v.Delete(name) {
+				n++
+			}
+		}
+		if v.Len() == 0 {
+			delete(s.keys, key)
+		}
+	case distSet:
+		for _, name := range args[1:] {
+			if _, ok := v[name]; ok {
+				delete(v, name)
+				n++
+			}
+		}
+		if len(v) == 0 {
+			delete(s.keys, key)
+		}
+	default:
+		return errWrongType
+	}
+	return n
+}
+
+func formatFloat(v float64) bulkString {
+	return bulkString(strconv.FormatFloat(v, 'f', -1, 64))
+}
@@ -0,0 +1,72 @@
+package resp
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("commands", func() {
+	var subject *Server
+
+	BeforeEach(func() {
+		subject = NewServer()
+		Expect(subject.Do("GEOADD", "Sicily", "13.361389", "38.115556", "Palermo", "15.087269", "37.502669", "Catania")).To(Equal(2))
+	})
+
+	It("should PING/ECHO", func() {
+		Expect(subject.Do("PING")).To(Equal(simpleString("PONG")))
+		Expect(subject.Do("ping", "hello")).To(Equal(bulkString("hello")))
+		Expect(subject.Do("ECHO", "hello")).To(Equal(bulkString("hello")))
+	})
+
+	It("should SELECT", func() {
+		Expect(subject.Do("SELECT", "0")).To(Equal(simpleString("OK")))
+		Expect(subject.Do("SELECT", "1")).To(MatchError("ERR DB index is out of range"))
+	})
+
+	It("should manage keys", func() {
+		Expect(subject.Do("TYPE", "Sicily")).To(Equal(simpleString("zset")))
+		Expect(subject.Do("TYPE", "Missing")).To(Equal(simpleString("none")))
+		Expect(subject.Do("EXISTS", "Sicily", "Missing", "Sicily")).To(Equal(2))
+		Expect(subject.Do("DEL", "Sicily", "Missing")).To(Equal(1))
+		Expect(subject.Do("EXISTS", "Sicily")).To(Equal(0))
+
+		Expect(subject.Do("GEOADD", "Sicily", "13.361389", "38.115556", "Palermo")).To(Equal(1))
+		Expect(subject.Do("FLUSHDB")).To(Equal(simpleString("OK")))
+		Expect(subject.Do("EXISTS", "Sicily")).To(Equal(0))
+	})
+
+	It("should ZCARD/ZSCORE", func() {
+		Expect(subject.Do("ZCARD", "Sicily")).To(Equal(2))
+		Expect(subject.Do("ZCARD", "Missing")).To(Equal(0))
+		Expect(subject.Do("ZSCORE", "Sicily", "Palermo")).To(Equal(bulkString("3479099956230698")))
+		Expect(subject.Do("ZSCORE", "Sicily", "Enna")).To(BeNil())
+	})
+
+	It("should ZRANGE", func() {
+		Expect(subject.Do("ZRANGE", "Sicily", "0", "-1")).To(Equal([]interface{}{
+			bulkString("Palermo"),
+			bulkString("Catania"),
+		}))
+		Expect(subject.Do("ZRANGE", "Sicily", "-1", "10", "WITHSCORES")).To(Equal([]interface{}{
+			bulkString("Catania"),
+			bulkString("3479447370796909"),
+		}))
+		Expect(subject.Do("ZRANGE", "Sicily", "5", "10")).To(Equal([]interface{}{}))
+		Expect(subject.Do("ZRANGE", "Missing", "0", "-1")).To(Equal([]interface{}{}))
+		Expect(subject.Do("ZRANGE", "Sicily", "0", "x")).To(MatchError("ERR value is not an integer or out of range"))
+		Expect(subject.Do("ZRANGE", "Sicily", "0", "-1", "REV")).To(MatchError("ERR syntax error"))
+	})
+
+	It("should ZREM", func() {
+		Expect(subject.Do("ZREM", "Sicily", "Palermo", "Enna")).To(Equal(1))
+		Expect(subject.Do("ZCARD", "Sicily")).To(Equal(1))
+		Expect(subject.Do("ZREM", "Sicily", "Catania")).To(Equal(1))
+		Expect(subject.Do("EXISTS", "Sicily")).To(Equal(0))
+	})
+
+	It("should reject unknown commands", func() {
+		Expect(subject.Do("GEOFOO")).To(MatchError("ERR unknown command 'GEOFOO'"))
+		Expect(subject.Do("ZSCORE", "Sicily")).To(MatchError("ERR wrong number of arguments for 'zscore' command"))
+	})
+})
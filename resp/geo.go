@@ -0,0 +1,528 @@
+package resp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bsm/geohashi"
+)
+
+var (
+	errUnsupportedUnit = errors.New("ERR unsupported unit provided. please use M, KM, FT, MI")
+	errMemberNotFound  = errors.New("ERR could not decode requested zset member")
+	errNegativeRadius  = errors.New("ERR radius cannot be negative")
+	errCountNotPos     = errors.New("ERR COUNT must be > 0")
+	errAnyWithoutCount = errors.New("ERR the ANY argument requires COUNT argument")
+)
+
+func cmdGeoAdd(s *Server, args []string) interface{} {
+	key, args := args[0], args[1:]
+
+	var nx, xx, ch bool
+	for ; len(args) != 0; args = args[1:] {
+		switch strings.ToUpper(args[0]) {
+		case "NX":
+			nx = true
+			continue
+		case "XX":
+			xx = true
+			continue
+		case "CH":
+			ch = true
+			continue
+		}
+		break
+	}
+	if nx && xx {
+		return errors.New("ERR XX and NX options at the same time are not compatible")
+	}
+	if len(args) == 0 || len(args)%3 != 0 {
+		return errSyntax
+	}
+
+	// validate all positions before making any changes
+	items := make([]geohashi.Item[string], 0, len(args)/3)
+	for i := 0; i < len(args); i += 3 {
+		lat, lon, err := parseLonLat(args[i], args[i+1])
+		if err != nil {
+			return err
+		}
+		items = append(items, geohashi.Item[string]{Lat: lat, Lon: lon, Value: args[i+2]})
+	}
+
+	set, err := s.geoSet(key, !xx)
+	if err != nil {
+		return err
+	} else if set == nil {
+		return 0
+	}
+
+	added, changed := 0, 0
+	for _, it := range items {
+		lat, lon, exists := set.Get(it.Value)
+		if (exists && nx) || (!exists && xx) {
+			continue
+		}
+
+		// store the centre of the cell, like Redis which only retains the score
+		hash := geohashi.Encode(it.Lat, it.Lon)
+		if exists && geohashi.Encode(lat, lon) == hash {
+			continue
+		}
+
+		clat, clon := hash.Decode().Center()
+		_ = set.Insert(it.Value, clat, clon)
+		if exists {
+			changed++
+		} else {
+			added++
+		}
+	}
+
+	if set.Len() == 0 {
+		delete(s.keys, key)
+	}
+	if ch {
+		return added + changed
+	}
+	return added
+}
+
+func cmdGeoPos(s *Server, args []string) interface{} {
+	set, err := s.geoSet(args[0], false)
+	if err != nil {
+		return err
+	}
+
+	res := make([]interface{}, 0, len(args)-1)
+	for _, member := range args[1:] {
+		if lat, lon, ok := set.get(member); ok {
+			res = append(res, []interface{}{formatFloat(lon), formatFloat(lat)})
+		} else {
+			res = append(res, nullArray{})
+		}
+	}
+	return res
+}
+
+func cmdGeoDist(s *Server, args []string) interface{} {
+	unit := 1.0
+	switch len(args) {
+	case 3:
+	case 4:
+		var err error
+		if unit, err = parseUnit(args[3]); err != nil {
+			return err
+		}
+	default:
+		return errSyntax
+	}
+
+	set, err := s.geoSet(args[0], false)
+	if err != nil {
+		return err
+	}
+
+	lat1, lon1, ok1 := set.get(args[1])
+	lat2, lon2, ok2 := set.get(args[2])
+	if !ok1 || !ok2 {
+		return nil
+	}
+	return formatDist(geohashi.Distance(lat1, lon1, lat2, lon2), unit)
+}
+
+func cmdGeoHash(s *Server, args []string) interface{} {
+	set, err := s.geoSet(args[0], false)
+	if err != nil {
+		return err
+	}
+
+	res := make([]interface{}, 0, len(args)-1)
+	for _, member := range args[1:] {
+		if lat, lon, ok := set.get(member); ok {
+			res = append(res, bulkString(geohashi.Encode(lat, lon).RedisGeohash()))
+		} else {
+			res = append(res, nil)
+		}
+	}
+	return res
+}
+
+func cmdGeoRadius(s *Server, args []string) interface{} {
+	q := &geoQuery{key: args[0]}
+
+	var err error
+	if q.lat, q.lon, err = parseLonLat(args[1], args[2]); err != nil {
+		return err
+	}
+	if err := q.parseRadius(args[3], args[4]); err != nil {
+		return err
+	}
+	if err := q.parseOptions(args[5:], false); err != nil {
+		return err
+	}
+	return s.geoQuery(q)
+}
+
+func cmdGeoRadiusByMember(s *Server, args []string) interface{} {
+	q := &geoQuery{key: args[0], member: args[1], fromMember: true}
+	if err := q.parseRadius(args[2], args[3]); err != nil {
+		return err
+	}
+	if err := q.parseOptions(args[4:], false); err != nil {
+		return err
+	}
+	return s.geoQuery(q)
+}
+
+func cmdGeoSearch(s *Server, args []string) interface{} {
+	q := &geoQuery{key: args[0]}
+	if err := q.parseOptions(args[1:], true); err != nil {
+		return err
+	}
+	return s.geoQuery(q)
+}
+
+func cmdGeoSearchStore(s *Server, args []string) interface{} {
+	q := &geoQuery{key: args[1], store: args[0], storing: true}
+	if err := q.parseOptions(args[2:], true); err != nil {
+		return err
+	}
+	return s.geoQuery(q)
+}
+
+// --------------------------------------------------------------------
+
+// geoQuery is a parsed GEORADIUS or GEOSEARCH query.
+type geoQuery struct {
+	key string
+
+	member     string
+	fromMember bool
+	lat, lon   float64
+	fromLonLat bool
+
+	radius        float64 // in meters
+	width, height float64 // in meters
+	byRadius      bool
+	byBox         bool
+	unit          float64
+
+	desc  bool
+	count int
+
+	withCoord, withDist, withHash bool
+
+	store     string
+	storing   bool
+	storeDist bool
+}
+
+func (q *geoQuery) parseRadius(radius, unit string) error {
+	r, err := parseDouble(radius)
+	if err != nil {
+		return err
+	} else if r < 0 {
+		return errNegativeRadius
+	}
+	if q.unit, err = parseUnit(unit); err != nil {
+		return err
+	}
+	q.radius = r * q.unit
+	q.byRadius = true
+	return nil
+}
+
+func (q *geoQuery) parseBox(width, height, unit string) error {
+	w, err := parseDouble(width)
+	if err != nil {
+		return err
+	}
+	h, err := parseDouble(height)
+	if err != nil {
+		return err
+	}
+	if w < 0 || h < 0 {
+		return errors.New("ERR height or width cannot be negative")
+	}
+	if q.unit, err = parseUnit(unit); err != nil {
+		return err
+	}
+	q.width, q.height = w*q.unit, h*q.unit
+	q.byBox = true
+	return nil
+}
+
+// parseOptions parses trailing options. If search is true, options are
+// parsed according to GEOSEARCH and GEOSEARCHSTORE, otherwise according to
+// GEORADIUS and GEORADIUSBYMEMBER.
+func (q *geoQuery) parseOptions(args []string, search bool) error {
+	for i := 0; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		remaining := len(args) - i - 1
+
+		switch {
+		case opt == "ASC":
+			q.desc = false
+		case opt == "DESC":
+			q.desc = true
+		case opt == "WITHCOORD" && !q.storing:
+			q.withCoord = true
+		case opt == "WITHDIST" && !q.storing:
+			q.withDist = true
+		case opt == "WITHHASH" && !q.storing:
+			q.withHash = true
+		case opt == "COUNT" && remaining > 0:
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return errNotInt
+			} else if n <= 0 {
+				return errCountNotPos
+			}
+			q.count = n
+			i++
+			// results are always sorted, ANY is accepted but has no effect
+			if remaining > 1 && strings.EqualFold(args[i+1], "ANY") {
+				i++
+			}
+		case opt == "ANY":
+			return errAnyWithoutCount
+		case opt == "FROMMEMBER" && search && remaining > 0:
+			q.member, q.fromMember = args[i+1], true
+			i++
+		case opt == "FROMLONLAT" && search && remaining > 1:
+			var err error
+			if q.lat, q.lon, err = parseLonLat(args[i+1], args[i+2]); err != nil {
+				return err
+			}
+			q.fromLonLat = true
+			i += 2
+		case opt == "BYRADIUS" && search && remaining > 1:
+			if err := q.parseRadius(args[i+1], args[i+2]); err != nil {
+				return err
+			}
+			i += 2
+		case opt == "BYBOX" && search && remaining > 2:
+			if err := q.parseBox(args[i+1], args[i+2], args[i+3]); err != nil {
+				return err
+			}
+			i += 3
+		case opt == "STOREDIST" && search && q.storing:
+			q.storeDist = true
+		case (opt == "STORE" || opt == "STOREDIST") && !search && remaining > 0:
+			q.store, q.storing = args[i+1], true
+			q.storeDist = opt == "STOREDIST"
+			i++
+		default:
+			return errSyntax
+		}
+	}
+
+	if search {
+		if q.fromMember == q.fromLonLat {
+			return errors.New("ERR exactly one of FROMMEMBER or FROMLONLAT can be specified for GEOSEARCH")
+		}
+		if q.byRadius == q.byBox {
+			return errors.New("ERR exactly one of BYRADIUS and BYBOX can be specified for GEOSEARCH")
+		}
+	} else if q.storing && (q.withCoord || q.withDist || q.withHash) {
+		return errors.New("ERR STORE option in GEORADIUS is not compatible with WITHDIST, WITHHASH and WITHCOORD options")
+	}
+	return nil
+}
+
+// geoQuery executes the query and either returns the matching members or,
+// when storing, the number of stored members.
+func (s *Server) geoQuery(q *geoQuery) interface{} {
+	set, err := s.geoSet(q.key, false)
+	if err != nil {
+		return err
+	}
+
+	var res []geohashi.Neighbor[string]
+	if set != nil {
+		if q.fromMember {
+			lat, lon, ok := set.get(q.member)
+			if !ok {
+				return errMemberNotFound
+			}
+			q.lat, q.lon = lat, lon
+		}
+		res = set.search(q)
+	}
+
+	if q.desc {
+		sort.SliceStable(res, func(i, j int) bool { return res[i].Distance > res[j].Distance })
+	}
+	if q.count > 0 && len(res) > q.count {
+		res = res[:q.count]
+	}
+
+	if q.storing {
+		return s.geoStore(q, res)
+	}
+
+	reply := make([]interface{}, 0, len(res))
+	for _, n := range res {
+		if !q.withCoord && !q.withDist && !q.withHash {
+			reply = append(reply, bulkString(n.Value))
+			continue
+		}
+
+		entry := []interface{}{bulkString(n.Value)}
+		if q.withDist {
+			entry = append(entry, formatDist(n.Distance, q.unit))
+		}
+		if q.withHash {
+			entry = append(entry, int64(geohashi.Encode(n.Lat, n.Lon).Score()))
+		}
+		if q.withCoord {
+			entry = append(entry, []interface{}{formatFloat(n.Lon), formatFloat(n.Lat)})
+		}
+		reply = append(reply, entry)
+	}
+	return reply
+}
+
+// geoStore stores the results of a query.
+func (s *Server) geoStore(q *geoQuery, res []geohashi.Neighbor[string]) interface{} {
+	if len(res) == 0 {
+		delete(s.keys, q.store)
+		return 0
+	}
+
+	if q.storeDist {
+		set := make(distSet, len(res))
+		for _, n := range res {
+			set[n.Value] = n.Distance / q.unit
+		}
+		s.keys[q.store] = set
+	} else {
+		set := &geoSet{Index: geohashi.NewIndex[string]()}
+		for _, n := range res {
+			_ = set.Insert(n.Value, n.Lat, n.Lon)
+		}
+		s.keys[q.store] = set
+	}
+	return len(res)
+}
+
+// --------------------------------------------------------------------
+
+// get returns the location of a member. It is safe to call on nil sets.
+func (g *geoSet) get(member string) (lat, lon float64, ok bool) {
+	if g == nil {
+		return 0, 0, false
+	}
+	return g.Get(member)
+}
+
+// search returns the members matching the query, ordered by distance.
+func (g *geoSet) search(q *geoQuery) []geohashi.Neighbor[string] {
+	if q.byRadius {
+		return g.Radius(q.lat, q.lon, q.radius)
+	}
+
+	var res []geohashi.Neighbor[string]
+	for _, a := range boxAreas(q.lat, q.lon, q.width, q.height) {
+		for _, it := range g.Within(a) {
+			if dist, ok := inBox(q.lat, q.lon, q.width, q.height, it.Lat, it.Lon); ok {
+				res = append(res, geohashi.Neighbor[string]{Item: it, Distance: dist})
+			}
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Distance < res[j].Distance })
+	return res
+}
+
+// boxAreas returns the areas enclosing a box of width x height meters around
+// a location, split at the antimeridian.
+func boxAreas(lat, lon, width, height float64) []geohashi.Area {
+	dlat := height / 2 / geohashi.EarthRadius * 180 / math.Pi
+	a := geohashi.Area{
+		MinLat: math.Max(lat-dlat, geohashi.LatMin),
+		MaxLat: math.Min(lat+dlat, geohashi.LatMax),
+		MinLon: geohashi.LonMin,
+		MaxLon: geohashi.LonMax,
+	}
+
+	φ := math.Max(math.Abs(a.MinLat), math.Abs(a.MaxLat)) * math.Pi / 180
+	dlon := width / 2 / (geohashi.EarthRadius * math.Cos(φ)) * 180 / math.Pi
+	if dlon >= 180 {
+		return []geohashi.Area{a}
+	}
+
+	a.MinLon, a.MaxLon = lon-dlon, lon+dlon
+	switch {
+	case a.MinLon < geohashi.LonMin:
+		b := a
+		b.MinLon, b.MaxLon = a.MinLon+360, geohashi.LonMax
+		a.MinLon = geohashi.LonMin
+		return []geohashi.Area{a, b}
+	case a.MaxLon > geohashi.LonMax:
+		b := a
+		b.MinLon, b.MaxLon = geohashi.LonMin, a.MaxLon-360
+		a.MaxLon = geohashi.LonMax
+		return []geohashi.Area{a, b}
+	}
+	return []geohashi.Area{a}
+}
+
+// inBox reports whether a point is within a box of width x height meters
+// centred at lat/lon and returns its distance. Like Redis, the east-west
+// distance is measured along the point's latitude.
+func inBox(lat, lon, width, height, plat, plon float64) (float64, bool) {
+	if geohashi.Distance(lat, lon, plat, lon) > height/2 {
+		return 0, false
+	}
+	if geohashi.Distance(plat, lon, plat, plon) > width/2 {
+		return 0, false
+	}
+	return geohashi.Distance(lat, lon, plat, plon), true
+}
+
+// --------------------------------------------------------------------
+
+func parseLonLat(lonArg, latArg string) (lat, lon float64, err error) {
+	if lon, err = parseDouble(lonArg); err != nil {
+		return 0, 0, err
+	}
+	if lat, err = parseDouble(latArg); err != nil {
+		return 0, 0, err
+	}
+	if lon < geohashi.LonMin || lon > geohashi.LonMax || lat < geohashi.LatMin || lat > geohashi.LatMax {
+		return 0, 0, fmt.Errorf("ERR invalid longitude,latitude pair %f,%f", lon, lat)
+	}
+	return lat, lon, nil
+}
+
+// parseDouble parses a float, like Redis, NaN is not accepted.
+func parseDouble(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) {
+		return 0, errNotFloat
+	}
+	return v, nil
+}
+
+func parseUnit(s string) (float64, error) {
+	switch strings.ToLower(s) {
+	case "m":
+		return 1, nil
+	case "km":
+		return 1000, nil
+	case "mi":
+		return 1609.34, nil
+	case "ft":
+		return 0.3048, nil
+	}
+	return 0, errUnsupportedUnit
+}
+
+func formatDist(meters, unit float64) bulkString {
+	return bulkString(strconv.FormatFloat(meters/unit, 'f', 4, 64))
+}
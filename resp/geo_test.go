@@ -0,0 +1,146 @@
+package resp
+
+import (
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GEO commands", func() {
+	var subject *Server
+
+	BeforeEach(func() {
+		subject = NewServer()
+		Expect(subject.Do("GEOADD", "Sicily", "13.361389", "38.115556", "Palermo", "15.087269", "37.502669", "Catania")).To(Equal(2))
+	})
+
+	It("should GEOADD", func() {
+		Expect(subject.Do("GEOADD", "Sicily", "13.361389", "38.115556", "Palermo")).To(Equal(0))
+		Expect(subject.Do("GEOADD", "Sicily", "CH", "13.361389", "38.115556", "Palermo", "13.583333", "37.316667", "Agrigento")).To(Equal(1))
+		Expect(subject.Do("GEOADD", "Sicily", "CH", "13.4", "38.1", "Palermo")).To(Equal(1))
+		Expect(subject.Do("GEOADD", "Sicily", "NX", "13.361389", "38.115556", "Palermo")).To(Equal(0))
+		Expect(subject.Do("GEOADD", "Sicily", "XX", "14.015482", "37.116796", "Gela")).To(Equal(0))
+		Expect(subject.Do("ZCARD", "Sicily")).To(Equal(3))
+
+		Expect(subject.Do("GEOADD", "Other", "XX", "14.015482", "37.116796", "Gela")).To(Equal(0))
+		Expect(subject.Do("EXISTS", "Other")).To(Equal(0))
+
+		Expect(subject.Do("GEOADD", "Sicily", "NX", "XX", "13.361389", "38.115556", "Palermo")).To(MatchError("ERR XX and NX options at the same time are not compatible"))
+		Expect(subject.Do("GEOADD", "Sicily", "13.361389", "38.115556", "Palermo", "15")).To(MatchError("ERR syntax error"))
+		Expect(subject.Do("GEOADD", "Sicily", "13.361389", "86", "North", "14", "37", "Gela")).To(MatchError("ERR invalid longitude,latitude pair 13.361389,86.000000"))
+		Expect(subject.Do("GEOADD", "Sicily", "NaN", "NaN", "Nowhere")).To(MatchError("ERR value is not a valid float"))
+		Expect(subject.Do("ZCARD", "Sicily")).To(Equal(3))
+	})
+
+	It("should GEOPOS", func() {
+		res := subject.Do("GEOPOS", "Sicily", "Palermo", "Enna")
+		Expect(res).To(HaveLen(2))
+		Expect(res.([]interface{})[1]).To(Equal(nullArray{}))
+
+		pos := res.([]interface{})[0].([]interface{})
+		Expect(parseFloat(pos[0])).To(BeNumerically("~", 13.361389, 1e-5))
+		Expect(parseFloat(pos[1])).To(BeNumerically("~", 38.115556, 1e-5))
+
+		Expect(subject.Do("GEOPOS", "Missing", "Palermo")).To(Equal([]interface{}{nullArray{}}))
+	})
+
+	It("should GEODIST", func() {
+		Expect(subject.Do("GEODIST", "Sicily", "Palermo", "Catania")).To(Equal(bulkString("166274.1516")))
+		Expect(subject.Do("GEODIST", "Sicily", "Palermo", "Catania", "km")).To(Equal(bulkString("166.2742")))
+		Expect(subject.Do("GEODIST", "Sicily", "Palermo", "Catania", "mi")).To(Equal(bulkString("103.3182")))
+		Expect(subject.Do("GEODIST", "Sicily", "Palermo", "Enna")).To(BeNil())
+		Expect(subject.Do("GEODIST", "Sicily", "Palermo", "Catania", "yd")).To(MatchError("ERR unsupported unit provided. please use M, KM, FT, MI"))
+	})
+
+	It("should GEOHASH", func() {
+		Expect(subject.Do("GEOHASH", "Sicily", "Palermo", "Catania", "Enna")).To(Equal([]interface{}{
+			bulkString("sqc8b49rny0"),
+			bulkString("sqdtr74hyu0"),
+			nil,
+		}))
+	})
+
+	It("should GEORADIUS", func() {
+		Expect(subject.Do("GEORADIUS", "Sicily", "15", "37", "200", "km", "WITHDIST", "DESC")).To(Equal([]interface{}{
+			[]interface{}{bulkString("Palermo"), bulkString("190.4424")},
+			[]interface{}{bulkString("Catania"), bulkString("56.4413")},
+		}))
+		Expect(subject.Do("GEORADIUS", "Sicily", "15", "37", "100", "km", "WITHHASH")).To(Equal([]interface{}{
+			[]interface{}{bulkString("Catania"), int64(3479447370796909)},
+		}))
+		Expect(subject.Do("GEORADIUS", "Sicily", "15", "37", "200", "km", "COUNT", "1")).To(Equal([]interface{}{
+			bulkString("Catania"),
+		}))
+		Expect(subject.Do("GEORADIUS", "Sicily", "15", "37", "-1", "km")).To(MatchError("ERR radius cannot be negative"))
+		Expect(subject.Do("GEORADIUS", "Sicily", "15", "37", "200", "km", "COUNT", "0")).To(MatchError("ERR COUNT must be > 0"))
+		Expect(subject.Do("GEORADIUS", "Sicily", "15", "37", "200", "km", "WITHDIST", "STORE", "dst")).To(MatchError("ERR STORE option in GEORADIUS is not compatible with WITHDIST, WITHHASH and WITHCOORD options"))
+
+		Expect(subject.Do("GEORADIUS", "Sicily", "15", "37", "200", "km", "STOREDIST", "dst")).To(Equal(2))
+		Expect(subject.Do("ZRANGE", "dst", "0", "-1", "WITHSCORES")).To(Equal([]interface{}{
+			bulkString("Catania"), bulkString("56.44125787015818"),
+			bulkString("Palermo"), bulkString("190.4424298477584"),
+		}))
+	})
+
+	It("should GEORADIUSBYMEMBER", func() {
+		Expect(subject.Do("GEOADD", "Sicily", "13.583333", "37.316667", "Agrigento")).To(Equal(1))
+		Expect(subject.Do("GEORADIUSBYMEMBER", "Sicily", "Agrigento", "100", "km")).To(Equal([]interface{}{
+			bulkString("Agrigento"),
+			bulkString("Palermo"),
+		}))
+		Expect(subject.Do("GEORADIUSBYMEMBER", "Sicily", "Enna", "100", "km")).To(MatchError("ERR could not decode requested zset member"))
+	})
+
+	It("should GEOSEARCH", func() {
+		Expect(subject.Do("GEOADD", "Sicily", "12.758489", "38.788135", "edge1", "17.241510", "38.788135", "edge2")).To(Equal(2))
+		Expect(subject.Do("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "ASC")).To(Equal([]interface{}{
+			bulkString("Catania"),
+			bulkString("Palermo"),
+		}))
+		Expect(subject.Do("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYBOX", "400", "400", "km", "ASC", "WITHDIST")).To(Equal([]interface{}{
+			[]interface{}{bulkString("Catania"), bulkString("56.4413")},
+			[]interface{}{bulkString("Palermo"), bulkString("190.4424")},
+			[]interface{}{bulkString("edge2"), bulkString("279.7403")},
+			[]interface{}{bulkString("edge1"), bulkString("279.7405")},
+		}))
+		Expect(subject.Do("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "200", "km", "DESC", "COUNT", "2", "ANY")).To(Equal([]interface{}{
+			bulkString("Catania"),
+			bulkString("edge1"),
+		}))
+		Expect(subject.Do("GEOSEARCH", "Missing", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km")).To(Equal([]interface{}{}))
+		Expect(subject.Do("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "NaN", "km")).To(MatchError("ERR value is not a valid float"))
+		Expect(subject.Do("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYBOX", "400", "NaN", "km")).To(MatchError("ERR value is not a valid float"))
+
+		Expect(subject.Do("GEOSEARCH", "Sicily", "BYRADIUS", "200", "km", "ASC", "WITHDIST")).To(MatchError("ERR exactly one of FROMMEMBER or FROMLONLAT can be specified for GEOSEARCH"))
+		Expect(subject.Do("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "COUNT", "2")).To(MatchError("ERR exactly one of BYRADIUS and BYBOX can be specified for GEOSEARCH"))
+		Expect(subject.Do("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "ANY")).To(MatchError("ERR the ANY argument requires COUNT argument"))
+	})
+
+	It("should search across the antimeridian", func() {
+		Expect(subject.Do("GEOADD", "Pacific", "179.9", "0", "east", "-179.9", "0", "west")).To(Equal(2))
+		Expect(subject.Do("GEOSEARCH", "Pacific", "FROMLONLAT", "180", "0", "BYBOX", "50", "50", "km")).To(HaveLen(2))
+		Expect(subject.Do("GEOSEARCH", "Pacific", "FROMLONLAT", "-180", "0", "BYRADIUS", "50", "km")).To(HaveLen(2))
+	})
+
+	It("should GEOSEARCHSTORE", func() {
+		Expect(subject.Do("GEOSEARCHSTORE", "dst", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "100", "km")).To(Equal(1))
+		Expect(subject.Do("GEOPOS", "dst", "Catania")).To(HaveLen(1))
+		Expect(subject.Do("ZSCORE", "dst", "Catania")).To(Equal(bulkString("3479447370796909")))
+
+		Expect(subject.Do("GEOSEARCHSTORE", "dst", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "STOREDIST")).To(Equal(2))
+		Expect(subject.Do("ZSCORE", "dst", "Catania")).To(Equal(bulkString("56.44125787015818")))
+		Expect(subject.Do("GEOPOS", "dst", "Catania")).To(MatchError(errWrongType))
+
+		Expect(subject.Do("GEOSEARCHSTORE", "dst", "Sicily", "FROMLONLAT", "0", "0", "BYRADIUS", "200", "km")).To(Equal(0))
+		Expect(subject.Do("EXISTS", "dst")).To(Equal(0))
+
+		Expect(subject.Do("GEOSEARCHSTORE", "dst", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "WITHDIST")).To(MatchError("ERR syntax error"))
+	})
+})
+
+func parseFloat(v interface{}) float64 {
+	f, err := strconv.ParseFloat(string(v.(bulkString)), 64)
+	Expect(err).NotTo(HaveOccurred())
+	return f
+}
@@ -0,0 +1,144 @@
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Request limits, as in Redis: maxBulkLen limits the size of bulk strings,
+// maxLineLen the size of inline commands and array or bulk string headers.
+// Headers only preallocate up to maxPrealloc bytes or arguments, larger
+// requests grow as their data arrives.
+const (
+	maxBulkLen  = 512 << 20
+	maxLineLen  = 64 << 10
+	maxPrealloc = 1 << 10
+)
+
+var errProtocol = errors.New("ERR Protocol error")
+
+// Reply types, see writeReply for the mapping to RESP.
+type (
+	simpleString string
+	bulkString   string
+	nullArray    struct{}
+)
+
+// readCommand reads a command, either as an array of bulk strings or as an
+// inline command. Null arrays are read as empty commands.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < -1 || n > 1<<20 {
+		return nil, errProtocol
+	} else if n == -1 {
+		return nil, nil
+	}
+
+	args := make([]string, 0, min(n, maxPrealloc))
+	for i := 0; i < n; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, errProtocol
+		}
+
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > maxBulkLen {
+			return nil, errProtocol
+		}
+
+		var buf bytes.Buffer
+		buf.Grow(min(size+2, maxPrealloc))
+		if _, err := io.CopyN(&buf, r, int64(size+2)); err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		}
+
+		data := buf.Bytes()
+		if data[size] != '\r' || data[size+1] != '\n' {
+			return nil, errProtocol
+		}
+		args = append(args, string(data[:size]))
+	}
+	return args, nil
+}
+
+// readLine reads a line of up to maxLineLen bytes.
+func readLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(line)+len(chunk) > maxLineLen {
+			return "", errProtocol
+		}
+		line = append(line, chunk...)
+
+		if err == nil {
+			return strings.TrimRight(string(line), "\r\n"), nil
+		} else if err != bufio.ErrBufferFull {
+			return "", err
+		}
+	}
+}
+
+// writeReply writes a reply. Supported types are:
+//
+//	simpleString  -> simple string
+//	error         -> error
+//	int64, int    -> integer
+//	bulkString    -> bulk string
+//	nil           -> null bulk string
+//	nullArray     -> null array
+//	[]interface{} -> array
+func writeReply(w *bufio.Writer, v interface{}) {
+	switch v := v.(type) {
+	case simpleString:
+		w.WriteByte('+')
+		w.WriteString(string(v))
+		w.WriteString("\r\n")
+	case error:
+		w.WriteByte('-')
+		w.WriteString(strings.NewReplacer("\r", " ", "\n", " ").Replace(v.Error()))
+		w.WriteString("\r\n")
+	case int:
+		writeReply(w, int64(v))
+	case int64:
+		w.WriteByte(':')
+		w.WriteString(strconv.FormatInt(v, 10))
+		w.WriteString("\r\n")
+	case bulkString:
+		w.WriteByte('$')
+		w.WriteString(strconv.Itoa(len(v)))
+		w.WriteString("\r\n")
+		w.WriteString(string(v))
+		w.WriteString("\r\n")
+	case nil:
+		w.WriteString("$-1\r\n")
+	case nullArray:
+		w.WriteString("*-1\r\n")
+	case []interface{}:
+		w.WriteByte('*')
+		w.WriteString(strconv.Itoa(len(v)))
+		w.WriteString("\r\n")
+		for _, e := range v {
+			writeReply(w, e)
+		}
+	default:
+		panic("resp: unsupported reply type")
+	}
+}
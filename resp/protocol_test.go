@@ -0,0 +1,92 @@
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("readCommand", func() {
+	read := func(s string) ([]string, error) {
+		return readCommand(bufio.NewReader(strings.NewReader(s)))
+	}
+
+	It("should read arrays", func() {
+		Expect(read("*2\r\n$4\r\nECHO\r\n$5\r\nhe\r\no\r\n")).To(Equal([]string{"ECHO", "he\r\no"}))
+		Expect(read("*0\r\n")).To(BeEmpty())
+		Expect(read("*-1\r\n")).To(BeEmpty())
+	})
+
+	It("should read inline commands", func() {
+		Expect(read("ECHO  hello\r\n")).To(Equal([]string{"ECHO", "hello"}))
+		Expect(read("PING\n")).To(Equal([]string{"PING"}))
+		Expect(read("\r\n")).To(BeEmpty())
+	})
+
+	It("should reject bad input", func() {
+		_, err := read("*x\r\n")
+		Expect(err).To(Equal(errProtocol))
+		_, err = read("*1\r\n:1\r\n")
+		Expect(err).To(Equal(errProtocol))
+		_, err = read("*1\r\n$2\r\nabc\r\n")
+		Expect(err).To(Equal(errProtocol))
+		_, err = read("*1\r\n$4\r\nPI")
+		Expect(err).To(Equal(io.ErrUnexpectedEOF))
+		_, err = read("*-5\r\n")
+		Expect(err).To(Equal(errProtocol))
+		_, err = read("*1\r\n$-1\r\n")
+		Expect(err).To(Equal(errProtocol))
+	})
+
+	It("should limit line lengths", func() {
+		line := strings.Repeat("x", maxLineLen-2) + "\r\n"
+		Expect(read(line)).To(HaveLen(1))
+
+		_, err := read("x" + line)
+		Expect(err).To(Equal(errProtocol))
+		_, err = read("*1\r\n$" + strings.Repeat("0", maxLineLen) + "\r\n")
+		Expect(err).To(Equal(errProtocol))
+		_, err = read(strings.Repeat("x", 2*maxLineLen))
+		Expect(err).To(Equal(errProtocol))
+	})
+
+	It("should not allocate from headers alone", func() {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, err := read("*1048576\r\n$536870912\r\nPING\r\n")
+		runtime.ReadMemStats(&after)
+		Expect(err).To(Equal(io.ErrUnexpectedEOF))
+		Expect(after.TotalAlloc - before.TotalAlloc).To(BeNumerically("<", 1<<20))
+
+		arg := strings.Repeat("x", 4*maxPrealloc)
+		Expect(read("*1\r\n$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")).To(Equal([]string{arg}))
+	})
+})
+
+var _ = Describe("writeReply", func() {
+	write := func(v interface{}) string {
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		writeReply(w, v)
+		Expect(w.Flush()).To(Succeed())
+		return buf.String()
+	}
+
+	It("should write replies", func() {
+		Expect(write(simpleString("OK"))).To(Equal("+OK\r\n"))
+		Expect(write(errors.New("ERR bad\r\nthing"))).To(Equal("-ERR bad  thing\r\n"))
+		Expect(write(3)).To(Equal(":3\r\n"))
+		Expect(write(int64(-3))).To(Equal(":-3\r\n"))
+		Expect(write(bulkString("hi"))).To(Equal("$2\r\nhi\r\n"))
+		Expect(write(nil)).To(Equal("$-1\r\n"))
+		Expect(write(nullArray{})).To(Equal("*-1\r\n"))
+		Expect(write([]interface{}{bulkString("a"), []interface{}{1, nil}})).To(Equal("*2\r\n$1\r\na\r\n*2\r\n:1\r\n$-1\r\n"))
+	})
+})
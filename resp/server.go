@@ -0,0 +1,224 @@
+// Package resp implements a small, embeddable server which speaks the Redis
+// protocol (RESP) and implements the Redis GEO commands on top of an
+// in-process geohashi.Index.
+//
+// Supported commands are GEOADD, GEOPOS, GEODIST, GEOHASH, GEORADIUS,
+// GEORADIUSBYMEMBER, GEOSEARCH and GEOSEARCHSTORE as well as a few generic
+// and sorted set commands commonly used alongside: PING, ECHO, QUIT, SELECT,
+// TYPE, DEL, EXISTS, FLUSHDB, FLUSHALL, ZCARD, ZSCORE, ZREM and ZRANGE.
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/bsm/geohashi"
+)
+
+// Server is a RESP server. All data is held in memory.
+type Server struct {
+	mu   sync.RWMutex
+	keys map[string]interface{} // *geoSet or distSet
+
+	connMu    sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// geoSet is a sorted set of members with locations.
+type geoSet struct{ *geohashi.Index[string] }
+
+// distSet is a sorted set of members with distances, as created by
+// the STOREDIST option.
+type distSet map[string]float64
+
+// NewServer inits a new server.
+func NewServer() *Server {
+	return &Server{
+		keys:      make(map[string]interface{}),
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on the TCP address and serves connections.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts and serves connections on the listener. It blocks until the
+// listener fails or the server is closed, in which case it returns nil.
+func (s *Server) Serve(l net.Listener) error {
+	if !s.track(l, nil) {
+		_ = l.Close()
+		return nil
+	}
+	defer s.untrack(l, nil)
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+			return err
+		}
+		if !s.track(nil, conn) {
+			_ = conn.Close()
+			return nil
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.untrack(nil, conn)
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// Close closes all listeners and connections and waits for pending commands
+// to finish.
+func (s *Server) Close() error {
+	s.connMu.Lock()
+	s.closed = true
+	for l := range s.listeners {
+		_ = l.Close()
+	}
+	for c := range s.conns {
+		_ = c.Close()
+	}
+	s.connMu.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// Do executes a single command and returns the reply, as written to clients.
+// It is mostly useful for testing.
+func (s *Server) Do(args ...string) interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+
+	cmd, ok := commands[strings.ToUpper(args[0])]
+	if !ok {
+		return fmt.Errorf("ERR unknown command '%s'", args[0])
+	}
+	if len(args) < cmd.arity {
+		return fmt.Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(args[0]))
+	}
+
+	if cmd.write {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	} else {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	}
+	return cmd.fn(s, args[1:])
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	for {
+		args, err := readCommand(r)
+		if errors.Is(err, errProtocol) {
+			writeReply(w, err)
+			_ = w.Flush()
+			return
+		} else if err != nil {
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		quit := strings.EqualFold(args[0], "QUIT")
+		if quit {
+			writeReply(w, simpleString("OK"))
+		} else {
+			writeReply(w, s.Do(args...))
+		}
+
+		// flush unless more commands are pipelined
+		if r.Buffered() == 0 || quit {
+			if err := w.Flush(); err != nil || quit {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) track(l net.Listener, c net.Conn) bool {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+
+	if s.closed {
+		return false
+	}
+	if l != nil {
+		s.listeners[l] = struct{}{}
+	}
+	if c != nil {
+		s.conns[c] = struct{}{}
+	}
+	return true
+}
+
+func (s *Server) untrack(l net.Listener, c net.Conn) {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+
+	if l != nil {
+		delete(s.listeners, l)
+	}
+	if c != nil {
+		_ = c.Close()
+		delete(s.conns, c)
+	}
+}
+
+func (s *Server) isClosed() bool {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	return s.closed
+}
+
+// --------------------------------------------------------------------
+
+var (
+	errWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errSyntax    = errors.New("ERR syntax error")
+	errNotInt    = errors.New("ERR value is not an integer or out of range")
+	errNotFloat  = errors.New("ERR value is not a valid float")
+)
+
+// geoSet returns the geo set stored at key. If create is true, missing keys
+// are created.
+func (s *Server) geoSet(key string, create bool) (*geoSet, error) {
+	switch v := s.keys[key].(type) {
+	case *geoSet:
+		return v, nil
+	case nil:
+		if !create {
+			return nil, nil
+		}
+		set := &geoSet{Index: geohashi.NewIndex[string]()}
+		s.keys[key] = set
+		return set, nil
+	}
+	return nil, errWrongType
+}
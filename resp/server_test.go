@@ -0,0 +1,170 @@
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server", func() {
+	var subject *Server
+	var conn *testConn
+
+	BeforeEach(func() {
+		subject = NewServer()
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		go func() { _ = subject.Serve(l) }()
+
+		c, err := net.Dial("tcp", l.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		conn = &testConn{Conn: c, r: bufio.NewReader(c)}
+	})
+
+	AfterEach(func() {
+		_ = conn.Close()
+		Expect(subject.Close()).To(Succeed())
+	})
+
+	It("should serve commands", func() {
+		Expect(conn.Do("PING")).To(Equal("PONG"))
+		Expect(conn.Do("GEOADD", "Sicily", "13.361389", "38.115556", "Palermo", "15.087269", "37.502669", "Catania")).To(Equal(int64(2)))
+		Expect(conn.Do("GEODIST", "Sicily", "Palermo", "Catania")).To(Equal("166274.1516"))
+		Expect(conn.Do("GEOHASH", "Sicily", "Palermo", "Catania", "Enna")).To(Equal([]interface{}{"sqc8b49rny0", "sqdtr74hyu0", nil}))
+		Expect(conn.Do("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "ASC", "WITHDIST")).To(Equal([]interface{}{
+			[]interface{}{"Catania", "56.4413"},
+			[]interface{}{"Palermo", "190.4424"},
+		}))
+		Expect(conn.Do("GEOPOS", "Sicily", "Enna")).To(Equal([]interface{}{nil}))
+	})
+
+	It("should reply with errors", func() {
+		Expect(conn.Do("UNKNOWN")).To(MatchError("ERR unknown command 'UNKNOWN'"))
+		Expect(conn.Do("GEOADD", "Sicily")).To(MatchError("ERR wrong number of arguments for 'geoadd' command"))
+		Expect(conn.Do("PING")).To(Equal("PONG"))
+	})
+
+	It("should support inline commands", func() {
+		_, err := conn.Write([]byte("PING\r\nECHO hello\r\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conn.read()).To(Equal("PONG"))
+		Expect(conn.read()).To(Equal("hello"))
+	})
+
+	It("should support pipelining", func() {
+		_, err := conn.Write([]byte("*1\r\n$4\r\nPING\r\n*2\r\n$4\r\nECHO\r\n$3\r\nfoo\r\n*1\r\n$4\r\nQUIT\r\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conn.read()).To(Equal("PONG"))
+		Expect(conn.read()).To(Equal("foo"))
+		Expect(conn.read()).To(Equal("OK"))
+
+		_, err = conn.read()
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("should close connections on protocol errors", func() {
+		_, err := conn.Write([]byte("*1\r\n+PING\r\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conn.read()).To(MatchError("ERR Protocol error"))
+
+		_, err = conn.read()
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("should survive negative array lengths", func() {
+		_, err := conn.Write([]byte("*-1\r\n*-5\r\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conn.read()).To(MatchError("ERR Protocol error"))
+
+		_, err = conn.read()
+		Expect(err).To(Equal(io.EOF))
+
+		// the server still accepts connections
+		c, err := net.Dial("tcp", conn.RemoteAddr().String())
+		Expect(err).NotTo(HaveOccurred())
+		defer c.Close()
+
+		other := &testConn{Conn: c, r: bufio.NewReader(c)}
+		Expect(other.Do("PING")).To(Equal("PONG"))
+	})
+})
+
+// --------------------------------------------------------------------
+
+// testConn is a minimal RESP client.
+type testConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// Do sends a command and returns the reply. Error replies are returned as
+// values.
+func (c *testConn) Do(args ...string) (interface{}, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.Conn, sb.String()); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+func (c *testConn) read() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return errors.New(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		res := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			v, err := c.read()
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, v)
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("invalid reply %q", line)
+}
+
+// --------------------------------------------------------------------
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "github.com/bsm/geohashi/resp")
+}
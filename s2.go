@@ -0,0 +1,572 @@
+package geohashi
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+var errInvalidS2Cell = errors.New("geohashi: invalid S2 cell")
+
+// S2 cell hierarchy constants, see
+// https://s2geometry.io/devguide/s2cell_hierarchy
+const (
+	S2LevelMax = 30 // maximum S2 cell level, leaf cells are roughly 1cm²
+
+	s2PosBits    = 2*S2LevelMax + 1
+	s2MaxSize    = 1 << S2LevelMax // number of leaf cells along each face axis
+	s2LookupBits = 4
+	s2SwapMask   = 0x01
+	s2InvertMask = 0x02
+)
+
+// S2CellID identifies a cell of Google's S2 geometry library. S2 projects the
+// six faces of a cube onto the sphere and subdivides each face recursively
+// into four cells, which are ordered along a Hilbert curve.
+//
+// S2 cells are bridged to hashes via the coverer: S2CellID implements Fence,
+// so CoverFence returns the hashes which cover a cell, while Area.S2Cells
+// returns the cells which cover an area or hash.
+type S2CellID uint64
+
+// S2CellIDFromLatLon returns the cell at the given level (0..30) which
+// contains a location. Unlike hashes, S2 cells cover the entire globe,
+// including the poles.
+func S2CellIDFromLatLon(lat, lon float64, level int) (S2CellID, error) {
+	if !(Point{Lat: lat, Lon: lon}).valid() {
+		return 0, errInvalidCoordinates
+	}
+	if level < 0 || level > S2LevelMax {
+		return 0, errInvalidS2Cell
+	}
+
+	face, u, v := s2FaceUV(s2LatLonToXYZ(lat, lon))
+	i, j := s2STToIJ(s2UVToST(u)), s2STToIJ(s2UVToST(v))
+	return s2CellIDFromFaceIJ(face, i, j).ancestor(level), nil
+}
+
+// S2CellIDFromToken parses a cell token, the hex representation of the ID
+// with trailing zeros removed, e.g. "89c25".
+func S2CellIDFromToken(token string) (S2CellID, error) {
+	if len(token) == 0 || len(token) > 16 {
+		return 0, errInvalidS2Cell
+	}
+
+	n, err := strconv.ParseUint(token+strings.Repeat("0", 16-len(token)), 16, 64)
+	if err != nil || !S2CellID(n).IsValid() {
+		return 0, errInvalidS2Cell
+	}
+	return S2CellID(n), nil
+}
+
+// IsValid returns true if the ID identifies a cell.
+func (c S2CellID) IsValid() bool {
+	return c.Face() < 6 && c.lsb()&0x1555555555555555 != 0
+}
+
+// Face returns the cube face (0..5) of the cell.
+func (c S2CellID) Face() int { return int(c >> s2PosBits) }
+
+// Level returns the level of the cell, between 0 (a face) and 30.
+func (c S2CellID) Level() int {
+	return S2LevelMax - bits.TrailingZeros64(uint64(c))>>1
+}
+
+// Token returns the compact hex representation of the cell ID.
+func (c S2CellID) Token() string {
+	if c == 0 {
+		return "X"
+	}
+	s := strconv.FormatUint(uint64(c), 16)
+	return strings.TrimRight(strings.Repeat("0", 16-len(s))+s, "0")
+}
+
+// Parent returns the parent cell. Returns 0 for face cells.
+func (c S2CellID) Parent() S2CellID {
+	level := c.Level()
+	if level == 0 {
+		return 0
+	}
+	return c.ancestor(level - 1)
+}
+
+// Children returns the four child cells, in Hilbert curve order. Returns nil
+// for leaf cells.
+func (c S2CellID) Children() []S2CellID {
+	if c.Level() >= S2LevelMax {
+		return nil
+	}
+
+	lsb := S2CellID(c.lsb())
+	child := c - lsb + lsb>>2
+	step := lsb >> 1
+	return []S2CellID{child, child + step, child + 2*step, child + 3*step}
+}
+
+// ContainsCell returns true if o is the cell itself or one of its
+// descendants.
+func (c S2CellID) ContainsCell(o S2CellID) bool {
+	lsb := S2CellID(c.lsb())
+	return c-(lsb-1) <= o && o <= c+(lsb-1)
+}
+
+// Center returns the center of the cell.
+func (c S2CellID) Center() (lat, lon float64) {
+	face, u0, u1, v0, v1 := c.uvBounds()
+	return s2XYZToLatLon(s2FaceUVToXYZ(face, (u0+u1)/2, (v0+v1)/2))
+}
+
+// Vertices returns the four corners of the cell, counter-clockwise. Note that
+// cell edges are geodesics.
+func (c S2CellID) Vertices() [4]Point {
+	var pts [4]Point
+	for k, v := range c.vertices() {
+		pts[k].Lat, pts[k].Lon = s2XYZToLatLon(v)
+	}
+	return pts
+}
+
+// Bounds implements Fence. Cells crossing the antimeridian or containing a
+// pole span all longitudes.
+func (c S2CellID) Bounds() Area {
+	return c.bounds(c.vertices())
+}
+
+// Contains implements Fence.
+func (c S2CellID) Contains(lat, lon float64) bool {
+	return c.containsXYZ(s2LatLonToXYZ(lat, lon))
+}
+
+// Relate implements Fence.
+func (c S2CellID) Relate(a Area) Relation {
+	vs := c.vertices()
+	return s2RelatePolygon(vs[:], c.bounds(vs), a, c.Contains)
+}
+
+// S2Cells returns the S2 cells at the given level which intersect the area,
+// in ascending order. The number of cells grows by a factor of four with
+// each level, callers should choose a level appropriate to the size of the
+// area. It returns nil if the area intersects more than MaxCoverCells cells,
+// use S2CellsLimit to choose a different limit.
+func (a Area) S2Cells(level int) []S2CellID {
+	cells, _ := a.S2CellsLimit(level, MaxCoverCells)
+	return cells
+}
+
+// S2CellsLimit is like S2Cells, but returns an error if the area intersects
+// more than maxCells cells.
+func (a Area) S2CellsLimit(level, maxCells int) ([]S2CellID, error) {
+	if level < 0 || level > S2LevelMax {
+		return nil, errInvalidS2Cell
+	}
+	if a.MinLat > a.MaxLat || a.MinLon > a.MaxLon {
+		return nil, errInvalidArea
+	}
+
+	var cells []S2CellID
+	var visit func(S2CellID) bool
+	visit = func(c S2CellID) bool {
+		if c.Relate(a) == Outside {
+			return true
+		}
+		if c.Level() >= level {
+			cells = append(cells, c)
+			return len(cells) <= maxCells
+		}
+		for _, child := range c.Children() {
+			if !visit(child) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for face := 0; face < 6; face++ {
+		if !visit(S2CellID(uint64(face)<<s2PosBits | 1<<(s2PosBits-1))) {
+			return nil, errTooManyCells
+		}
+	}
+	return cells, nil
+}
+
+// S2Cells returns the S2 cells at the given level which intersect the hash,
+// in ascending order.
+func (h Hash) S2Cells(level int) []S2CellID {
+	return h.Decode().S2Cells(level)
+}
+
+// lsb returns the lowest set bit of the ID, which encodes its level.
+func (c S2CellID) lsb() uint64 { return uint64(c) & -uint64(c) }
+
+// ancestor returns the ancestor cell at the given level. Returns c if level
+// is not above the level of the cell.
+func (c S2CellID) ancestor(level int) S2CellID {
+	if level >= c.Level() {
+		return c
+	}
+	lsb := uint64(1) << (2 * (S2LevelMax - level))
+	return S2CellID(uint64(c)&-lsb | lsb)
+}
+
+// faceIJ returns the face and the (i, j) coordinates of the leaf cell at
+// the start of the cell's Hilbert curve segment.
+func (c S2CellID) faceIJ() (face, i, j int) {
+	face = c.Face()
+	orientation := face & s2SwapMask
+	nbits := S2LevelMax - 7*s2LookupBits
+	for k := 7; k >= 0; k-- {
+		orientation += int(uint64(c)>>(k*2*s2LookupBits+1)&(1<<(2*nbits)-1)) << 2
+		orientation = s2Lookup.ij[orientation]
+		i += orientation >> (s2LookupBits + 2) << (k * s2LookupBits)
+		j += orientation >> 2 & (1<<s2LookupBits - 1) << (k * s2LookupBits)
+		orientation &= s2SwapMask | s2InvertMask
+		nbits = s2LookupBits
+	}
+	return
+}
+
+// uvBounds returns the face and (u, v) bounds of the cell.
+func (c S2CellID) uvBounds() (face int, u0, u1, v0, v1 float64) {
+	face, i, j := c.faceIJ()
+	size := 1 << (S2LevelMax - c.Level())
+	i, j = i&-size, j&-size
+	return face,
+		s2STToUV(float64(i) / s2MaxSize), s2STToUV(float64(i+size) / s2MaxSize),
+		s2STToUV(float64(j) / s2MaxSize), s2STToUV(float64(j+size) / s2MaxSize)
+}
+
+// vertices returns the unit vectors of the cell corners, counter-clockwise.
+func (c S2CellID) vertices() [4]s2Point {
+	face, u0, u1, v0, v1 := c.uvBounds()
+	return [4]s2Point{
+		s2FaceUVToXYZ(face, u0, v0).normalize(),
+		s2FaceUVToXYZ(face, u1, v0).normalize(),
+		s2FaceUVToXYZ(face, u1, v1).normalize(),
+		s2FaceUVToXYZ(face, u0, v1).normalize(),
+	}
+}
+
+// bounds returns the bounding box of the cell with the given vertices.
+func (c S2CellID) bounds(vs [4]s2Point) Area {
+	_, center := c.Center()
+	return s2PolygonBounds(vs[:], center, c.containsXYZ(s2Point{0, 0, 1}), c.containsXYZ(s2Point{0, 0, -1}))
+}
+
+// containsXYZ returns true if a point is within the cell, boundaries
+// included.
+func (c S2CellID) containsXYZ(p s2Point) bool {
+	face, u0, u1, v0, v1 := c.uvBounds()
+	u, v, ok := s2FaceXYZToUV(face, p)
+	return ok && u0 <= u && u <= u1 && v0 <= v && v <= v1
+}
+
+// --------------------------------------------------------------------
+
+// s2Point is a point in 3D space, usually a unit vector on the sphere.
+type s2Point [3]float64
+
+func (p s2Point) dot(o s2Point) float64 { return p[0]*o[0] + p[1]*o[1] + p[2]*o[2] }
+
+func (p s2Point) cross(o s2Point) s2Point {
+	return s2Point{p[1]*o[2] - p[2]*o[1], p[2]*o[0] - p[0]*o[2], p[0]*o[1] - p[1]*o[0]}
+}
+
+func (p s2Point) isZero() bool { return p == s2Point{} }
+
+func (p s2Point) normalize() s2Point {
+	n := math.Sqrt(p.dot(p))
+	if n == 0 {
+		return p
+	}
+	return s2Point{p[0] / n, p[1] / n, p[2] / n}
+}
+
+// onArc returns true if p, a point on the great circle through a and b with
+// normal n = a × b, lies on the shorter arc between a and b.
+func (p s2Point) onArc(a, b, n s2Point) bool {
+	return a.cross(p).dot(n) >= 0 && p.cross(b).dot(n) >= 0
+}
+
+func s2LatLonToXYZ(lat, lon float64) s2Point {
+	φ, λ := deg2rad(lat), deg2rad(lon)
+	return s2Point{math.Cos(φ) * math.Cos(λ), math.Cos(φ) * math.Sin(λ), math.Sin(φ)}
+}
+
+func s2XYZToLatLon(p s2Point) (lat, lon float64) {
+	return rad2deg(math.Atan2(p[2], math.Hypot(p[0], p[1]))), rad2deg(math.Atan2(p[1], p[0]))
+}
+
+// s2FaceUV projects a point onto the face it belongs to, i.e. the face of
+// the axis with the largest absolute component.
+func s2FaceUV(p s2Point) (face int, u, v float64) {
+	ax, ay, az := math.Abs(p[0]), math.Abs(p[1]), math.Abs(p[2])
+	switch {
+	case ax >= ay && ax >= az:
+		face = 0
+	case ay >= az:
+		face = 1
+	default:
+		face = 2
+	}
+	if p[face] < 0 {
+		face += 3
+	}
+	u, v, _ = s2FaceXYZToUV(face, p)
+	return
+}
+
+// s2FaceXYZToUV projects a point onto the given face. Returns false if the
+// point is in the opposite hemisphere.
+func s2FaceXYZToUV(face int, p s2Point) (u, v float64, ok bool) {
+	switch face {
+	case 0:
+		return p[1] / p[0], p[2] / p[0], p[0] > 0
+	case 1:
+		return -p[0] / p[1], p[2] / p[1], p[1] > 0
+	case 2:
+		return -p[0] / p[2], -p[1] / p[2], p[2] > 0
+	case 3:
+		return p[2] / p[0], p[1] / p[0], p[0] < 0
+	case 4:
+		return p[2] / p[1], -p[0] / p[1], p[1] < 0
+	default:
+		return -p[1] / p[2], -p[0] / p[2], p[2] < 0
+	}
+}
+
+func s2FaceUVToXYZ(face int, u, v float64) s2Point {
+	switch face {
+	case 0:
+		return s2Point{1, u, v}
+	case 1:
+		return s2Point{-u, 1, v}
+	case 2:
+		return s2Point{-u, -v, 1}
+	case 3:
+		return s2Point{-1, -v, -u}
+	case 4:
+		return s2Point{v, -1, -u}
+	default:
+		return s2Point{v, u, -1}
+	}
+}
+
+// s2UVToST and s2STToUV apply the quadratic transform which S2 uses to make
+// cells of the same level roughly equal in area.
+func s2UVToST(u float64) float64 {
+	if u >= 0 {
+		return 0.5 * math.Sqrt(1+3*u)
+	}
+	return 1 - 0.5*math.Sqrt(1-3*u)
+}
+
+func s2STToUV(s float64) float64 {
+	if s >= 0.5 {
+		return (4*s*s - 1) / 3
+	}
+	return (1 - 4*(1-s)*(1-s)) / 3
+}
+
+func s2STToIJ(s float64) int {
+	i := int(math.Floor(s * s2MaxSize))
+	if i < 0 {
+		return 0
+	} else if i >= s2MaxSize {
+		return s2MaxSize - 1
+	}
+	return i
+}
+
+// s2CellIDFromFaceIJ returns the leaf cell at the given face and (i, j)
+// coordinates, by walking the Hilbert curve four bits at a time.
+func s2CellIDFromFaceIJ(face, i, j int) S2CellID {
+	n := uint64(face) << (s2PosBits - 1)
+	state := face & s2SwapMask
+	for k := 7; k >= 0; k-- {
+		const mask = 1<<s2LookupBits - 1
+		state += (i >> (k * s2LookupBits) & mask) << (s2LookupBits + 2)
+		state += (j >> (k * s2LookupBits) & mask) << 2
+		state = s2Lookup.pos[state]
+		n |= uint64(state>>2) << (k * 2 * s2LookupBits)
+		state &= s2SwapMask | s2InvertMask
+	}
+	return S2CellID(n*2 + 1)
+}
+
+// s2PolygonBounds returns the bounding box of a spherical polygon with
+// geodesic edges, given its vertices, the longitude of its center and
+// whether it contains the poles.
+func s2PolygonBounds(vs []s2Point, center float64, north, south bool) Area {
+	b := Area{MinLat: 90, MaxLat: -90, MinLon: LonMin, MaxLon: LonMax}
+	for k := range vs {
+		lo, hi := s2ArcLatRange(vs[k], vs[(k+1)%len(vs)])
+		b.MinLat, b.MaxLat = math.Min(b.MinLat, lo), math.Max(b.MaxLat, hi)
+	}
+
+	if north {
+		b.MaxLat = 90
+	}
+	if south {
+		b.MinLat = -90
+	}
+	if north || south {
+		return b
+	}
+
+	// longitudes are monotonic along edges which do not cross a pole,
+	// measure them relative to the center to detect antimeridian crossings
+	min, max := 0.0, 0.0
+	for _, v := range vs {
+		_, lon := s2XYZToLatLon(v)
+		d := math.Remainder(lon-center, 360)
+		min, max = math.Min(min, d), math.Max(max, d)
+	}
+	if lo, hi := center+min, center+max; lo >= LonMin-1e-9 && hi <= LonMax+1e-9 {
+		b.MinLon, b.MaxLon = math.Max(lo, LonMin), math.Min(hi, LonMax)
+	}
+	return b
+}
+
+// s2RelatePolygon returns the relation of an area to a spherical polygon
+// with geodesic edges, given its vertices, bounds and containment test.
+func s2RelatePolygon(vs []s2Point, b, a Area, contains func(lat, lon float64) bool) Relation {
+	if a.MaxLat < b.MinLat || a.MinLat > b.MaxLat || a.MaxLon < b.MinLon || a.MinLon > b.MaxLon {
+		return Outside
+	}
+
+	// if the polygon boundary intersects the area, the area is either
+	// partially covered or contains the polygon entirely
+	for k := range vs {
+		if s2ArcIntersects(vs[k], vs[(k+1)%len(vs)], a) {
+			return Boundary
+		}
+	}
+
+	// otherwise, the area is either entirely inside or outside the polygon
+	if contains(a.MinLat, a.MinLon) {
+		return Inside
+	}
+	return Outside
+}
+
+// s2ArcLatRange returns the latitude range of the shorter great circle arc
+// between two points.
+func s2ArcLatRange(a, b s2Point) (min, max float64) {
+	latA, _ := s2XYZToLatLon(a)
+	latB, _ := s2XYZToLatLon(b)
+	min, max = math.Min(latA, latB), math.Max(latA, latB)
+
+	// the northernmost point of the great circle, and its antipode
+	n := a.cross(b).normalize()
+	if n.isZero() {
+		return
+	}
+	top := s2Point{-n[2] * n[0], -n[2] * n[1], 1 - n[2]*n[2]}.normalize()
+	if top.onArc(a, b, n) {
+		lat, _ := s2XYZToLatLon(top)
+		max = math.Max(max, lat)
+	}
+	if bottom := (s2Point{-top[0], -top[1], -top[2]}); bottom.onArc(a, b, n) {
+		lat, _ := s2XYZToLatLon(bottom)
+		min = math.Min(min, lat)
+	}
+	return
+}
+
+// s2ArcIntersects returns true if the shorter great circle arc between two
+// points intersects the area.
+func s2ArcIntersects(a, b s2Point, area Area) bool {
+	for _, p := range []s2Point{a, b} {
+		if lat, lon := s2XYZToLatLon(p); area.Contains(lat, lon) {
+			return true
+		}
+	}
+
+	n := a.cross(b).normalize()
+	if n.isZero() {
+		return false
+	}
+
+	// crossings with the meridian edges, which are great circle arcs; arcs
+	// on the same great circle are handled by the parallel edges below
+	for _, lon := range []float64{area.MinLon, area.MaxLon} {
+		c, d := s2LatLonToXYZ(area.MinLat, lon), s2LatLonToXYZ(area.MaxLat, lon)
+		m := c.cross(d).normalize()
+		x := n.cross(m)
+		if x.dot(x) < 1e-24 {
+			continue
+		}
+		x = x.normalize()
+		for _, p := range []s2Point{x, {-x[0], -x[1], -x[2]}} {
+			if p.onArc(a, b, n) && p.onArc(c, d, m) {
+				return true
+			}
+		}
+	}
+
+	// crossings with the parallel edges, which are small circles; solve for
+	// the points of the great circle at the latitude of each edge
+	r2 := n[0]*n[0] + n[1]*n[1]
+	if r2 == 0 {
+		return false
+	}
+	for _, lat := range []float64{area.MinLat, area.MaxLat} {
+		z := math.Sin(deg2rad(lat))
+		d := -n[2] * z
+		h2 := (1-z*z)/r2 - d*d/(r2*r2)
+		if h2 < 0 {
+			continue
+		}
+		h := math.Sqrt(h2)
+		fx, fy := n[0]*d/r2, n[1]*d/r2
+		for _, sign := range []float64{-1, 1} {
+			p := s2Point{fx - sign*h*n[1], fy + sign*h*n[0], z}
+			if _, lon := s2XYZToLatLon(p); p.onArc(a, b, n) && area.MinLon <= lon && lon <= area.MaxLon {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// --------------------------------------------------------------------
+
+// s2Lookup holds the tables which convert between (i, j) coordinates and
+// Hilbert curve positions, four bits per axis at a time, for each of the
+// four curve orientations.
+var s2Lookup = newS2LookupTables()
+
+type s2LookupTables struct {
+	pos, ij [1 << (2*s2LookupBits + 2)]int
+}
+
+func newS2LookupTables() *s2LookupTables {
+	// sub-cell (i, j) positions in Hilbert order, by orientation
+	posToIJ := [4][4]int{
+		{0, 1, 3, 2}, // canonical order
+		{0, 2, 3, 1}, // axes swapped
+		{3, 2, 0, 1}, // bits inverted
+		{3, 1, 0, 2}, // swapped & inverted
+	}
+	posToOrientation := [4]int{s2SwapMask, 0, 0, s2InvertMask | s2SwapMask}
+
+	t := new(s2LookupTables)
+	var fill func(level, i, j, origOrientation, pos, orientation int)
+	fill = func(level, i, j, origOrientation, pos, orientation int) {
+		if level == s2LookupBits {
+			ij := i<<s2LookupBits + j
+			t.pos[ij<<2+origOrientation] = pos<<2 + orientation
+			t.ij[pos<<2+origOrientation] = ij<<2 + orientation
+			return
+		}
+		for k, r := range posToIJ[orientation] {
+			fill(level+1, i<<1+r>>1, j<<1+r&1, origOrientation, pos<<2+k, orientation^posToOrientation[k])
+		}
+	}
+	for o := 0; o < 4; o++ {
+		fill(0, 0, 0, o, 0, o)
+	}
+	return t
+}
@@ -0,0 +1,245 @@
+package geohashi
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("S2CellID", func() {
+	It("should encode locations", func() {
+		for _, tc := range []struct {
+			lat, lon float64
+			prefix   string
+		}{
+			{40.7128, -74.006, "89c25"},  // New York
+			{51.5074, -0.1278, "4876"},   // London
+			{37.7749, -122.4194, "8085"}, // San Francisco
+			{-33.8688, 151.2093, "6b12"}, // Sydney
+			{35.6762, 139.6503, "6018"},  // Tokyo
+		} {
+			c, err := S2CellIDFromLatLon(tc.lat, tc.lon, S2LevelMax)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Token()).To(HavePrefix(tc.prefix))
+			Expect(c.Level()).To(Equal(S2LevelMax))
+			Expect(c.IsValid()).To(BeTrue())
+		}
+
+		c, err := S2CellIDFromLatLon(0, 0, S2LevelMax)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c).To(Equal(S2CellID(0x1000000000000001)))
+
+		c, err = S2CellIDFromLatLon(-90, 0, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Face()).To(Equal(5))
+
+		_, err = S2CellIDFromLatLon(91, 0, 10)
+		Expect(err).To(MatchError(errInvalidCoordinates))
+		_, err = S2CellIDFromLatLon(0, 0, 31)
+		Expect(err).To(MatchError(errInvalidS2Cell))
+	})
+
+	It("should convert tokens", func() {
+		Expect(S2CellID(0).Token()).To(Equal("X"))
+		Expect(S2CellID(1 << 60).Token()).To(Equal("1"))
+		Expect(S2CellID(0xb000000000000000).Token()).To(Equal("b"))
+
+		c, err := S2CellIDFromLatLon(40.7128, -74.006, 12)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(S2CellIDFromToken(c.Token())).To(Equal(c))
+		Expect(S2CellIDFromToken("89C25")).To(Equal(S2CellID(0x89c2500000000000)))
+
+		for _, token := range []string{"", "X", "0", "c", "89c25x", "89c259000000000001"} {
+			_, err := S2CellIDFromToken(token)
+			Expect(err).To(MatchError(errInvalidS2Cell), "token %q", token)
+		}
+	})
+
+	It("should navigate the hierarchy", func() {
+		c, err := S2CellIDFromLatLon(51.5074, -0.1278, 10)
+		Expect(err).NotTo(HaveOccurred())
+
+		parent := c.Parent()
+		Expect(parent.Level()).To(Equal(9))
+		Expect(parent.ContainsCell(c)).To(BeTrue())
+		Expect(parent.Children()).To(ContainElement(c))
+		Expect(c.ContainsCell(parent)).To(BeFalse())
+
+		children := c.Children()
+		Expect(children).To(HaveLen(4))
+		for _, child := range children {
+			Expect(child.Level()).To(Equal(11))
+			Expect(child.Parent()).To(Equal(c))
+			Expect(c.ContainsCell(child)).To(BeTrue())
+		}
+		Expect(sort.SliceIsSorted(children, func(i, j int) bool { return children[i] < children[j] })).To(BeTrue())
+
+		face := S2CellID(1 << 60)
+		Expect(face.Level()).To(Equal(0))
+		Expect(face.Parent()).To(Equal(S2CellID(0)))
+
+		leaf, err := S2CellIDFromLatLon(51.5074, -0.1278, S2LevelMax)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(leaf.Children()).To(BeNil())
+		Expect(c.ContainsCell(leaf)).To(BeTrue())
+	})
+
+	It("should convert between positions and face coordinates", func() {
+		rnd := rand.New(rand.NewSource(1))
+		for n := 0; n < 1000; n++ {
+			face, i, j := rnd.Intn(6), rnd.Intn(s2MaxSize), rnd.Intn(s2MaxSize)
+			c := s2CellIDFromFaceIJ(face, i, j)
+			Expect(c.Level()).To(Equal(S2LevelMax))
+
+			f, ci, cj := c.faceIJ()
+			Expect([]int{f, ci, cj}).To(Equal([]int{face, i, j}))
+		}
+	})
+
+	It("should contain its locations", func() {
+		rnd := rand.New(rand.NewSource(1))
+		for n := 0; n < 1000; n++ {
+			lat, lon := rnd.Float64()*178-89, rnd.Float64()*358-179
+			level := rnd.Intn(S2LevelMax + 1)
+
+			c, err := S2CellIDFromLatLon(lat, lon, level)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Level()).To(Equal(level))
+			Expect(c.Contains(lat, lon)).To(BeTrue(), "%v, %v @ %d", lat, lon, level)
+			Expect(c.Bounds().Contains(lat, lon)).To(BeTrue(), "%v, %v @ %d", lat, lon, level)
+
+			clat, clon := c.Center()
+			Expect(c.Contains(clat, clon)).To(BeTrue())
+		}
+	})
+
+	It("should calculate bounds", func() {
+		north, err := S2CellIDFromLatLon(89.9, 45, 3)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(north.Bounds()).To(Equal(Area{MinLat: north.Bounds().MinLat, MaxLat: 90, MinLon: LonMin, MaxLon: LonMax}))
+
+		// level 0 cells of equatorial faces bulge beyond their vertices
+		face := S2CellID(1 << 60)
+		b := face.Bounds()
+		Expect(b.MaxLat).To(BeNumerically("~", 45, 1e-9))
+		Expect(b.MinLat).To(BeNumerically("~", -45, 1e-9))
+		Expect(b.MinLon).To(BeNumerically("~", -45, 1e-9))
+		Expect(b.MaxLon).To(BeNumerically("~", 45, 1e-9))
+		for _, v := range face.Vertices() {
+			Expect(math.Abs(v.Lat)).To(BeNumerically("~", 35.26, 0.01))
+		}
+
+		// cells crossing the antimeridian span all longitudes
+		b = S2CellID(0x7000000000000000).Bounds()
+		Expect([]float64{b.MinLon, b.MaxLon}).To(Equal([]float64{LonMin, LonMax}))
+
+		c, err := S2CellIDFromLatLon(0, 179.999, 6)
+		Expect(err).NotTo(HaveOccurred())
+		b = c.Bounds()
+		Expect(b.MinLon).To(BeNumerically(">", 170))
+		Expect(b.MaxLon).To(Equal(LonMax))
+	})
+
+	It("should relate areas", func() {
+		c, err := S2CellIDFromLatLon(51.5074, -0.1278, 10)
+		Expect(err).NotTo(HaveOccurred())
+		b := c.Bounds()
+		lat, lon := c.Center()
+
+		Expect(c.Relate(Area{MinLat: lat - 1e-3, MaxLat: lat + 1e-3, MinLon: lon - 1e-3, MaxLon: lon + 1e-3})).To(Equal(Inside))
+		Expect(c.Relate(Area{MinLat: b.MinLat - 1, MaxLat: b.MaxLat + 1, MinLon: b.MinLon - 1, MaxLon: b.MaxLon + 1})).To(Equal(Boundary))
+		Expect(c.Relate(Area{MinLat: lat, MaxLat: b.MaxLat + 1, MinLon: lon - 1e-3, MaxLon: lon + 1e-3})).To(Equal(Boundary))
+		Expect(c.Relate(Area{MinLat: b.MaxLat + 1, MaxLat: b.MaxLat + 2, MinLon: lon, MaxLon: lon + 1})).To(Equal(Outside))
+
+		// the corners of the bounding box are outside of the cell
+		vs := c.Vertices()
+		Expect(c.Relate(Area{MinLat: b.MaxLat - 1e-6, MaxLat: b.MaxLat, MinLon: b.MaxLon - 1e-6, MaxLon: b.MaxLon})).To(Equal(Outside))
+		Expect(c.Relate(Area{MinLat: vs[0].Lat - 1e-6, MaxLat: vs[0].Lat + 1e-6, MinLon: vs[0].Lon - 1e-6, MaxLon: vs[0].Lon + 1e-6})).To(Equal(Boundary))
+	})
+
+	It("should be covered by hashes", func() {
+		c, err := S2CellIDFromLatLon(51.5074, -0.1278, 12)
+		Expect(err).NotTo(HaveOccurred())
+
+		cover := CoverFence(c, 22)
+		Expect(cover.Interior).NotTo(BeEmpty())
+		Expect(cover.Boundary).NotTo(BeEmpty())
+		for _, h := range cover.Interior {
+			a := h.Decode()
+			Expect(c.Contains(a.MinLat, a.MinLon)).To(BeTrue())
+			Expect(c.Contains(a.MaxLat, a.MaxLon)).To(BeTrue())
+		}
+
+		// every location within the cell is covered
+		hashes := append(cover.Interior, cover.Boundary...)
+		b := c.Bounds()
+		rnd := rand.New(rand.NewSource(1))
+		for n := 0; n < 1000; n++ {
+			lat := b.MinLat + rnd.Float64()*(b.MaxLat-b.MinLat)
+			lon := b.MinLon + rnd.Float64()*(b.MaxLon-b.MinLon)
+			if !c.Contains(lat, lon) {
+				continue
+			}
+
+			covered := false
+			for _, h := range hashes {
+				if h.Decode().Contains(lat, lon) {
+					covered = true
+					break
+				}
+			}
+			Expect(covered).To(BeTrue(), "%v, %v", lat, lon)
+		}
+	})
+
+	It("should cover hashes", func() {
+		h := EncodeWithPrecision(40.7128, -74.006, 16)
+		cells := h.S2Cells(14)
+		Expect(cells).NotTo(BeEmpty())
+		Expect(len(cells)).To(BeNumerically("<", 16))
+		Expect(sort.SliceIsSorted(cells, func(i, j int) bool { return cells[i] < cells[j] })).To(BeTrue())
+
+		a := h.Decode()
+		for _, c := range cells {
+			Expect(c.Level()).To(Equal(14))
+			Expect(c.Relate(a)).NotTo(Equal(Outside))
+		}
+
+		// every location within the hash is covered
+		rnd := rand.New(rand.NewSource(1))
+		for n := 0; n < 1000; n++ {
+			lat := a.MinLat + rnd.Float64()*(a.MaxLat-a.MinLat)
+			lon := a.MinLon + rnd.Float64()*(a.MaxLon-a.MinLon)
+
+			c, err := S2CellIDFromLatLon(lat, lon, 14)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cells).To(ContainElement(c), "%v, %v", lat, lon)
+		}
+
+		Expect(h.S2Cells(-1)).To(BeNil())
+		Expect(Area{MinLat: 1, MaxLat: 0}.S2Cells(10)).To(BeNil())
+		Expect(Area{MinLat: -90, MaxLat: 90, MinLon: -180, MaxLon: 180}.S2Cells(0)).To(HaveLen(6))
+	})
+
+	It("should limit covers", func() {
+		h := EncodeWithPrecision(40.7128, -74.006, 16)
+		Expect(h.S2Cells(S2LevelMax)).To(BeNil())
+
+		_, err := h.Decode().S2CellsLimit(S2LevelMax, MaxCoverCells)
+		Expect(err).To(Equal(errTooManyCells))
+
+		cells, err := h.Decode().S2CellsLimit(14, 16)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cells).To(Equal(h.S2Cells(14)))
+
+		_, err = h.Decode().S2CellsLimit(14, len(cells)-1)
+		Expect(err).To(Equal(errTooManyCells))
+		_, err = h.Decode().S2CellsLimit(S2LevelMax+1, 16)
+		Expect(err).To(Equal(errInvalidS2Cell))
+		_, err = Area{MinLat: 1, MaxLat: 0}.S2CellsLimit(10, 16)
+		Expect(err).To(Equal(errInvalidArea))
+	})
+})
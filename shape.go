@@ -0,0 +1,241 @@
+package geohashi
+
+import "math"
+
+// Point is a lat/lon coordinate pair.
+type Point struct{ Lat, Lon float64 }
+
+// valid returns true if the point is within the valid (geographic) range.
+func (p Point) valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lon >= -180 && p.Lon <= 180
+}
+
+// Relation describes the spatial relation of an area to a fence.
+type Relation uint8
+
+const (
+	// Outside areas are disjoint from the fence.
+	Outside Relation = iota
+	// Boundary areas intersect with the boundary of the fence.
+	Boundary
+	// Inside areas are entirely within the fence.
+	Inside
+)
+
+// Fence is a region which can be covered by hashes.
+type Fence interface {
+	// Bounds returns the bounding box of the fence.
+	Bounds() Area
+	// Contains returns true if coordinates are within the fence.
+	Contains(lat, lon float64) bool
+	// Relate returns the relation of an area to the fence.
+	Relate(a Area) Relation
+}
+
+// --------------------------------------------------------------------
+
+// Bounds implements Fence.
+func (a Area) Bounds() Area { return a }
+
+// ring returns the area as a closed, counter-clockwise ring.
+func (a Area) ring() []Point {
+	return []Point{
+		{Lat: a.MinLat, Lon: a.MinLon},
+		{Lat: a.MinLat, Lon: a.MaxLon},
+		{Lat: a.MaxLat, Lon: a.MaxLon},
+		{Lat: a.MaxLat, Lon: a.MinLon},
+		{Lat: a.MinLat, Lon: a.MinLon},
+	}
+}
+
+// Relate implements Fence.
+func (a Area) Relate(o Area) Relation {
+	if o.MaxLat < a.MinLat || o.MinLat > a.MaxLat || o.MaxLon < a.MinLon || o.MinLon > a.MaxLon {
+		return Outside
+	}
+	if a.Contains(o.MinLat, o.MinLon) && a.Contains(o.MaxLat, o.MaxLon) {
+		return Inside
+	}
+	return Boundary
+}
+
+// --------------------------------------------------------------------
+
+// Circle is a circular fence with a radius in meters.
+type Circle struct{ Lat, Lon, Radius float64 }
+
+// Bounds implements Fence. Circles crossing the antimeridian span all
+// longitudes.
+func (c Circle) Bounds() Area {
+	areas := radiusAreas(c.Lat, c.Lon, c.Radius)
+	if len(areas) != 1 {
+		return Area{MinLat: areas[0].MinLat, MaxLat: areas[0].MaxLat, MinLon: LonMin, MaxLon: LonMax}
+	}
+	return areas[0]
+}
+
+// Contains implements Fence.
+func (c Circle) Contains(lat, lon float64) bool {
+	return Distance(c.Lat, c.Lon, lat, lon) <= c.Radius
+}
+
+// Relate implements Fence.
+func (c Circle) Relate(a Area) Relation {
+	if c.Contains(a.MinLat, a.MinLon) && c.Contains(a.MinLat, a.MaxLon) &&
+		c.Contains(a.MaxLat, a.MinLon) && c.Contains(a.MaxLat, a.MaxLon) {
+		return Inside
+	}
+	if c.minDistance(a) <= c.Radius {
+		return Boundary
+	}
+	return Outside
+}
+
+// minDistance returns the minimum distance from the centre to the area.
+func (c Circle) minDistance(a Area) float64 {
+	if a.Contains(c.Lat, c.Lon) {
+		return 0
+	}
+
+	// nearest points on the parallel edges
+	lon := clampLon(c.Lon, a.MinLon, a.MaxLon)
+	dist := math.Min(Distance(c.Lat, c.Lon, a.MinLat, lon), Distance(c.Lat, c.Lon, a.MaxLat, lon))
+
+	// nearest points on the meridian edges
+	for _, edge := range []float64{a.MinLon, a.MaxLon} {
+		lat := math.Copysign(90, c.Lat)
+		if dλ := deg2rad(edge - c.Lon); math.Cos(dλ) > 0 {
+			lat = rad2deg(math.Atan(math.Tan(deg2rad(c.Lat)) / math.Cos(dλ)))
+		}
+		lat = math.Max(a.MinLat, math.Min(a.MaxLat, lat))
+		dist = math.Min(dist, Distance(c.Lat, c.Lon, lat, edge))
+	}
+	return dist
+}
+
+// clampLon returns the longitude within min..max nearest to lon.
+func clampLon(lon, min, max float64) float64 {
+	if min <= lon && lon <= max {
+		return lon
+	}
+	if lonDelta(lon, min) < lonDelta(lon, max) {
+		return min
+	}
+	return max
+}
+
+// lonDelta returns the absolute difference between two longitudes.
+func lonDelta(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	return math.Min(d, 360-d)
+}
+
+// --------------------------------------------------------------------
+
+// Polygon is a fence defined by a simple closed ring of points. Edges are
+// treated as straight lines in lat/lon space and must not cross the
+// antimeridian.
+type Polygon []Point
+
+// ringPolygon converts a closed ring, as used by GeoJSON, WKT and WKB, into a
+// polygon. It returns false if the ring is not closed or has fewer than three
+// distinct points.
+func ringPolygon(ring []Point) (Polygon, bool) {
+	if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+		return nil, false
+	}
+
+	a, b := ring[0], ring[0]
+	for _, pt := range ring[1 : len(ring)-1] {
+		if pt == a || pt == b {
+			continue
+		} else if a == b {
+			b = pt
+			continue
+		}
+		return Polygon(ring[:len(ring)-1]), true
+	}
+	return nil, false
+}
+
+// ring returns the polygon as a closed ring.
+func (p Polygon) ring() []Point {
+	if len(p) == 0 {
+		return nil
+	}
+	return append(p[:len(p):len(p)], p[0])
+}
+
+// Bounds implements Fence.
+func (p Polygon) Bounds() Area {
+	if len(p) == 0 {
+		return Area{}
+	}
+
+	a := Area{MinLat: p[0].Lat, MaxLat: p[0].Lat, MinLon: p[0].Lon, MaxLon: p[0].Lon}
+	for _, pt := range p[1:] {
+		a.MinLat, a.MaxLat = math.Min(a.MinLat, pt.Lat), math.Max(a.MaxLat, pt.Lat)
+		a.MinLon, a.MaxLon = math.Min(a.MinLon, pt.Lon), math.Max(a.MaxLon, pt.Lon)
+	}
+	return a
+}
+
+// Contains implements Fence.
+func (p Polygon) Contains(lat, lon float64) bool {
+	in := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if (a.Lat > lat) != (b.Lat > lat) && lon < (b.Lon-a.Lon)*(lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			in = !in
+		}
+	}
+	return in
+}
+
+// Relate implements Fence.
+func (p Polygon) Relate(a Area) Relation {
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		if segmentIntersects(p[j], p[i], a) {
+			return Boundary
+		}
+	}
+	if p.Contains(a.MinLat, a.MinLon) {
+		return Inside
+	}
+	return Outside
+}
+
+// segmentIntersects uses Liang-Barsky clipping to test if a line segment
+// intersects with an area.
+func segmentIntersects(a, b Point, area Area) bool {
+	dLat, dLon := b.Lat-a.Lat, b.Lon-a.Lon
+	t0, t1 := 0.0, 1.0
+	for _, c := range [4][2]float64{
+		{-dLon, a.Lon - area.MinLon},
+		{dLon, area.MaxLon - a.Lon},
+		{-dLat, a.Lat - area.MinLat},
+		{dLat, area.MaxLat - a.Lat},
+	} {
+		p, q := c[0], c[1]
+		if p == 0 {
+			if q < 0 {
+				return false
+			}
+			continue
+		}
+
+		t := q / p
+		if p < 0 {
+			if t > t1 {
+				return false
+			}
+			t0 = math.Max(t0, t)
+		} else {
+			if t < t0 {
+				return false
+			}
+			t1 = math.Min(t1, t)
+		}
+	}
+	return true
+}
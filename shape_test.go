@@ -0,0 +1,80 @@
+package geohashi
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Area", func() {
+	subject := Area{MinLat: 10, MaxLat: 20, MinLon: 10, MaxLon: 20}
+
+	It("should relate", func() {
+		Expect(subject.Bounds()).To(Equal(subject))
+		Expect(subject.Relate(Area{MinLat: 12, MaxLat: 14, MinLon: 12, MaxLon: 14})).To(Equal(Inside))
+		Expect(subject.Relate(Area{MinLat: 12, MaxLat: 24, MinLon: 12, MaxLon: 14})).To(Equal(Boundary))
+		Expect(subject.Relate(Area{MinLat: 0, MaxLat: 30, MinLon: 0, MaxLon: 30})).To(Equal(Boundary))
+		Expect(subject.Relate(Area{MinLat: 22, MaxLat: 24, MinLon: 12, MaxLon: 14})).To(Equal(Outside))
+	})
+})
+
+var _ = Describe("Circle", func() {
+	subject := Circle{Lat: 51.5246, Lon: -0.0841, Radius: 1000}
+
+	It("should calculate bounds", func() {
+		b := subject.Bounds()
+		Expect(b.MinLat).To(BeNumerically("~", 51.5156, 1e-4))
+		Expect(b.MaxLat).To(BeNumerically("~", 51.5336, 1e-4))
+		Expect(b.MinLon).To(BeNumerically("~", -0.0985, 1e-4))
+		Expect(b.MaxLon).To(BeNumerically("~", -0.0697, 1e-4))
+
+		b = Circle{Lat: 0, Lon: 179.9, Radius: 100000}.Bounds()
+		Expect(b.MinLon).To(Equal(LonMin))
+		Expect(b.MaxLon).To(Equal(LonMax))
+	})
+
+	It("should check containment", func() {
+		Expect(subject.Contains(51.5256, -0.0875)).To(BeTrue())
+		Expect(subject.Contains(51.5308, -0.1238)).To(BeFalse())
+	})
+
+	It("should relate", func() {
+		Expect(subject.Relate(Area{MinLat: 51.52, MaxLat: 51.53, MinLon: -0.09, MaxLon: -0.08})).To(Equal(Inside))
+		Expect(subject.Relate(Area{MinLat: 51.52, MaxLat: 51.53, MinLon: -0.07, MaxLon: -0.06})).To(Equal(Boundary))
+		Expect(subject.Relate(Area{MinLat: 51.53, MaxLat: 51.54, MinLon: -0.07, MaxLon: -0.06})).To(Equal(Outside))
+		Expect(subject.Relate(Area{MinLat: 51.50, MaxLat: 51.55, MinLon: -0.10, MaxLon: -0.06})).To(Equal(Boundary))
+		Expect(subject.Relate(Area{MinLat: 51.50, MaxLat: 51.60, MinLon: -0.0841, MaxLon: -0.0841})).To(Equal(Boundary))
+	})
+
+	It("should relate near the antimeridian", func() {
+		c := Circle{Lat: 0, Lon: 179.99, Radius: 5000}
+		Expect(c.Relate(Area{MinLat: -0.01, MaxLat: 0.01, MinLon: -180, MaxLon: -179.9})).To(Equal(Boundary))
+		Expect(c.Relate(Area{MinLat: -0.01, MaxLat: 0.01, MinLon: -179.9, MaxLon: -179.8})).To(Equal(Outside))
+	})
+})
+
+var _ = Describe("Polygon", func() {
+	// an L-shaped polygon
+	subject := Polygon{{0, 0}, {0, 10}, {5, 10}, {5, 5}, {10, 5}, {10, 0}}
+
+	It("should calculate bounds", func() {
+		Expect(subject.Bounds()).To(Equal(Area{MinLat: 0, MaxLat: 10, MinLon: 0, MaxLon: 10}))
+		Expect(Polygon{}.Bounds()).To(Equal(Area{}))
+	})
+
+	It("should check containment", func() {
+		Expect(subject.Contains(1, 1)).To(BeTrue())
+		Expect(subject.Contains(4, 9)).To(BeTrue())
+		Expect(subject.Contains(9, 4)).To(BeTrue())
+		Expect(subject.Contains(6, 6)).To(BeFalse())
+		Expect(subject.Contains(-1, 1)).To(BeFalse())
+	})
+
+	It("should relate", func() {
+		Expect(subject.Relate(Area{MinLat: 1, MaxLat: 2, MinLon: 1, MaxLon: 2})).To(Equal(Inside))
+		Expect(subject.Relate(Area{MinLat: 4, MaxLat: 6, MinLon: 1, MaxLon: 2})).To(Equal(Inside))
+		Expect(subject.Relate(Area{MinLat: 4, MaxLat: 6, MinLon: 4, MaxLon: 6})).To(Equal(Boundary))
+		Expect(subject.Relate(Area{MinLat: 6, MaxLat: 9, MinLon: 6, MaxLon: 9})).To(Equal(Outside))
+		Expect(subject.Relate(Area{MinLat: -5, MaxLat: 15, MinLon: -5, MaxLon: 15})).To(Equal(Boundary))
+		Expect(subject.Relate(Area{MinLat: 20, MaxLat: 25, MinLon: 20, MaxLon: 25})).To(Equal(Outside))
+	})
+})
@@ -0,0 +1,283 @@
+package geohashi
+
+import (
+	"hash/maphash"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// numStripes is the number of lock stripes used to track value locations.
+const numStripes = 64
+
+// ShardedIndex is a spatial index which is safe for concurrent use by many
+// goroutines. Values are partitioned into shards by the parent cell of their
+// location at a coarse precision and each shard is protected by its own lock.
+type ShardedIndex[T comparable] struct {
+	prec    uint8
+	shards  []indexShard[T]
+	stripes [numStripes]indexStripe[T]
+	seed    maphash.Seed
+	size    atomic.Int64
+}
+
+type indexShard[T comparable] struct {
+	sync.RWMutex
+	*Index[T]
+}
+
+type indexStripe[T comparable] struct {
+	sync.Mutex
+	shards map[T]uint64
+}
+
+// NewShardedIndex inits a new index with 4^prec shards. The precision is
+// capped at 8.
+func NewShardedIndex[T comparable](prec uint8) *ShardedIndex[T] {
+	if prec < PrecisionMin {
+		prec = PrecisionMin
+	} else if prec > 8 {
+		prec = 8
+	}
+
+	x := &ShardedIndex[T]{
+		prec:   prec,
+		shards: make([]indexShard[T], 1<<(2*prec)),
+		seed:   maphash.MakeSeed(),
+	}
+	for i := range x.shards {
+		x.shards[i].Index = NewIndex[T]()
+	}
+	for i := range x.stripes {
+		x.stripes[i].shards = make(map[T]uint64)
+	}
+	return x
+}
+
+// Len returns the number of values in the index.
+func (x *ShardedIndex[T]) Len() int { return int(x.size.Load()) }
+
+// Get returns the location of a value.
+func (x *ShardedIndex[T]) Get(v T) (lat, lon float64, ok bool) {
+	st := x.stripe(v)
+	st.Lock()
+	defer st.Unlock()
+
+	n, ok := st.shards[v]
+	if !ok {
+		return 0, 0, false
+	}
+
+	sh := &x.shards[n]
+	sh.RLock()
+	defer sh.RUnlock()
+	return sh.Get(v)
+}
+
+// Insert inserts a value at a location. If the value already exists, it is
+// moved to the new location.
+func (x *ShardedIndex[T]) Insert(v T, lat, lon float64) error {
+	if !ValidCoordinates(lat, lon) {
+		return errInvalidCoordinates
+	}
+
+	st := x.stripe(v)
+	st.Lock()
+	defer st.Unlock()
+
+	return x.insert(st, v, lat, lon)
+}
+
+// Move updates the location of an existing value.
+func (x *ShardedIndex[T]) Move(v T, lat, lon float64) error {
+	if !ValidCoordinates(lat, lon) {
+		return errInvalidCoordinates
+	}
+
+	st := x.stripe(v)
+	st.Lock()
+	defer st.Unlock()
+
+	if _, ok := st.shards[v]; !ok {
+		return errNotFound
+	}
+	return x.insert(st, v, lat, lon)
+}
+
+// Delete removes a value from the index. It returns true if the value existed.
+func (x *ShardedIndex[T]) Delete(v T) bool {
+	st := x.stripe(v)
+	st.Lock()
+	defer st.Unlock()
+
+	n, ok := st.shards[v]
+	if !ok {
+		return false
+	}
+
+	sh := &x.shards[n]
+	sh.Lock()
+	sh.Index.Delete(v)
+	sh.Unlock()
+
+	delete(st.shards, v)
+	x.size.Add(-1)
+	return true
+}
+
+// Snapshot returns a consistent snapshot of all items in the index. Writes
+// are blocked while the snapshot is taken.
+func (x *ShardedIndex[T]) Snapshot() []Item[T] {
+	shards := make([]uint64, len(x.shards))
+	for i := range shards {
+		shards[i] = uint64(i)
+	}
+	x.rlock(shards)
+	defer x.runlock(shards)
+
+	res := make([]Item[T], 0, x.Len())
+	for i := range x.shards {
+		x.shards[i].Iterate(func(item Item[T]) bool {
+			res = append(res, item)
+			return true
+		})
+	}
+	return res
+}
+
+// Within returns all items within the area.
+func (x *ShardedIndex[T]) Within(a Area) []Item[T] {
+	shards := x.shardsOf(a)
+	x.rlock(shards)
+	defer x.runlock(shards)
+
+	var res []Item[T]
+	for _, n := range shards {
+		res = append(res, x.shards[n].Within(a)...)
+	}
+	return res
+}
+
+// Radius returns all items within radius meters of a location, ordered by
+// distance. The result is consistent, concurrent writes to the affected
+// shards are blocked while the query is running.
+func (x *ShardedIndex[T]) Radius(lat, lon, radius float64) []Neighbor[T] {
+	var shards []uint64
+	for _, a := range radiusAreas(lat, lon, radius) {
+		for _, n := range x.shardsOf(a) {
+			if i := sort.Search(len(shards), func(i int) bool { return shards[i] >= n }); i == len(shards) || shards[i] != n {
+				shards = append(shards, 0)
+				copy(shards[i+1:], shards[i:])
+				shards[i] = n
+			}
+		}
+	}
+
+	x.rlock(shards)
+	defer x.runlock(shards)
+
+	var res []Neighbor[T]
+	for _, n := range shards {
+		res = append(res, x.shards[n].Radius(lat, lon, radius)...)
+	}
+	sortNeighbors(res)
+	return res
+}
+
+// Nearest returns the k nearest items to a location, ordered by distance.
+// Unlike Radius, the result is not guaranteed to be consistent under
+// concurrent writes.
+func (x *ShardedIndex[T]) Nearest(lat, lon float64, k int) []Neighbor[T] {
+	return KNN(lat, lon, k, knnPrecision(x.Len(), k), x.lookup)
+}
+
+func (x *ShardedIndex[T]) insert(st *indexStripe[T], v T, lat, lon float64) error {
+	to := encode(lat, lon, x.prec).base()
+	from, ok := st.shards[v]
+
+	// lock both, the source and the target shard, in order to move
+	// values atomically
+	shards := []uint64{to}
+	if ok && from < to {
+		shards = []uint64{from, to}
+	} else if ok && from > to {
+		shards = []uint64{to, from}
+	}
+	x.lock(shards)
+	defer x.unlock(shards)
+
+	if ok && from != to {
+		x.shards[from].Index.Delete(v)
+	}
+	if err := x.shards[to].Insert(v, lat, lon); err != nil {
+		return err
+	}
+
+	st.shards[v] = to
+	if !ok {
+		x.size.Add(1)
+	}
+	return nil
+}
+
+func (x *ShardedIndex[T]) lookup(cell Hash) []Item[T] {
+	prec := cell.Precision()
+	if prec >= x.prec {
+		sh := &x.shards[cell.base()>>(2*(prec-x.prec))]
+		sh.RLock()
+		defer sh.RUnlock()
+		return sh.lookup(cell)
+	}
+
+	// cells with lower precision contain multiple shards
+	var res []Item[T]
+	shift := 2 * (x.prec - prec)
+	for n := cell.base() << shift; n < (cell.base()+1)<<shift; n++ {
+		sh := &x.shards[n]
+		sh.RLock()
+		sh.Iterate(func(item Item[T]) bool {
+			res = append(res, item)
+			return true
+		})
+		sh.RUnlock()
+	}
+	return res
+}
+
+// shardsOf returns the (sorted) shard numbers which intersect with the area.
+func (x *ShardedIndex[T]) shardsOf(a Area) []uint64 {
+	cells := a.Cover(x.prec)
+	res := make([]uint64, 0, len(cells))
+	for _, c := range cells {
+		res = append(res, c.base())
+	}
+	return res
+}
+
+func (x *ShardedIndex[T]) stripe(v T) *indexStripe[T] {
+	return &x.stripes[maphash.Comparable(x.seed, v)%numStripes]
+}
+
+func (x *ShardedIndex[T]) lock(shards []uint64) {
+	for _, n := range shards {
+		x.shards[n].Lock()
+	}
+}
+
+func (x *ShardedIndex[T]) unlock(shards []uint64) {
+	for _, n := range shards {
+		x.shards[n].Unlock()
+	}
+}
+
+func (x *ShardedIndex[T]) rlock(shards []uint64) {
+	for _, n := range shards {
+		x.shards[n].RLock()
+	}
+}
+
+func (x *ShardedIndex[T]) runlock(shards []uint64) {
+	for _, n := range shards {
+		x.shards[n].RUnlock()
+	}
+}
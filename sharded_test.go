@@ -0,0 +1,141 @@
+package geohashi
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ShardedIndex", func() {
+	var subject *ShardedIndex[string]
+
+	BeforeEach(func() {
+		subject = NewShardedIndex[string](4)
+		Expect(subject.Insert("liverpool-st", 51.5178, -0.0823)).To(Succeed())
+		Expect(subject.Insert("old-street", 51.5256, -0.0875)).To(Succeed())
+		Expect(subject.Insert("paris", 48.8584, 2.2945)).To(Succeed())
+		Expect(subject.Insert("fiji", -17.7134, 178.065)).To(Succeed())
+		Expect(subject.Insert("samoa", -13.759, -172.1046)).To(Succeed())
+	})
+
+	It("should insert/move/delete", func() {
+		Expect(subject.Len()).To(Equal(5))
+		Expect(subject.shards).To(HaveLen(256))
+		Expect(subject.Insert("x", 89, 0)).To(MatchError(errInvalidCoordinates))
+
+		Expect(subject.Move("paris", 51.5014, -0.1419)).To(Succeed())
+		Expect(subject.Move("berlin", 52.52, 13.405)).To(MatchError(errNotFound))
+		Expect(subject.Len()).To(Equal(5))
+
+		lat, lon, ok := subject.Get("paris")
+		Expect(ok).To(BeTrue())
+		Expect(lat).To(Equal(51.5014))
+		Expect(lon).To(Equal(-0.1419))
+
+		Expect(subject.Delete("paris")).To(BeTrue())
+		Expect(subject.Delete("paris")).To(BeFalse())
+		Expect(subject.Len()).To(Equal(4))
+		Expect(subject.Snapshot()).To(HaveLen(4))
+	})
+
+	It("should query", func() {
+		Expect(subject.Within(Area{MinLat: 51.5, MaxLat: 51.6, MinLon: -0.1, MaxLon: 0})).To(HaveLen(2))
+
+		res := subject.Radius(-15, 180, 900000)
+		Expect(res).To(HaveLen(2))
+		Expect(res[0].Value).To(Equal("fiji"))
+		Expect(res[1].Value).To(Equal("samoa"))
+
+		res = subject.Nearest(51.5246, -0.0841, 3)
+		Expect(res).To(HaveLen(3))
+		Expect(res[0].Value).To(Equal("old-street"))
+		Expect(res[2].Value).To(Equal("paris"))
+
+		Expect(subject.Nearest(0, 0, 10)).To(HaveLen(5))
+	})
+
+	It("should support concurrent writes", func() {
+		index := NewShardedIndex[int](3)
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				rnd := rand.New(rand.NewSource(int64(w)))
+				for i := 0; i < 2000; i++ {
+					v := rnd.Intn(100)
+					lat, lon := rnd.Float64()*160-80, rnd.Float64()*360-180
+					switch rnd.Intn(10) {
+					case 0:
+						index.Delete(v)
+					default:
+						Expect(index.Insert(v, lat, lon)).To(Succeed())
+					}
+				}
+			}(w)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer GinkgoRecover()
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				// values must never appear twice
+				seen := make(map[int]bool)
+				for _, item := range index.Snapshot() {
+					Expect(seen).NotTo(HaveKey(item.Value))
+					seen[item.Value] = true
+				}
+
+				seen = make(map[int]bool)
+				for _, n := range index.Radius(0, 0, 5000000) {
+					Expect(seen).NotTo(HaveKey(n.Value))
+					seen[n.Value] = true
+				}
+
+				index.Nearest(0, 0, 5)
+			}
+		}()
+		wg.Wait()
+
+		snap := index.Snapshot()
+		Expect(snap).To(HaveLen(index.Len()))
+
+		values := make([]int, 0, len(snap))
+		for _, item := range snap {
+			lat, lon, ok := index.Get(item.Value)
+			Expect(ok).To(BeTrue())
+			Expect(lat).To(Equal(item.Lat))
+			Expect(lon).To(Equal(item.Lon))
+			values = append(values, item.Value)
+		}
+		sort.Ints(values)
+		Expect(values).To(HaveLen(index.Len()))
+	})
+
+})
+
+func BenchmarkShardedIndex_Move(b *testing.B) {
+	const n = 1000000
+	rnd := rand.New(rand.NewSource(1))
+	index := NewShardedIndex[int](4)
+	for i := 0; i < n; i++ {
+		_ = index.Insert(i, rnd.Float64()*20+40, rnd.Float64()*20-10)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		rnd := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			_ = index.Move(rnd.Intn(n), rnd.Float64()*20+40, rnd.Float64()*20-10)
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package geohashi
+
+import (
+	"errors"
+	"math/bits"
+	"time"
+)
+
+var errInvalidTime = errors.New("geohashi: invalid time")
+
+// Spatio-temporal hash limits. Three dimensions of 19 bits each, plus the
+// precision, fit into 64 bits.
+const (
+	SpaceTimePrecisionMax = 19
+
+	precisionOffset3 = 3 * SpaceTimePrecisionMax
+	baseMask3        = 1<<precisionOffset3 - 1
+)
+
+// SpaceTime configures spatio-temporal hashes. Time is divided into buckets
+// of Resolution, starting at Epoch. At maximum precision, 2^19 buckets are
+// available, e.g. one year at a resolution of one minute, 59 years at a
+// resolution of one hour, or 1435 years at a resolution of one day. Each
+// lower precision doubles the bucket duration, just like it doubles the size
+// of cells.
+type SpaceTime struct {
+	Epoch      time.Time
+	Resolution time.Duration
+}
+
+// SpaceTimeHash is a numeric key which interleaves latitude, longitude and
+// time. Keys of events which are close in space and time are likely to be
+// close numerically.
+type SpaceTimeHash uint64
+
+func newSpaceTimeHash(base uint64, prec uint8) SpaceTimeHash {
+	return SpaceTimeHash(base) | SpaceTimeHash(prec)<<precisionOffset3
+}
+
+// Encode converts a location and time into a hash with maximum precision.
+func (st SpaceTime) Encode(lat, lon float64, t time.Time) (SpaceTimeHash, error) {
+	return st.EncodeWithPrecision(lat, lon, t, SpaceTimePrecisionMax)
+}
+
+// EncodeWithPrecision converts a location and time into a hash.
+func (st SpaceTime) EncodeWithPrecision(lat, lon float64, t time.Time, prec uint8) (SpaceTimeHash, error) {
+	if prec < PrecisionMin || prec > SpaceTimePrecisionMax {
+		return 0, errInvalidPrecision
+	}
+	if !ValidCoordinates(lat, lon) {
+		return 0, errInvalidCoordinates
+	}
+
+	z, err := st.bucket(t)
+	if err != nil {
+		return 0, err
+	}
+
+	x := gridIndex((lat-LatMin)/latScale, prec)
+	y := gridIndex((lon-LonMin)/lonScale, prec)
+	return newSpaceTimeHash(interleave3(x, y, z>>(SpaceTimePrecisionMax-prec)), prec), nil
+}
+
+// Decode decodes a hash into an area and the time interval [start, end).
+func (st SpaceTime) Decode(h SpaceTimeHash) (area Area, start, end time.Time) {
+	prec := h.Precision()
+	x, y, z := deinterleave3(h.base())
+	area = newHash(interleave64(x, y), prec).Decode()
+
+	shift := SpaceTimePrecisionMax - prec
+	start = st.bucketStart(z << shift)
+	end = st.bucketStart((z + 1) << shift)
+	return
+}
+
+// SpaceTimeRange is a half-open range of hashes with maximum precision.
+type SpaceTimeRange struct{ Min, Max SpaceTimeHash }
+
+// Contains returns true if the range contains the hash, which must have
+// maximum precision.
+func (r SpaceTimeRange) Contains(h SpaceTimeHash) bool {
+	return r.Min <= h && h < r.Max
+}
+
+// Ranges returns sorted ranges of maximum precision hashes which cover the
+// area during the time window [from, to] with cells of the given precision.
+// Adjacent ranges are merged. Parts of the time window outside of the
+// supported interval are ignored. The number of ranges grows with the
+// surface of the covered volume, long windows should therefore be queried at
+// lower precisions. It returns nil if the volume requires more than
+// MaxCoverCells ranges, use RangesLimit to choose a different limit.
+func (st SpaceTime) Ranges(a Area, from, to time.Time, prec uint8) []SpaceTimeRange {
+	res, _ := st.RangesLimit(a, from, to, prec, MaxCoverCells)
+	return res
+}
+
+// RangesLimit is like Ranges, but returns an error if the volume requires
+// more than maxRanges ranges.
+func (st SpaceTime) RangesLimit(a Area, from, to time.Time, prec uint8, maxRanges int) ([]SpaceTimeRange, error) {
+	if prec < PrecisionMin || prec > SpaceTimePrecisionMax {
+		return nil, errInvalidPrecision
+	}
+	if st.Resolution <= 0 || to.Before(from) {
+		return nil, errInvalidTime
+	}
+	if a.MinLat > a.MaxLat || a.MinLon > a.MaxLon {
+		return nil, errInvalidArea
+	}
+	if _, err := st.bucket(from); to.Before(st.Epoch) || (err != nil && !from.Before(st.Epoch)) {
+		return nil, nil
+	}
+
+	shift := SpaceTimePrecisionMax - prec
+	x0, x1, y0, y1 := a.gridBounds(prec)
+	z0, z1 := st.clampedBucket(from)>>shift, st.clampedBucket(to)>>shift
+
+	q := spaceTimeQuery{prec: prec, min: [3]uint64{x0, y0, z0}, max: [3]uint64{x1, y1, z1}, limit: maxRanges}
+	if !q.visit(0, 0) {
+		return nil, errTooManyCells
+	}
+	return q.res, nil
+}
+
+// spaceTimeQuery collects the ranges of hashes within a box of x, y and z
+// grid indices at a given precision.
+type spaceTimeQuery struct {
+	prec     uint8
+	min, max [3]uint64
+	limit    int
+	res      []SpaceTimeRange
+}
+
+// visit visits a cell of the given level, i.e. precision, starting at the
+// root, which contains everything. Cells within the box are emitted as a
+// whole, cells on its boundary are refined up to the query precision. It
+// returns false once the results exceed the limit.
+func (q *spaceTimeQuery) visit(base uint64, level uint8) bool {
+	shift := q.prec - level
+	x, y, z := deinterleave3(base)
+
+	inside := true
+	for i, n := range [3]uint64{x, y, z} {
+		lo, hi := n<<shift, (n+1)<<shift-1
+		if hi < q.min[i] || lo > q.max[i] {
+			return true
+		}
+		inside = inside && q.min[i] <= lo && hi <= q.max[i]
+	}
+
+	if !inside && level < q.prec {
+		for i := uint64(0); i < 8; i++ {
+			if !q.visit(base<<3|i, level+1) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// the max of the last cell overflows into the precision bits, add rather
+	// than OR to sort it after all hashes with maximum precision
+	span := 3 * (SpaceTimePrecisionMax - level)
+	r := SpaceTimeRange{
+		Min: newSpaceTimeHash(base<<span, SpaceTimePrecisionMax),
+		Max: newSpaceTimeHash(0, SpaceTimePrecisionMax) + SpaceTimeHash((base+1)<<span),
+	}
+	if n := len(q.res); n != 0 && q.res[n-1].Max == r.Min {
+		q.res[n-1].Max = r.Max
+		return true
+	}
+	q.res = append(q.res, r)
+	return len(q.res) <= q.limit
+}
+
+// bucket returns the time bucket at maximum precision.
+func (st SpaceTime) bucket(t time.Time) (uint64, error) {
+	if st.Resolution <= 0 || t.Before(st.Epoch) {
+		return 0, errInvalidTime
+	}
+
+	// time.Duration overflows after 292 years, divide the elapsed
+	// nanoseconds as a 128 bit number instead
+	secs := t.Unix() - st.Epoch.Unix()
+	nanos := int64(t.Nanosecond()) - int64(st.Epoch.Nanosecond())
+	if nanos < 0 {
+		secs, nanos = secs-1, nanos+int64(time.Second)
+	}
+
+	hi, lo := bits.Mul64(uint64(secs), uint64(time.Second))
+	lo, carry := bits.Add64(lo, uint64(nanos), 0)
+	hi += carry
+	if hi >= uint64(st.Resolution) {
+		return 0, errInvalidTime
+	}
+
+	n, _ := bits.Div64(hi, lo, uint64(st.Resolution))
+	if n >= 1<<SpaceTimePrecisionMax {
+		return 0, errInvalidTime
+	}
+	return n, nil
+}
+
+// bucketStart returns the start time of a bucket at maximum precision.
+func (st SpaceTime) bucketStart(n uint64) time.Time {
+	hi, lo := bits.Mul64(n, uint64(st.Resolution))
+	secs, nanos := bits.Div64(hi, lo, uint64(time.Second))
+	return time.Unix(st.Epoch.Unix()+int64(secs), int64(st.Epoch.Nanosecond())+int64(nanos)).In(st.Epoch.Location())
+}
+
+// clampedBucket is like bucket, but clamps times at the limits.
+func (st SpaceTime) clampedBucket(t time.Time) uint64 {
+	if t.Before(st.Epoch) {
+		return 0
+	}
+	if n, err := st.bucket(t); err == nil {
+		return n
+	}
+	return 1<<SpaceTimePrecisionMax - 1
+}
+
+// Precision returns the prec level
+func (h SpaceTimeHash) Precision() uint8 { return uint8(h >> precisionOffset3) }
+
+func (h SpaceTimeHash) base() uint64 { return uint64(h & baseMask3) }
+
+// Hash returns the spatial component of the hash, with the same precision.
+func (h SpaceTimeHash) Hash() Hash {
+	x, y, _ := deinterleave3(h.base())
+	return newHash(interleave64(x, y), h.Precision())
+}
+
+// Parent zooms out, returning the parent hash, lowering the precision. The
+// parent covers twice the duration. This function may return
+// SpaceTimeHash(0) if unable to zoom out further
+func (h SpaceTimeHash) Parent() SpaceTimeHash {
+	prec := h.Precision()
+	if prec <= PrecisionMin {
+		return 0
+	}
+	return newSpaceTimeHash(h.base()>>3, prec-1)
+}
+
+// Children zooms in, returning eight child hashes. The first four children
+// cover the first half of the time interval, in the same spatial order as
+// Hash.Children, the last four cover the second half. This function may
+// return nil if unable to zoom in further
+func (h SpaceTimeHash) Children() []SpaceTimeHash {
+	prec := h.Precision()
+	if prec >= SpaceTimePrecisionMax {
+		return nil
+	}
+
+	child := newSpaceTimeHash(h.base()<<3, prec+1)
+	res := make([]SpaceTimeHash, 8)
+	for i := range res {
+		res[i] = child | SpaceTimeHash(i)
+	}
+	return res
+}
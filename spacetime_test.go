@@ -0,0 +1,296 @@
+package geohashi
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SpaceTime", func() {
+	const lat, lon = 51.524632318, -0.0841140747
+
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	subject := SpaceTime{Epoch: epoch, Resolution: time.Minute}
+	at := epoch.Add(90*24*time.Hour + 90*time.Second)
+
+	It("should encode", func() {
+		h, err := subject.Encode(lat, lon, at)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.Precision()).To(Equal(uint8(SpaceTimePrecisionMax)))
+		Expect(h.Hash()).To(Equal(EncodeWithPrecision(lat, lon, SpaceTimePrecisionMax)))
+
+		h, err = subject.EncodeWithPrecision(lat, lon, at, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h).To(Equal(SpaceTimeHash(0x0200000000000001)))
+
+		_, err = subject.EncodeWithPrecision(lat, lon, at, 0)
+		Expect(err).To(MatchError(errInvalidPrecision))
+		_, err = subject.EncodeWithPrecision(lat, lon, at, 20)
+		Expect(err).To(MatchError(errInvalidPrecision))
+		_, err = subject.Encode(90, lon, at)
+		Expect(err).To(MatchError(errInvalidCoordinates))
+		_, err = subject.Encode(lat, lon, epoch.Add(-time.Second))
+		Expect(err).To(MatchError(errInvalidTime))
+		_, err = subject.Encode(lat, lon, epoch.Add(time.Minute<<SpaceTimePrecisionMax))
+		Expect(err).To(MatchError(errInvalidTime))
+		_, err = SpaceTime{Epoch: epoch}.Encode(lat, lon, at)
+		Expect(err).To(MatchError(errInvalidTime))
+	})
+
+	It("should decode", func() {
+		h, err := subject.Encode(lat, lon, at)
+		Expect(err).NotTo(HaveOccurred())
+
+		area, start, end := subject.Decode(h)
+		Expect(area).To(Equal(EncodeWithPrecision(lat, lon, SpaceTimePrecisionMax).Decode()))
+		Expect(start).To(Equal(epoch.Add(90*24*time.Hour + time.Minute)))
+		Expect(end).To(Equal(start.Add(time.Minute)))
+
+		rnd := rand.New(rand.NewSource(1))
+		for n := 0; n < 1000; n++ {
+			lat, lon := rnd.Float64()*170-85, rnd.Float64()*360-180
+			t := epoch.Add(time.Duration(rnd.Int63n(int64(time.Minute << SpaceTimePrecisionMax))))
+			prec := uint8(rnd.Intn(SpaceTimePrecisionMax) + 1)
+
+			h, err := subject.EncodeWithPrecision(lat, lon, t, prec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(h.Precision()).To(Equal(prec))
+
+			area, start, end := subject.Decode(h)
+			Expect(area.Contains(lat, lon)).To(BeTrue())
+			Expect(start).To(BeTemporally("<=", t))
+			Expect(end).To(BeTemporally(">", t))
+			Expect(end.Sub(start)).To(Equal(time.Minute << (SpaceTimePrecisionMax - prec)))
+		}
+	})
+
+	It("should support long resolutions", func() {
+		daily := SpaceTime{Epoch: epoch, Resolution: 24 * time.Hour}
+		t1 := time.Date(2400, 1, 1, 0, 0, 0, 0, time.UTC)
+		t2 := time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		h1, err := daily.Encode(lat, lon, t1)
+		Expect(err).NotTo(HaveOccurred())
+		h2, err := daily.Encode(lat, lon, t2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h1).NotTo(Equal(h2))
+
+		_, start, end := daily.Decode(h2)
+		Expect(start).To(Equal(t2))
+		Expect(end).To(Equal(t2.Add(24 * time.Hour)))
+
+		_, start, end = daily.Decode(h2.Parent())
+		Expect(start).To(BeTemporally("<=", t2))
+		Expect(end).To(BeTemporally(">", t2))
+		Expect(end.Sub(start)).To(Equal(48 * time.Hour))
+
+		last := epoch.AddDate(0, 0, 1<<SpaceTimePrecisionMax)
+		_, err = daily.Encode(lat, lon, last.Add(-time.Nanosecond))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = daily.Encode(lat, lon, last)
+		Expect(err).To(MatchError(errInvalidTime))
+		_, err = SpaceTime{Epoch: epoch, Resolution: time.Nanosecond}.Encode(lat, lon, t2)
+		Expect(err).To(MatchError(errInvalidTime))
+	})
+
+	It("should zoom out", func() {
+		for prec := uint8(SpaceTimePrecisionMax); prec > PrecisionMin; prec-- {
+			h, err := subject.EncodeWithPrecision(lat, lon, at, prec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subject.EncodeWithPrecision(lat, lon, at, prec-1)).To(Equal(h.Parent()))
+		}
+
+		h, err := subject.EncodeWithPrecision(lat, lon, at, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.Parent()).To(Equal(SpaceTimeHash(0)))
+	})
+
+	It("should zoom in", func() {
+		h, err := subject.EncodeWithPrecision(lat, lon, at, 10)
+		Expect(err).NotTo(HaveOccurred())
+		area, start, end := subject.Decode(h)
+		mid := start.Add(end.Sub(start) / 2)
+
+		children := h.Children()
+		Expect(children).To(HaveLen(8))
+		for i, child := range children {
+			Expect(child.Precision()).To(Equal(uint8(11)))
+			Expect(child.Parent()).To(Equal(h))
+			Expect(child.Hash().Parent()).To(Equal(h.Hash()))
+
+			a, s, e := subject.Decode(child)
+			clat, clon := a.Center()
+			Expect(area.Contains(clat, clon)).To(BeTrue())
+			if i < 4 {
+				Expect([]time.Time{s, e}).To(Equal([]time.Time{start, mid}))
+			} else {
+				Expect([]time.Time{s, e}).To(Equal([]time.Time{mid, end}))
+			}
+		}
+
+		h, err = subject.Encode(lat, lon, at)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.Children()).To(BeNil())
+	})
+
+	It("should calculate ranges", func() {
+		area := Area{MinLat: 51.50, MaxLat: 51.55, MinLon: -0.15, MaxLon: -0.05}
+		from, to := at, at.Add(6*time.Hour)
+
+		ranges := subject.Ranges(area, from, to, 12)
+		Expect(ranges).NotTo(BeEmpty())
+		for i := 1; i < len(ranges); i++ {
+			Expect(ranges[i-1].Max).To(BeNumerically("<", ranges[i].Min))
+		}
+
+		// every event within the area and window is within a range
+		rnd := rand.New(rand.NewSource(1))
+		for n := 0; n < 1000; n++ {
+			h, err := subject.Encode(
+				area.MinLat+rnd.Float64()*(area.MaxLat-area.MinLat),
+				area.MinLon+rnd.Float64()*(area.MaxLon-area.MinLon),
+				from.Add(time.Duration(rnd.Int63n(int64(to.Sub(from))))),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			found := false
+			for _, r := range ranges {
+				if r.Contains(h) {
+					found = true
+					break
+				}
+			}
+			Expect(found).To(BeTrue())
+		}
+
+		// events outside of the window are not
+		h, err := subject.Encode(51.52, -0.1, to.Add(24*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		for _, r := range ranges {
+			Expect(r.Contains(h)).To(BeFalse())
+		}
+	})
+
+	It("should clip ranges to the supported interval", func() {
+		area := Area{MinLat: 51.50, MaxLat: 51.55, MinLon: -0.15, MaxLon: -0.05}
+		end := epoch.Add(time.Minute << SpaceTimePrecisionMax)
+
+		Expect(subject.Ranges(area, epoch.Add(-time.Hour), epoch.Add(time.Hour), 12)).To(Equal(subject.Ranges(area, epoch, epoch.Add(time.Hour), 12)))
+		Expect(subject.Ranges(area, end.Add(-time.Hour), end.Add(time.Hour), 12)).To(Equal(subject.Ranges(area, end.Add(-time.Hour), end.Add(-time.Nanosecond), 12)))
+
+		Expect(subject.Ranges(area, epoch.Add(-2*time.Hour), epoch.Add(-time.Hour), 12)).To(BeNil())
+		Expect(subject.Ranges(area, end, end.Add(time.Hour), 12)).To(BeNil())
+		Expect(subject.Ranges(area, at, at.Add(-time.Hour), 12)).To(BeNil())
+		Expect(subject.Ranges(area, at, at, 0)).To(BeNil())
+		Expect(subject.Ranges(area, at, at, 20)).To(BeNil())
+		Expect(SpaceTime{Epoch: epoch}.Ranges(area, at, at, 12)).To(BeNil())
+	})
+
+	It("should limit ranges", func() {
+		area := Area{MinLat: 51.50, MaxLat: 51.55, MinLon: -0.15, MaxLon: -0.05}
+		ranges, err := subject.RangesLimit(area, at, at.Add(time.Hour), 12, MaxCoverCells)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ranges).NotTo(BeEmpty())
+		Expect(ranges).To(Equal(subject.Ranges(area, at, at.Add(time.Hour), 12)))
+
+		res, err := subject.RangesLimit(area, at, at.Add(time.Hour), 12, len(ranges))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(Equal(ranges))
+		_, err = subject.RangesLimit(area, at, at.Add(time.Hour), 12, len(ranges)-1)
+		Expect(err).To(Equal(errTooManyCells))
+
+		_, err = subject.RangesLimit(area, at, at, 20, 16)
+		Expect(err).To(Equal(errInvalidPrecision))
+		_, err = subject.RangesLimit(area, at, at.Add(-time.Hour), 12, 16)
+		Expect(err).To(Equal(errInvalidTime))
+		_, err = subject.RangesLimit(Area{MinLat: 1, MaxLat: 0}, at, at, 12, 16)
+		Expect(err).To(Equal(errInvalidArea))
+
+		// the boundary of large volumes requires too many ranges at high precisions
+		world := Area{MinLat: -60.1, MaxLat: 60.3, MinLon: -170.8, MaxLon: 170.1}
+		Expect(subject.Ranges(world, at, at.Add(24*time.Hour), SpaceTimePrecisionMax)).To(BeNil())
+	})
+
+	It("should calculate ranges at the upper limits", func() {
+		area := Area{MinLat: 85, MaxLat: LatMax, MinLon: 179.9, MaxLon: LonMax}
+		last := epoch.Add(time.Minute<<SpaceTimePrecisionMax - time.Nanosecond)
+
+		ranges := subject.Ranges(area, last.Add(-time.Hour), last, SpaceTimePrecisionMax)
+		Expect(ranges).NotTo(BeEmpty())
+		for _, r := range ranges {
+			Expect(r.Min).To(BeNumerically("<", r.Max))
+		}
+
+		h, err := subject.Encode(LatMax-1e-9, LonMax-1e-9, last)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ranges[len(ranges)-1].Contains(h)).To(BeTrue())
+	})
+
+	It("should calculate the same ranges as enumerating cells", func() {
+		rnd := rand.New(rand.NewSource(1))
+		for n := 0; n < 100; n++ {
+			lat, lon := rnd.Float64()*160-80, rnd.Float64()*340-170
+			area := Area{MinLat: lat, MaxLat: lat + rnd.Float64()*10, MinLon: lon, MaxLon: lon + rnd.Float64()*10}
+			from := epoch.Add(time.Duration(rnd.Int63n(int64(time.Minute << SpaceTimePrecisionMax))))
+			to := from.Add(time.Duration(rnd.Int63n(int64(60 * 24 * time.Hour))))
+			prec := uint8(rnd.Intn(6) + 1)
+
+			Expect(subject.Ranges(area, from, to, prec)).To(Equal(enumerateSpaceTimeRanges(subject, area, from, to, prec)))
+		}
+	})
+
+	It("should calculate ranges for long windows", func() {
+		area := Area{MinLat: 51.50, MaxLat: 51.55, MinLon: -0.15, MaxLon: -0.05}
+		to := epoch.Add(300 * 24 * time.Hour)
+		ranges := subject.Ranges(area, epoch, to, 14)
+		Expect(ranges).NotTo(BeEmpty())
+
+		// fewer ranges than cells
+		x0, x1, y0, y1 := area.gridBounds(14)
+		z1 := subject.clampedBucket(to) >> (SpaceTimePrecisionMax - 14)
+		Expect(uint64(len(ranges))).To(BeNumerically("<", (x1-x0+1)*(y1-y0+1)*(z1+1)))
+
+		h, err := subject.Encode(51.52, -0.1, to)
+		Expect(err).NotTo(HaveOccurred())
+		found := false
+		for _, r := range ranges {
+			found = found || r.Contains(h)
+		}
+		Expect(found).To(BeTrue())
+	})
+})
+
+// enumerateSpaceTimeRanges calculates ranges by enumerating all cells of the
+// query volume.
+func enumerateSpaceTimeRanges(st SpaceTime, a Area, from, to time.Time, prec uint8) []SpaceTimeRange {
+	shift := SpaceTimePrecisionMax - prec
+	x0, x1, y0, y1 := a.gridBounds(prec)
+	z0, z1 := st.clampedBucket(from)>>shift, st.clampedBucket(to)>>shift
+
+	var bases []uint64
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			for z := z0; z <= z1; z++ {
+				bases = append(bases, interleave3(x, y, z))
+			}
+		}
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+
+	var res []SpaceTimeRange
+	for _, base := range bases {
+		r := SpaceTimeRange{
+			Min: newSpaceTimeHash(base<<(3*shift), SpaceTimePrecisionMax),
+			Max: newSpaceTimeHash(0, SpaceTimePrecisionMax) + SpaceTimeHash((base+1)<<(3*shift)),
+		}
+		if n := len(res); n != 0 && res[n-1].Max == r.Min {
+			res[n-1].Max = r.Max
+			continue
+		}
+		res = append(res, r)
+	}
+	return res
+}
@@ -0,0 +1,122 @@
+package geohashi
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Scan implements sql.Scanner. Hashes are stored as (signed) BIGINT values,
+// but decimal strings are accepted too. NULL values scan as the zero Hash.
+func (h *Hash) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*h = 0
+		return nil
+	case int64:
+		if v < 0 {
+			return errInvalidHash
+		}
+		return h.set(Hash(v))
+	case []byte:
+		return h.UnmarshalText(v)
+	case string:
+		return h.UnmarshalText([]byte(v))
+	}
+	return fmt.Errorf("geohashi: cannot scan %T into Hash", src)
+}
+
+// Value implements driver.Valuer. Valid hashes never exceed 57 bits and
+// can safely be stored as signed integers. The zero Hash is stored as NULL.
+func (h Hash) Value() (driver.Value, error) {
+	if h == 0 {
+		return nil, nil
+	}
+	if err := h.validate(); err != nil {
+		return nil, err
+	}
+	return int64(h), nil
+}
+
+// --------------------------------------------------------------------
+
+// Scan implements sql.Scanner. It accepts PostgreSQL box notation, i.e.
+// "(maxLon,maxLat),(minLon,minLat)", WKT and EWKT geometries as well as raw
+// or hex-encoded (E)WKB geometries, as returned by PostGIS. Areas are set to
+// the bounding box of parsed geometries. NULL values scan as the zero Area.
+func (a *Area) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*a = Area{}
+		return nil
+	case []byte:
+		if len(v) != 0 && v[0] <= 1 {
+			return a.scanGeometry(ParseWKB(v))
+		}
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return fmt.Errorf("geohashi: cannot scan %T into Area", src)
+	}
+
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "(") {
+		return a.scanBox(s)
+	}
+	if data, err := hex.DecodeString(s); err == nil && len(data) != 0 {
+		return a.scanGeometry(ParseWKB(data))
+	}
+	return a.scanGeometry(ParseWKT(s))
+}
+
+// Value implements driver.Valuer. Areas are stored as WKT polygons.
+func (a Area) Value() (driver.Value, error) {
+	return a.WKT(), nil
+}
+
+func (a *Area) scanBox(s string) error {
+	s = strings.NewReplacer("(", "", ")", "").Replace(s)
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return errInvalidArea
+	}
+
+	var vv [4]float64
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return errInvalidArea
+		}
+		vv[i] = v
+	}
+
+	*a = Area{
+		MinLat: math.Min(vv[1], vv[3]),
+		MaxLat: math.Max(vv[1], vv[3]),
+		MinLon: math.Min(vv[0], vv[2]),
+		MaxLon: math.Max(vv[0], vv[2]),
+	}
+	return nil
+}
+
+func (a *Area) scanGeometry(points []Point, polygons []Polygon, err error) error {
+	if err != nil || (len(points) == 0 && len(polygons) == 0) {
+		return errInvalidArea
+	}
+
+	b := Area{MinLat: math.Inf(1), MaxLat: math.Inf(-1), MinLon: math.Inf(1), MaxLon: math.Inf(-1)}
+	for _, poly := range polygons {
+		points = append(points, poly...)
+	}
+	for _, pt := range points {
+		b.MinLat, b.MaxLat = math.Min(b.MinLat, pt.Lat), math.Max(b.MaxLat, pt.Lat)
+		b.MinLon, b.MaxLon = math.Min(b.MinLon, pt.Lon), math.Max(b.MaxLon, pt.Lon)
+	}
+	*a = b
+	return nil
+}
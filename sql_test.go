@@ -0,0 +1,124 @@
+package geohashi
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SQL", func() {
+	var db *sql.DB
+
+	BeforeEach(func() {
+		var err error
+		db, err = sql.Open("geohashi_echo", "")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).To(Succeed())
+	})
+
+	It("should store/load hashes", func() {
+		var h Hash
+		Expect(db.QueryRow("SELECT ?", Hash(90072520759854475)).Scan(&h)).To(Succeed())
+		Expect(h).To(Equal(Hash(90072520759854475)))
+
+		Expect(db.QueryRow("SELECT ?", int64(-1)).Scan(&h)).To(MatchError(ContainSubstring("invalid hash")))
+		Expect(db.QueryRow("SELECT ?", "4503599627370499").Scan(&h)).To(Succeed())
+		Expect(h).To(Equal(Hash(4503599627370499)))
+		Expect(db.QueryRow("SELECT ?", nil).Scan(&h)).To(Succeed())
+		Expect(h).To(Equal(Hash(0)))
+
+		_, err := db.Exec("INSERT ?", Hash(1))
+		Expect(err).To(MatchError(ContainSubstring("invalid precision")))
+		_, err = db.Exec("INSERT ?", Hash(1<<52|1<<40))
+		Expect(err).To(MatchError(ContainSubstring("invalid hash")))
+
+		// NULL values round-trip through the zero Hash
+		v, err := h.Value()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(BeNil())
+		Expect(db.QueryRow("SELECT ?", h).Scan(&h)).To(Succeed())
+		Expect(h).To(Equal(Hash(0)))
+	})
+
+	It("should store/load areas", func() {
+		var a Area
+		Expect(db.QueryRow("SELECT ?", Area{MinLat: -1.5, MaxLat: 2, MinLon: 3, MaxLon: 4.25}).Scan(&a)).To(Succeed())
+		Expect(a).To(Equal(Area{MinLat: -1.5, MaxLat: 2, MinLon: 3, MaxLon: 4.25}))
+
+		Expect(db.QueryRow("SELECT ?", "(4.25,2),(3,-1.5)").Scan(&a)).To(Succeed())
+		Expect(a).To(Equal(Area{MinLat: -1.5, MaxLat: 2, MinLon: 3, MaxLon: 4.25}))
+
+		Expect(db.QueryRow("SELECT ?", "POLYGON ((0 0, 5 -1, 2 8, 0 0), (1 1, 2 2, 1 2, 1 1))").Scan(&a)).To(Succeed())
+		Expect(a).To(Equal(Area{MinLat: -1, MaxLat: 8, MinLon: 0, MaxLon: 5}))
+
+		Expect(db.QueryRow("SELECT ?", "SRID=4326;MULTIPOINT (1 2, 3 -4)").Scan(&a)).To(Succeed())
+		Expect(a).To(Equal(Area{MinLat: -4, MaxLat: 2, MinLon: 1, MaxLon: 3}))
+
+		Expect(db.QueryRow("SELECT ?", "0101000020E6100000000000000000F03F0000000000000040").Scan(&a)).To(Succeed())
+		Expect(a).To(Equal(Area{MinLat: 2, MaxLat: 2, MinLon: 1, MaxLon: 1}))
+
+		Expect(a.Scan(Area{MinLat: 1, MaxLat: 2, MinLon: 3, MaxLon: 4}.WKB(binary.LittleEndian))).To(Succeed())
+		Expect(a).To(Equal(Area{MinLat: 1, MaxLat: 2, MinLon: 3, MaxLon: 4}))
+
+		Expect(db.QueryRow("SELECT ?", "(1,2)").Scan(&a)).To(MatchError(ContainSubstring("invalid area")))
+		Expect(db.QueryRow("SELECT ?", "POINT EMPTY").Scan(&a)).To(MatchError(ContainSubstring("invalid area")))
+		Expect(db.QueryRow("SELECT ?", int64(1)).Scan(&a)).To(MatchError(ContainSubstring("cannot scan int64")))
+
+		Expect(db.QueryRow("SELECT ?", nil).Scan(&a)).To(Succeed())
+		Expect(a).To(Equal(Area{}))
+	})
+
+	It("should encode areas as WKT", func() {
+		v, err := Area{MinLat: -1.5, MaxLat: 2, MinLon: 3, MaxLon: 4.25}.Value()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal("POLYGON((3 -1.5,4.25 -1.5,4.25 2,3 2,3 -1.5))"))
+	})
+
+})
+
+// --------------------------------------------------------------------
+
+func init() {
+	sql.Register("geohashi_echo", echoDriver{})
+}
+
+// echoDriver is a fake SQL driver which returns query arguments as a single row
+type echoDriver struct{}
+
+func (echoDriver) Open(_ string) (driver.Conn, error) { return echoConn{}, nil }
+
+type echoConn struct{}
+
+func (echoConn) Prepare(_ string) (driver.Stmt, error) { return echoStmt{}, nil }
+func (echoConn) Close() error                          { return nil }
+func (echoConn) Begin() (driver.Tx, error)             { return nil, driver.ErrSkip }
+
+type echoStmt struct{}
+
+func (echoStmt) Close() error                                   { return nil }
+func (echoStmt) NumInput() int                                  { return -1 }
+func (echoStmt) Exec(_ []driver.Value) (driver.Result, error)   { return driver.RowsAffected(1), nil }
+func (echoStmt) Query(args []driver.Value) (driver.Rows, error) { return &echoRows{row: args}, nil }
+
+type echoRows struct {
+	row  []driver.Value
+	done bool
+}
+
+func (r *echoRows) Columns() []string { return make([]string, len(r.row)) }
+func (r *echoRows) Close() error      { return nil }
+func (r *echoRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.row)
+	return nil
+}
@@ -0,0 +1,205 @@
+package geohashi
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+)
+
+var errInvalidQuadkey = errors.New("geohashi: invalid quadkey")
+
+// Tile is a Web Mercator (slippy map) XYZ tile. X increases eastwards, Y
+// increases southwards, Z is the zoom level.
+//
+// Longitudes of tiles and hashes align exactly, the X coordinate of a tile
+// equals the longitude grid index of a hash with the same precision. Hashes
+// use linear latitude scaling within the Mercator limits, latitudes therefore
+// only align at zoom level/precision 1, where both are split at the equator.
+type Tile struct {
+	X, Y uint32
+	Z    uint8
+}
+
+// TileAt returns the tile containing the coordinates at zoom level z.
+// Coordinates outside the Mercator limits are clamped. The zoom level is
+// capped at PrecisionMax.
+func TileAt(lat, lon float64, z uint8) Tile {
+	if z > PrecisionMax {
+		z = PrecisionMax
+	}
+	return Tile{
+		X: uint32(gridIndex((lon-LonMin)/lonScale, z)),
+		Y: uint32(gridIndex(mercatorY(lat), z)),
+		Z: z,
+	}
+}
+
+// ParseQuadkey parses a Bing Maps quadkey.
+func ParseQuadkey(s string) (Tile, error) {
+	if len(s) > PrecisionMax {
+		return Tile{}, errInvalidQuadkey
+	}
+
+	t := Tile{Z: uint8(len(s))}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '0' || c > '3' {
+			return Tile{}, errInvalidQuadkey
+		}
+		t.X = t.X<<1 | uint32(c-'0')&1
+		t.Y = t.Y<<1 | uint32(c-'0')>>1
+	}
+	return t, nil
+}
+
+// IsValid returns true if the tile coordinates are within the bounds of the
+// zoom level.
+func (t Tile) IsValid() bool {
+	n := uint64(1) << t.Z
+	return t.Z <= PrecisionMax && uint64(t.X) < n && uint64(t.Y) < n
+}
+
+// Quadkey returns the Bing Maps quadkey of the tile.
+func (t Tile) Quadkey() string {
+	var b strings.Builder
+	b.Grow(int(t.Z))
+	for i := int(t.Z) - 1; i >= 0; i-- {
+		b.WriteByte('0' + byte(t.X>>i&1) + byte(t.Y>>i&1)<<1)
+	}
+	return b.String()
+}
+
+// Bounds returns the area covered by the tile.
+func (t Tile) Bounds() Area {
+	n := float64(uint64(1) << t.Z)
+	a := Area{
+		MinLat: LatMin,
+		MaxLat: LatMax,
+		MinLon: LonMin + float64(t.X)/n*lonScale,
+		MaxLon: LonMin + float64(t.X+1)/n*lonScale,
+	}
+
+	// snap outer edges to the limits
+	if y := float64(t.Y + 1); y < n {
+		a.MinLat = mercatorLat(y / n)
+	}
+	if t.Y > 0 {
+		a.MaxLat = mercatorLat(float64(t.Y) / n)
+	}
+	return a
+}
+
+// Hash returns the hash which is identical to the tile. Only tiles at zoom
+// level 1 have an exact hash equivalent.
+func (t Tile) Hash() (Hash, bool) {
+	if t.Z != 1 || !t.IsValid() {
+		return 0, false
+	}
+	return newHash(interleave64(uint64(1-t.Y), uint64(t.X)), 1), true
+}
+
+// Cover returns all hashes of the given precision which intersect with the
+// tile, in ascending order. Unlike Area.Cover, hashes which only touch the
+// edges of the tile are not included. It returns nil if the tile requires
+// more than MaxCoverCells hashes.
+func (t Tile) Cover(prec uint8) []Hash {
+	if prec < PrecisionMin || prec > PrecisionMax || !t.IsValid() {
+		return nil
+	}
+
+	a := t.Bounds()
+	x0, x1 := gridSpan((a.MinLat-LatMin)/latScale, (a.MaxLat-LatMin)/latScale, prec)
+	y0, y1 := alignedSpan(uint64(t.X), t.Z, prec)
+	if (x1-x0+1)*(y1-y0+1) > MaxCoverCells {
+		return nil
+	}
+
+	res := make([]Hash, 0, (x1-x0+1)*(y1-y0+1))
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			res = append(res, newHash(interleave64(x, y), prec))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
+
+// --------------------------------------------------------------------
+
+// Tile returns the tile which is identical to the hash. Only hashes with
+// precision 1 have an exact tile equivalent.
+func (h Hash) Tile() (Tile, bool) {
+	if h.Precision() != 1 || h.validate() != nil {
+		return Tile{}, false
+	}
+
+	x, y := deinterleave64(h.base())
+	return Tile{X: uint32(y), Y: uint32(1 - x), Z: 1}, true
+}
+
+// Tiles returns all tiles at zoom level z which intersect with the hash, in
+// quadkey order. Tiles which only touch the edges of the hash are not
+// included. It returns nil if there are more than MaxCoverCells tiles.
+func (h Hash) Tiles(z uint8) []Tile {
+	if z > PrecisionMax || h.validate() != nil {
+		return nil
+	}
+
+	a := h.Decode()
+	_, lon := deinterleave64(h.base())
+	x0, x1 := alignedSpan(lon, h.Precision(), z)
+	y0, y1 := gridSpan(mercatorY(a.MaxLat), mercatorY(a.MinLat), z)
+	if (x1-x0+1)*(y1-y0+1) > MaxCoverCells {
+		return nil
+	}
+
+	res := make([]Tile, 0, (x1-x0+1)*(y1-y0+1))
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			res = append(res, Tile{X: uint32(x), Y: uint32(y), Z: z})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return interleave64(uint64(res[i].X), uint64(res[i].Y)) < interleave64(uint64(res[j].X), uint64(res[j].Y))
+	})
+	return res
+}
+
+// --------------------------------------------------------------------
+
+// mercatorY converts a latitude into a relative Web Mercator y offset, 0 at
+// the northern and 1 at the southern limit.
+func mercatorY(lat float64) float64 {
+	φ := deg2rad(lat)
+	return (1 - math.Log(math.Tan(φ)+1/math.Cos(φ))/math.Pi) / 2
+}
+
+// mercatorLat is the inverse of mercatorY.
+func mercatorLat(y float64) float64 {
+	return rad2deg(math.Atan(math.Sinh(math.Pi * (1 - 2*y))))
+}
+
+// gridSpan returns the grid index range of the half-open interval between
+// relative offsets f0 and f1 at the given precision.
+func gridSpan(f0, f1 float64, prec uint8) (i0, i1 uint64) {
+	n := float64(uint64(1) << prec)
+	i0 = gridIndex(f0, prec)
+	if c := math.Ceil(f1 * n); c > float64(i0+1) {
+		i1 = gridIndex((c-1)/n, prec)
+	} else {
+		i1 = i0
+	}
+	return
+}
+
+// alignedSpan converts a grid index from one precision to the range of
+// indices it covers at another.
+func alignedSpan(i uint64, from, to uint8) (i0, i1 uint64) {
+	if to >= from {
+		shift := to - from
+		return i << shift, (i+1)<<shift - 1
+	}
+	i >>= from - to
+	return i, i
+}
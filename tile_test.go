@@ -0,0 +1,120 @@
+package geohashi
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tile", func() {
+	london := Tile{X: 511, Y: 340, Z: 10}
+
+	It("should locate tiles", func() {
+		Expect(TileAt(51.5074, -0.1278, 10)).To(Equal(london))
+		Expect(TileAt(40.7128, -74.0060, 12)).To(Equal(Tile{X: 1205, Y: 1540, Z: 12}))
+		Expect(TileAt(0, 0, 0)).To(Equal(Tile{}))
+		Expect(TileAt(90, 180, 2)).To(Equal(Tile{X: 3, Y: 0, Z: 2}))
+		Expect(TileAt(-90, -180, 2)).To(Equal(Tile{X: 0, Y: 3, Z: 2}))
+		Expect(TileAt(0, 0, 40).Z).To(Equal(uint8(26)))
+	})
+
+	It("should validate", func() {
+		Expect(Tile{}.IsValid()).To(BeTrue())
+		Expect(london.IsValid()).To(BeTrue())
+		Expect(Tile{X: 4, Y: 0, Z: 2}.IsValid()).To(BeFalse())
+		Expect(Tile{X: 0, Y: 4, Z: 2}.IsValid()).To(BeFalse())
+		Expect(Tile{Z: 27}.IsValid()).To(BeFalse())
+	})
+
+	It("should convert to/from quadkeys", func() {
+		Expect(Tile{X: 3, Y: 5, Z: 3}.Quadkey()).To(Equal("213"))
+		Expect(Tile{}.Quadkey()).To(Equal(""))
+		Expect(london.Quadkey()).To(Equal("0313131311"))
+
+		Expect(ParseQuadkey("213")).To(Equal(Tile{X: 3, Y: 5, Z: 3}))
+		Expect(ParseQuadkey("0313131311")).To(Equal(london))
+		Expect(ParseQuadkey("")).To(Equal(Tile{}))
+
+		_, err := ParseQuadkey("214")
+		Expect(err).To(MatchError(errInvalidQuadkey))
+		_, err = ParseQuadkey("000000000000000000000000000")
+		Expect(err).To(MatchError(errInvalidQuadkey))
+	})
+
+	It("should calculate bounds", func() {
+		Expect(Tile{}.Bounds()).To(Equal(Area{MinLat: LatMin, MaxLat: LatMax, MinLon: -180, MaxLon: 180}))
+
+		a := london.Bounds()
+		Expect(a.MinLat).To(BeNumerically("~", 51.3992, 1e-4))
+		Expect(a.MaxLat).To(BeNumerically("~", 51.6180, 1e-4))
+		Expect(a.MinLon).To(BeNumerically("~", -0.3516, 1e-4))
+		Expect(a.MaxLon).To(Equal(0.0))
+	})
+
+	It("should convert to/from hashes where aligned", func() {
+		for _, t := range []Tile{{0, 0, 1}, {1, 0, 1}, {0, 1, 1}, {1, 1, 1}} {
+			h, ok := t.Hash()
+			Expect(ok).To(BeTrue())
+			Expect(h.Precision()).To(Equal(uint8(1)))
+			Expect(h.Decode()).To(Equal(t.Bounds()))
+
+			r, ok := h.Tile()
+			Expect(ok).To(BeTrue())
+			Expect(r).To(Equal(t))
+		}
+
+		_, ok := london.Hash()
+		Expect(ok).To(BeFalse())
+		_, ok = EncodeWithPrecision(51.5074, -0.1278, 10).Tile()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should cover tiles with hashes", func() {
+		hashes := london.Cover(10)
+		Expect(hashes).To(HaveLen(2))
+		for _, h := range hashes {
+			_, lon := deinterleave64(h.base())
+			Expect(lon).To(Equal(uint64(511)))
+		}
+		Expect(hashes).To(ContainElement(EncodeWithPrecision(51.5074, -0.1278, 10)))
+
+		Expect(london.Cover(8)).To(Equal([]Hash{EncodeWithPrecision(51.5074, -0.1278, 8)}))
+		Expect(Tile{}.Cover(1)).To(HaveLen(4))
+		Expect(Tile{X: 0, Y: 0, Z: 1}.Cover(1)).To(HaveLen(1))
+		Expect(Tile{X: 4, Z: 1}.Cover(1)).To(BeNil())
+		Expect(london.Cover(0)).To(BeNil())
+		Expect(Tile{}.Cover(PrecisionMax)).To(BeNil())
+		Expect(Tile{}.Cover(10)).To(HaveLen(MaxCoverCells))
+	})
+
+	It("should cover hashes with tiles", func() {
+		h := EncodeWithPrecision(51.5074, -0.1278, 10)
+		tiles := h.Tiles(10)
+		Expect(tiles).To(ContainElement(london))
+		for _, t := range tiles {
+			Expect(t.X).To(Equal(uint32(511)))
+		}
+
+		Expect(h.Tiles(0)).To(Equal([]Tile{{}}))
+		Expect(EncodeWithPrecision(1, 1, 1).Tiles(2)).To(Equal([]Tile{
+			{X: 2, Y: 0, Z: 2}, {X: 3, Y: 0, Z: 2}, {X: 2, Y: 1, Z: 2}, {X: 3, Y: 1, Z: 2},
+		}))
+		Expect(Hash(0).Tiles(2)).To(BeNil())
+		Expect(EncodeWithPrecision(0, 0, 1).Tiles(PrecisionMax)).To(BeNil())
+		Expect(EncodeWithPrecision(0, 0, 1).Tiles(10)).To(HaveLen(1 << 18))
+	})
+
+	It("should be consistent", func() {
+		for _, z := range []uint8{3, 7} {
+			for _, prec := range []uint8{2, 5, 9} {
+				for lat := -80.0; lat <= 80; lat += 10 {
+					for lon := -175.0; lon < 180; lon += 35 {
+						tile := TileAt(lat, lon, z)
+						for _, h := range tile.Cover(prec) {
+							Expect(h.Tiles(z)).To(ContainElement(tile), "tile %v, hash %d", tile, h)
+						}
+					}
+				}
+			}
+		}
+	})
+})
@@ -0,0 +1,262 @@
+package geohashi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"time"
+)
+
+// DefaultTrajectoryResolution is the default resolution of timestamps in
+// encoded trajectories.
+const DefaultTrajectoryResolution = time.Second
+
+var errInvalidTrajectory = errors.New("geohashi: invalid trajectory encoding")
+
+// Timestamps must be representable in nanoseconds since the Unix epoch,
+// i.e. between the years 1678 and 2262.
+var (
+	minTrajectoryTime = time.Unix(0, math.MinInt64)
+	maxTrajectoryTime = time.Unix(0, math.MaxInt64)
+)
+
+// TrackPoint is a timestamped location of a trajectory.
+type TrackPoint struct {
+	Lat, Lon float64
+	Time     time.Time
+}
+
+// Encoded trajectories start with the uvarint precision and the uvarint
+// timestamp resolution in nanoseconds. Each point is then stored as moves
+// along the latitude (x) and longitude (y) grid of its hash, relative to the
+// previous point. Longitude moves wrap around the antimeridian. The bits of
+// the zigzag encoded x and y moves are interleaved into a single uvarint,
+// like the grid coordinates of hashes, and shifted left by one bit. The
+// lowest bit signals that the time elapsed since the previous point has
+// changed, in which case the new interval follows as a varint. Timestamps of
+// regularly sampled tracks therefore take up a single bit.
+type trajectoryEncoder struct {
+	prec       uint8
+	resolution time.Duration
+	x, y       uint64
+	t, dt      int64
+}
+
+func newTrajectoryEncoder(prec uint8, resolution time.Duration) trajectoryEncoder {
+	if resolution < 1 {
+		resolution = DefaultTrajectoryResolution
+	}
+	return trajectoryEncoder{prec: prec, resolution: resolution}
+}
+
+func (e *trajectoryEncoder) header(dst []byte) []byte {
+	dst = binary.AppendUvarint(dst, uint64(e.prec))
+	return binary.AppendUvarint(dst, uint64(e.resolution))
+}
+
+func (e *trajectoryEncoder) append(dst []byte, p TrackPoint) ([]byte, error) {
+	if e.prec < PrecisionMin || e.prec > PrecisionMax {
+		return dst, errInvalidPrecision
+	}
+	if !ValidCoordinates(p.Lat, p.Lon) {
+		return dst, errInvalidCoordinates
+	}
+	if p.Time.Before(minTrajectoryTime) || p.Time.After(maxTrajectoryTime) {
+		return dst, errInvalidTime
+	}
+
+	x, y := deinterleave64(encode(p.Lat, p.Lon, e.prec).base())
+	ns, res := p.Time.UnixNano(), int64(e.resolution)
+	t := ns / res
+	if ns%res < 0 {
+		t-- // floor times before 1970
+	}
+	if min, _ := trajectoryTimeBounds(res); t < min {
+		return dst, errInvalidTime
+	}
+
+	dx := int64(x - e.x)
+	dy := int64(y-e.y) << (64 - e.prec) >> (64 - e.prec) // shortest way around
+	dt := t - e.t
+	if (dt < 0) != (t < e.t) {
+		return dst, errInvalidTime // more than 292 years apart at ns resolution
+	}
+
+	flag := uint64(0)
+	if dt != e.dt {
+		flag = 1
+	}
+	dst = binary.AppendUvarint(dst, interleave64(zigzag(dx), zigzag(dy))<<1|flag)
+	if flag != 0 {
+		dst = binary.AppendVarint(dst, dt)
+	}
+
+	e.x, e.y, e.t, e.dt = x, y, t, dt
+	return dst, nil
+}
+
+// AppendTrajectory appends points to dst, encoded as hashes with the given
+// precision and timestamps with the given resolution. Pass 0 as resolution
+// to use the DefaultTrajectoryResolution. Decoded locations are the centers
+// of the hashes.
+func AppendTrajectory(dst []byte, points []TrackPoint, prec uint8, resolution time.Duration) ([]byte, error) {
+	enc := newTrajectoryEncoder(prec, resolution)
+	dst = enc.header(dst)
+	for _, p := range points {
+		var err error
+		if dst, err = enc.append(dst, p); err != nil {
+			return dst, err
+		}
+	}
+	return dst, nil
+}
+
+// DecodeTrajectory decodes all points of an encoded trajectory.
+func DecodeTrajectory(data []byte) ([]TrackPoint, error) {
+	var res []TrackPoint
+	r := NewTrajectoryReader(bytes.NewReader(data))
+	for {
+		p, err := r.Read()
+		if err == io.EOF {
+			return res, nil
+		} else if err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+}
+
+// trajectoryTimeBounds returns the range of timestamps, in units of the
+// resolution, which are within minTrajectoryTime and maxTrajectoryTime.
+func trajectoryTimeBounds(resolution int64) (min, max int64) {
+	return minTrajectoryTime.UnixNano() / resolution, maxTrajectoryTime.UnixNano() / resolution
+}
+
+func zigzag(v int64) uint64 { return uint64(v<<1) ^ uint64(v>>63) }
+
+func unzigzag(u uint64) int64 { return int64(u>>1) ^ -int64(u&1) }
+
+// --------------------------------------------------------------------
+
+// TrajectoryWriter writes a trajectory to a stream, point by point.
+type TrajectoryWriter struct {
+	w   *bufio.Writer
+	enc trajectoryEncoder
+	buf []byte
+}
+
+// NewTrajectoryWriter inits a new writer. Points are encoded as hashes with
+// the given precision and timestamps with the given resolution, pass 0 to
+// use the DefaultTrajectoryResolution.
+func NewTrajectoryWriter(w io.Writer, prec uint8, resolution time.Duration) *TrajectoryWriter {
+	enc := newTrajectoryEncoder(prec, resolution)
+	return &TrajectoryWriter{
+		w:   bufio.NewWriter(w),
+		enc: enc,
+		buf: enc.header(make([]byte, 0, 3*binary.MaxVarintLen64)),
+	}
+}
+
+// Write appends a point to the stream.
+func (w *TrajectoryWriter) Write(p TrackPoint) error {
+	var err error
+	if w.buf, err = w.enc.append(w.buf, p); err != nil {
+		return err
+	}
+
+	_, err = w.w.Write(w.buf)
+	w.buf = w.buf[:0]
+	return err
+}
+
+// Flush flushes buffered data to the underlying writer.
+func (w *TrajectoryWriter) Flush() error {
+	if len(w.buf) != 0 {
+		if _, err := w.w.Write(w.buf); err != nil {
+			return err
+		}
+		w.buf = w.buf[:0]
+	}
+	return w.w.Flush()
+}
+
+// --------------------------------------------------------------------
+
+// TrajectoryReader reads a trajectory written by TrajectoryWriter or
+// AppendTrajectory.
+type TrajectoryReader struct {
+	r          io.ByteReader
+	prec       uint8
+	resolution time.Duration
+	x, y       uint64
+	t, dt      int64
+}
+
+// NewTrajectoryReader inits a new reader.
+func NewTrajectoryReader(r io.Reader) *TrajectoryReader {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &TrajectoryReader{r: br}
+}
+
+// Read returns the next point of the trajectory. It returns io.EOF at the
+// end of the stream.
+func (r *TrajectoryReader) Read() (TrackPoint, error) {
+	if r.prec == 0 {
+		if err := r.readHeader(); err != nil {
+			return TrackPoint{}, err
+		}
+	}
+
+	m, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = errInvalidTrajectory
+		}
+		return TrackPoint{}, err
+	}
+	if m&1 != 0 {
+		if r.dt, err = binary.ReadVarint(r.r); err != nil {
+			return TrackPoint{}, errInvalidTrajectory
+		}
+	}
+
+	mx, my := deinterleave64(m >> 1)
+	mask := uint64(1)<<r.prec - 1
+	x := r.x + uint64(unzigzag(mx))
+	if x > mask {
+		return TrackPoint{}, errInvalidTrajectory
+	}
+	r.x = x
+	r.y = (r.y + uint64(unzigzag(my))) & mask
+
+	t := r.t + r.dt
+	if min, max := trajectoryTimeBounds(int64(r.resolution)); (t < r.t) != (r.dt < 0) || t < min || t > max {
+		return TrackPoint{}, errInvalidTrajectory
+	}
+	r.t = t
+
+	lat, lon := newHash(interleave64(r.x, r.y), r.prec).Decode().Center()
+	return TrackPoint{Lat: lat, Lon: lon, Time: time.Unix(0, r.t*int64(r.resolution)).UTC()}, nil
+}
+
+func (r *TrajectoryReader) readHeader() error {
+	prec, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return errInvalidTrajectory
+	} else if err != nil {
+		return err
+	}
+	resolution, err := binary.ReadUvarint(r.r)
+	if err != nil || prec < PrecisionMin || prec > PrecisionMax || resolution == 0 || resolution > math.MaxInt64 {
+		return errInvalidTrajectory
+	}
+
+	r.prec, r.resolution = uint8(prec), time.Duration(resolution)
+	return nil
+}
@@ -0,0 +1,227 @@
+package geohashi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Trajectory encoding", func() {
+	var track []TrackPoint
+
+	BeforeEach(func() {
+		track = testTrack(3600)
+	})
+
+	It("should encode", func() {
+		data, err := AppendTrajectory(nil, []TrackPoint{
+			{Lat: 10, Lon: 10, Time: time.Unix(100, 0)},
+			{Lat: 10, Lon: -170, Time: time.Unix(101, 0)},
+			{Lat: 10, Lon: 170, Time: time.Unix(102, 0)},
+		}, 2, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal([]byte{
+			0x02,                         // precision
+			0x80, 0x94, 0xeb, 0xdc, 0x03, // resolution
+			0x35, 0xc8, 0x01, // +2, -2 (wrapped), 100s
+			0x15, 0x02, // 0, -2, 1s
+			0x04, // 0, -1 (across the antimeridian)
+		}))
+
+		_, err = AppendTrajectory(nil, track, 0, 0)
+		Expect(err).To(MatchError(errInvalidPrecision))
+		_, err = AppendTrajectory(nil, []TrackPoint{{Lat: 89, Lon: 0}}, 20, 0)
+		Expect(err).To(MatchError(errInvalidCoordinates))
+		_, err = AppendTrajectory(nil, []TrackPoint{{Time: time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC)}}, 20, 0)
+		Expect(err).To(MatchError(errInvalidTime))
+		_, err = AppendTrajectory(nil, []TrackPoint{{Time: time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC)}}, 20, 0)
+		Expect(err).To(MatchError(errInvalidTime))
+		_, err = AppendTrajectory(nil, []TrackPoint{{Time: minTrajectoryTime}, {Time: maxTrajectoryTime}}, 20, time.Nanosecond)
+		Expect(err).To(MatchError(errInvalidTime))
+	})
+
+	It("should round timestamps down", func() {
+		data, err := AppendTrajectory(nil, []TrackPoint{
+			{Time: time.Unix(-1, 500)},
+			{Time: time.Unix(1, 500)},
+		}, 20, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		points, err := DecodeTrajectory(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(points[0].Time).To(BeTemporally("==", time.Unix(-1, 0)))
+		Expect(points[1].Time).To(BeTemporally("==", time.Unix(1, 0)))
+	})
+
+	It("should decode within the cell bounds", func() {
+		const prec = 25
+		data, err := AppendTrajectory(nil, track, prec, time.Millisecond)
+		Expect(err).NotTo(HaveOccurred())
+
+		points, err := DecodeTrajectory(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(points).To(HaveLen(len(track)))
+
+		gn := float64(uint64(1) << prec)
+		for i, p := range points {
+			Expect(p.Lat).To(BeNumerically("~", track[i].Lat, latScale/gn/2))
+			Expect(p.Lon).To(BeNumerically("~", track[i].Lon, lonScale/gn/2))
+			Expect(p.Time).To(BeTemporally("==", track[i].Time.Truncate(time.Millisecond)))
+		}
+	})
+
+	It("should encode locations at the limits", func() {
+		limits := []TrackPoint{
+			{Lat: LatMax, Lon: 10, Time: time.Unix(100, 0)},
+			{Lat: 10, Lon: LonMax, Time: time.Unix(101, 0)},
+			{Lat: LatMax, Lon: LonMax, Time: time.Unix(102, 0)},
+			{Lat: LatMin, Lon: LonMin, Time: time.Unix(103, 0)},
+		}
+		data, err := AppendTrajectory(nil, limits, 20, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		points, err := DecodeTrajectory(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(points).To(HaveLen(len(limits)))
+
+		for i, p := range points {
+			Expect(p.Lat).To(BeNumerically("~", limits[i].Lat, latScale/(1<<20)))
+			Expect(p.Lon).To(BeNumerically("~", limits[i].Lon, lonScale/(1<<20)))
+		}
+	})
+
+	It("should be smaller than encoded polylines", func() {
+		// at precision 26, hashes are decoded with a maximum error of 1.3e-6
+		// degrees latitude and 2.7e-6 degrees longitude, polylines have a
+		// maximum error of 5e-6 degrees
+		data, err := AppendTrajectory(nil, track, 26, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		polyline := appendPolyline(nil, track)
+		Expect(len(data)).To(BeNumerically("<", len(polyline)))
+	})
+
+	It("should stream", func() {
+		buf := new(bytes.Buffer)
+		w := NewTrajectoryWriter(buf, 24, time.Second)
+		for _, p := range track {
+			Expect(w.Write(p)).To(Succeed())
+		}
+		Expect(w.Write(TrackPoint{Lat: 89})).To(MatchError(errInvalidCoordinates))
+		Expect(w.Flush()).To(Succeed())
+
+		data, err := AppendTrajectory(nil, track, 24, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buf.Bytes()).To(Equal(data))
+
+		exp, err := DecodeTrajectory(data)
+		Expect(err).NotTo(HaveOccurred())
+
+		r := NewTrajectoryReader(buf)
+		for _, p := range exp {
+			Expect(r.Read()).To(Equal(p))
+		}
+		_, err = r.Read()
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("should stream empty trajectories", func() {
+		buf := new(bytes.Buffer)
+		Expect(NewTrajectoryWriter(buf, 20, 0).Flush()).To(Succeed())
+		Expect(buf.Len()).To(Equal(6))
+
+		_, err := NewTrajectoryReader(buf).Read()
+		Expect(err).To(Equal(io.EOF))
+
+		_, err = NewTrajectoryReader(buf).Read()
+		Expect(err).To(MatchError(errInvalidTrajectory))
+	})
+
+	It("should reject invalid data", func() {
+		data, err := AppendTrajectory(nil, track[:10], 20, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = DecodeTrajectory(data[:7]) // within the first point
+		Expect(err).To(MatchError(errInvalidTrajectory))
+		_, err = DecodeTrajectory([]byte{27, 1})
+		Expect(err).To(MatchError(errInvalidTrajectory))
+		_, err = DecodeTrajectory([]byte{2, 1, 0x80, 0x01}) // +4
+		Expect(err).To(MatchError(errInvalidTrajectory))
+
+		// timestamps which overflow
+		_, err = DecodeTrajectory(binary.AppendVarint([]byte{2, 1, 0x01}, math.MaxInt64))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = DecodeTrajectory(append(binary.AppendVarint([]byte{2, 1, 0x01}, math.MaxInt64), 0x00))
+		Expect(err).To(MatchError(errInvalidTrajectory))
+		_, err = DecodeTrajectory(binary.AppendVarint([]byte{2, 2, 0x01}, math.MaxInt64))
+		Expect(err).To(MatchError(errInvalidTrajectory))
+		_, err = DecodeTrajectory([]byte{2, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0x00})
+		Expect(err).To(MatchError(errInvalidTrajectory))
+	})
+
+	It("should encode polylines", func() {
+		Expect(string(appendPolyline(nil, []TrackPoint{
+			{Lat: 38.5, Lon: -120.2},
+			{Lat: 40.7, Lon: -120.95},
+			{Lat: 43.252, Lon: -126.453},
+		}))).To(Equal("_p~iF~ps|U_ulLnnqC_mqNvxq`@"))
+	})
+})
+
+// testTrack simulates a vehicle, sampled every second, which alternates
+// between stops, city and highway driving.
+func testTrack(n int) []TrackPoint {
+	rnd := rand.New(rand.NewSource(1))
+	start := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	lat, lon, heading := 51.5, -0.1, 0.0
+
+	track := make([]TrackPoint, n)
+	for i := range track {
+		var speed float64 // m/s
+		switch phase := i / 300 % 4; phase {
+		case 0:
+			speed = 0
+		case 1, 3:
+			speed = 5 + rnd.Float64()*10
+		case 2:
+			speed = 25 + rnd.Float64()*10
+		}
+
+		heading += rnd.NormFloat64() * 0.1
+		lat += speed * math.Cos(heading) / 111320
+		lon += speed * math.Sin(heading) / (111320 * math.Cos(deg2rad(lat)))
+		track[i] = TrackPoint{Lat: lat, Lon: lon, Time: start.Add(time.Duration(i) * time.Second)}
+	}
+	return track
+}
+
+// appendPolyline encodes points using Google's encoded polyline algorithm
+// format with 5 decimal digits.
+func appendPolyline(dst []byte, points []TrackPoint) []byte {
+	var lat, lon int64
+	for _, p := range points {
+		plat, plon := int64(math.Round(p.Lat*1e5)), int64(math.Round(p.Lon*1e5))
+		dst = appendPolylineValue(dst, plat-lat)
+		dst = appendPolylineValue(dst, plon-lon)
+		lat, lon = plat, plon
+	}
+	return dst
+}
+
+func appendPolylineValue(dst []byte, v int64) []byte {
+	u := uint64(v) << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		dst = append(dst, byte(0x20|u&0x1f)+63)
+		u >>= 5
+	}
+	return append(dst, byte(u)+63)
+}
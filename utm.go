@@ -0,0 +1,173 @@
+package geohashi
+
+import (
+	"fmt"
+	"math"
+)
+
+// WGS84 ellipsoid and UTM projection parameters.
+const (
+	wgs84A = 6378137.0
+	wgs84F = 1 / 298.257223563
+
+	utmK0           = 0.9996
+	utmFalseEasting = 500000.0
+	utmFalseNorth   = 10000000.0 // false northing in the southern hemisphere
+	utmLatMin       = -80.0
+	utmLatMax       = 84.0
+)
+
+// UTM is a position in the Universal Transverse Mercator coordinate system.
+type UTM struct {
+	Zone     int  // 1..60
+	North    bool // true for the northern hemisphere
+	Easting  float64
+	Northing float64
+}
+
+// ToUTM converts a location to UTM coordinates. The zone is chosen according
+// to the standard, including the exceptions for Norway and Svalbard. Only
+// latitudes between -80 and 84 degrees are supported.
+func ToUTM(lat, lon float64) (UTM, error) {
+	if !(utmLatMin <= lat && lat <= utmLatMax && LonMin <= lon && lon <= LonMax) {
+		return UTM{}, errInvalidCoordinates
+	}
+	return toUTMZone(lat, lon, utmZone(lat, lon)), nil
+}
+
+// LatLon converts UTM coordinates to a location.
+func (u UTM) LatLon() (lat, lon float64, err error) {
+	if u.Zone < 1 || u.Zone > 60 {
+		return 0, 0, errInvalidCoordinates
+	}
+	if math.IsNaN(u.Easting) || math.IsNaN(u.Northing) || math.IsInf(u.Easting, 0) || math.IsInf(u.Northing, 0) {
+		return 0, 0, errInvalidCoordinates
+	}
+
+	northing := u.Northing
+	if !u.North {
+		northing -= utmFalseNorth
+	}
+
+	t := wgs84TM
+	ξ := northing / (utmK0 * t.A)
+	η := (u.Easting - utmFalseEasting) / (utmK0 * t.A)
+
+	ξ1, η1 := ξ, η
+	for j := 1; j <= 3; j++ {
+		jj := float64(2 * j)
+		ξ1 -= t.β[j-1] * math.Sin(jj*ξ) * math.Cosh(jj*η)
+		η1 -= t.β[j-1] * math.Cos(jj*ξ) * math.Sinh(jj*η)
+	}
+
+	χ := math.Asin(math.Sin(ξ1) / math.Cosh(η1))
+	φ := χ
+	for j := 1; j <= 3; j++ {
+		φ += t.δ[j-1] * math.Sin(float64(2*j)*χ)
+	}
+	λ := math.Atan2(math.Sinh(η1), math.Cos(ξ1))
+
+	lon = utmCentralMeridian(u.Zone) + rad2deg(λ)
+	if lon < LonMin {
+		lon += 360
+	} else if lon > LonMax {
+		lon -= 360
+	}
+	return rad2deg(φ), lon, nil
+}
+
+// String returns the UTM coordinates as text, e.g. "32N 691607 5334760".
+func (u UTM) String() string {
+	hemi := 'S'
+	if u.North {
+		hemi = 'N'
+	}
+	return fmt.Sprintf("%d%c %.0f %.0f", u.Zone, hemi, math.Floor(u.Easting), math.Floor(u.Northing))
+}
+
+// utmZone returns the UTM zone of a location.
+func utmZone(lat, lon float64) int {
+	if lon >= LonMax {
+		lon -= 360
+	}
+	zone := int(math.Floor((lon+180)/6)) + 1
+
+	switch {
+	case lat >= 56 && lat < 64 && lon >= 3 && lon < 12:
+		zone = 32 // Norway
+	case lat >= 72 && lon >= 0 && lon < 42:
+		// Svalbard, zones 32, 34 and 36 are not used
+		switch {
+		case lon < 9:
+			zone = 31
+		case lon < 21:
+			zone = 33
+		case lon < 33:
+			zone = 35
+		default:
+			zone = 37
+		}
+	}
+	return zone
+}
+
+// utmCentralMeridian returns the central meridian of a zone, in degrees.
+func utmCentralMeridian(zone int) float64 {
+	return float64(zone)*6 - 183
+}
+
+// toUTMZone projects a location into a specific zone.
+func toUTMZone(lat, lon float64, zone int) UTM {
+	λ := deg2rad(lon - utmCentralMeridian(zone))
+	if λ > math.Pi {
+		λ -= 2 * math.Pi
+	} else if λ < -math.Pi {
+		λ += 2 * math.Pi
+	}
+	φ := deg2rad(lat)
+
+	t := wgs84TM
+	c := 2 * math.Sqrt(t.n) / (1 + t.n)
+	τ := math.Sinh(math.Atanh(math.Sin(φ)) - c*math.Atanh(c*math.Sin(φ)))
+	ξ1 := math.Atan2(τ, math.Cos(λ))
+	η1 := math.Atanh(math.Sin(λ) / math.Sqrt(1+τ*τ))
+
+	ξ, η := ξ1, η1
+	for j := 1; j <= 3; j++ {
+		jj := float64(2 * j)
+		ξ += t.α[j-1] * math.Sin(jj*ξ1) * math.Cosh(jj*η1)
+		η += t.α[j-1] * math.Cos(jj*ξ1) * math.Sinh(jj*η1)
+	}
+
+	u := UTM{
+		Zone:     zone,
+		North:    lat >= 0,
+		Easting:  utmFalseEasting + utmK0*t.A*η,
+		Northing: utmK0 * t.A * ξ,
+	}
+	if !u.North {
+		u.Northing += utmFalseNorth
+	}
+	return u
+}
+
+// transverseMercator holds the coefficients of the Krüger series for the
+// WGS84 ellipsoid.
+type transverseMercator struct {
+	n, A    float64
+	α, β, δ [3]float64
+}
+
+var wgs84TM = newTransverseMercator()
+
+func newTransverseMercator() *transverseMercator {
+	n := wgs84F / (2 - wgs84F)
+	n2, n3 := n*n, n*n*n
+	return &transverseMercator{
+		n: n,
+		A: wgs84A / (1 + n) * (1 + n2/4 + n2*n2/64),
+		α: [3]float64{n/2 - 2*n2/3 + 5*n3/16, 13*n2/48 - 3*n3/5, 61 * n3 / 240},
+		β: [3]float64{n/2 - 2*n2/3 + 37*n3/96, n2/48 + n3/15, 17 * n3 / 480},
+		δ: [3]float64{2*n - 2*n2/3 - 2*n3, 7*n2/3 - 8*n3/5, 56 * n3 / 15},
+	}
+}
@@ -0,0 +1,93 @@
+package geohashi
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UTM", func() {
+	It("should convert to UTM", func() {
+		// GeographicLib: echo 33.3 44.4 | GeoConvert -u
+		u, err := ToUTM(33.3, 44.4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u.Zone).To(Equal(38))
+		Expect(u.North).To(BeTrue())
+		Expect(u.Easting).To(BeNumerically("~", 444140.54, 0.01))
+		Expect(u.Northing).To(BeNumerically("~", 3684706.36, 0.01))
+		Expect(u.String()).To(Equal("38N 444140 3684706"))
+
+		// CN Tower, Toronto
+		u, err = ToUTM(43.642567, -79.387139)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u.String()).To(Equal("17N 630084 4833438"))
+
+		u, err = ToUTM(-33.8568, 151.2153)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u.Zone).To(Equal(56))
+		Expect(u.North).To(BeFalse())
+		Expect(u.Northing).To(BeNumerically(">", 6000000))
+	})
+
+	It("should apply zone exceptions", func() {
+		for _, tc := range []struct {
+			lat, lon float64
+			zone     int
+		}{
+			{60, 2, 31},
+			{60, 5, 32},  // Norway
+			{50, 5, 31},  // south of Norway
+			{78, 8, 31},  // Svalbard
+			{78, 15, 33}, // Svalbard
+			{78, 30, 35}, // Svalbard
+			{78, 40, 37}, // Svalbard
+			{0, 180, 1},
+			{0, -180, 1},
+			{0, 179.9, 60},
+		} {
+			u, err := ToUTM(tc.lat, tc.lon)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u.Zone).To(Equal(tc.zone), "%v", tc)
+		}
+	})
+
+	It("should reject polar regions", func() {
+		_, err := ToUTM(84.5, 0)
+		Expect(err).To(MatchError(errInvalidCoordinates))
+		_, err = ToUTM(-80.5, 0)
+		Expect(err).To(MatchError(errInvalidCoordinates))
+		_, err = ToUTM(math.NaN(), 0)
+		Expect(err).To(MatchError(errInvalidCoordinates))
+		_, err = ToUTM(0, math.NaN())
+		Expect(err).To(MatchError(errInvalidCoordinates))
+		_, _, err = UTM{Zone: 61}.LatLon()
+		Expect(err).To(MatchError(errInvalidCoordinates))
+		_, _, err = UTM{Zone: 31, North: true, Easting: math.NaN(), Northing: 5e6}.LatLon()
+		Expect(err).To(MatchError(errInvalidCoordinates))
+		_, _, err = UTM{Zone: 31, North: true, Easting: 5e5, Northing: math.NaN()}.LatLon()
+		Expect(err).To(MatchError(errInvalidCoordinates))
+		_, _, err = UTM{Zone: 31, North: true, Easting: math.Inf(1), Northing: 5e6}.LatLon()
+		Expect(err).To(MatchError(errInvalidCoordinates))
+	})
+
+	It("should round-trip", func() {
+		for _, pt := range []Point{
+			{Lat: 33.3, Lon: 44.4},
+			{Lat: 43.642567, Lon: -79.387139},
+			{Lat: -33.8568, Lon: 151.2153},
+			{Lat: 0, Lon: 0},
+			{Lat: 83.9, Lon: 40},
+			{Lat: -79.9, Lon: -179.9},
+			{Lat: 60, Lon: 3.1},
+		} {
+			u, err := ToUTM(pt.Lat, pt.Lon)
+			Expect(err).NotTo(HaveOccurred())
+
+			lat, lon, err := u.LatLon()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lat).To(BeNumerically("~", pt.Lat, 1e-8), "%v", pt)
+			Expect(lon).To(BeNumerically("~", pt.Lon, 1e-8), "%v", pt)
+		}
+	})
+})
@@ -0,0 +1,169 @@
+package geohashi
+
+import "errors"
+
+var errInvalidAltitude = errors.New("geohashi: invalid altitude")
+
+// VolumePrecisionMax is the maximum precision of volume hashes. Like
+// spatio-temporal hashes, three dimensions of 19 bits each, plus the
+// precision, fit into 64 bits.
+const VolumePrecisionMax = SpaceTimePrecisionMax
+
+// Volume is a box defined through a min and max lat/lon and altitude
+type Volume struct{ MinLat, MaxLat, MinLon, MaxLon, MinAlt, MaxAlt float64 }
+
+// Area returns the horizontal extent of the volume
+func (v Volume) Area() Area {
+	return Area{MinLat: v.MinLat, MaxLat: v.MaxLat, MinLon: v.MinLon, MaxLon: v.MaxLon}
+}
+
+// Center returns the volume's centeroid coordinates
+func (v Volume) Center() (lat, lon, alt float64) {
+	lat, lon = v.Area().Center()
+	alt = (v.MinAlt + v.MaxAlt) / 2.0
+	return
+}
+
+// Contains returns true if coordinates are contained within the volume.
+func (v Volume) Contains(lat, lon, alt float64) bool {
+	return v.Area().Contains(lat, lon) && v.MinAlt <= alt && alt <= v.MaxAlt
+}
+
+// --------------------------------------------------------------------
+
+// VolumeGrid configures the altitude bounds of volume hashes, in meters,
+// e.g. VolumeGrid{MinAlt: -500, MaxAlt: 20000} for aviation. The bounds are
+// divided in halves at each precision, just like latitudes and longitudes.
+type VolumeGrid struct{ MinAlt, MaxAlt float64 }
+
+// VolumeHash is a numeric hash which interleaves latitude, longitude and
+// altitude.
+type VolumeHash uint64
+
+func newVolumeHash(base uint64, prec uint8) VolumeHash {
+	return VolumeHash(base) | VolumeHash(prec)<<precisionOffset3
+}
+
+// Encode converts a location and altitude into a hash with maximum
+// precision.
+func (g VolumeGrid) Encode(lat, lon, alt float64) (VolumeHash, error) {
+	return g.EncodeWithPrecision(lat, lon, alt, VolumePrecisionMax)
+}
+
+// EncodeWithPrecision converts a location and altitude into a hash.
+func (g VolumeGrid) EncodeWithPrecision(lat, lon, alt float64, prec uint8) (VolumeHash, error) {
+	if prec < PrecisionMin || prec > VolumePrecisionMax {
+		return 0, errInvalidPrecision
+	}
+	if !ValidCoordinates(lat, lon) {
+		return 0, errInvalidCoordinates
+	}
+	if !(g.MinAlt < g.MaxAlt) || !(g.MinAlt <= alt && alt <= g.MaxAlt) {
+		return 0, errInvalidAltitude
+	}
+
+	x := gridIndex((lat-LatMin)/latScale, prec)
+	y := gridIndex((lon-LonMin)/lonScale, prec)
+	z := gridIndex((alt-g.MinAlt)/(g.MaxAlt-g.MinAlt), prec)
+	return newVolumeHash(interleave3(x, y, z), prec), nil
+}
+
+// Decode decodes a hash into a volume
+func (g VolumeGrid) Decode(h VolumeHash) Volume {
+	_, _, z := deinterleave3(h.base())
+	a := h.Hash().Decode()
+	size := (g.MaxAlt - g.MinAlt) / float64(uint64(1)<<h.Precision())
+	return Volume{
+		MinLat: a.MinLat,
+		MaxLat: a.MaxLat,
+		MinLon: a.MinLon,
+		MaxLon: a.MaxLon,
+		MinAlt: g.MinAlt + float64(z)*size,
+		MaxAlt: g.MinAlt + float64(z+1)*size,
+	}
+}
+
+// Precision returns the prec level
+func (h VolumeHash) Precision() uint8 { return uint8(h >> precisionOffset3) }
+
+func (h VolumeHash) base() uint64 { return uint64(h & baseMask3) }
+
+// Hash returns the horizontal component of the hash, with the same
+// precision.
+func (h VolumeHash) Hash() Hash {
+	x, y, _ := deinterleave3(h.base())
+	return newHash(interleave64(x, y), h.Precision())
+}
+
+// Parent zooms out, returning the parent hash, lowering the precision. This
+// function may return VolumeHash(0) if unable to zoom out further
+func (h VolumeHash) Parent() VolumeHash {
+	prec := h.Precision()
+	if prec <= PrecisionMin {
+		return 0
+	}
+	return newVolumeHash(h.base()>>3, prec-1)
+}
+
+// Children zooms in, returning eight child hashes. The first four children
+// cover the lower half, in the same order as Hash.Children, the last four
+// cover the upper half. This function may return nil if unable to zoom in
+// further
+func (h VolumeHash) Children() []VolumeHash {
+	prec := h.Precision()
+	if prec >= VolumePrecisionMax {
+		return nil
+	}
+
+	child := newVolumeHash(h.base()<<3, prec+1)
+	res := make([]VolumeHash, 8)
+	for i := range res {
+		res[i] = child | VolumeHash(i)
+	}
+	return res
+}
+
+// Neighbors returns the adjacent hashes: the hash below, followed by its
+// horizontal neighbors in the order N, NE, E, SE, S, SW, W, NW, then the
+// neighbors at the same altitude, then the hash above and its neighbors.
+// Longitudes wrap around the antimeridian, neighbors beyond the latitude and
+// altitude limits are omitted, there are up to 26 neighbors.
+func (h VolumeHash) Neighbors() []VolumeHash {
+	prec := h.Precision()
+	max := uint64(1)<<prec - 1
+	x, y, z := deinterleave3(h.base())
+
+	// lat (x) and lon (y) offsets, starting with the center
+	offsets := [9][2]int{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+
+	res := make([]VolumeHash, 0, 26)
+	for _, dz := range []int{-1, 0, 1} {
+		nz, ok := gridStep(z, dz, max)
+		if !ok {
+			continue
+		}
+
+		for i, d := range offsets {
+			if dz == 0 && i == 0 {
+				continue
+			}
+			nx, ok := gridStep(x, d[0], max)
+			if !ok {
+				continue
+			}
+			ny := (y + uint64(d[1])) & max
+			res = append(res, newVolumeHash(interleave3(nx, ny, nz), prec))
+		}
+	}
+	return res
+}
+
+// gridStep moves a grid index by -1, 0 or 1, returns false if the result is
+// beyond the limits.
+func gridStep(n uint64, d int, max uint64) (uint64, bool) {
+	switch {
+	case d < 0 && n == 0, d > 0 && n == max:
+		return 0, false
+	}
+	return n + uint64(d), true
+}
@@ -0,0 +1,166 @@
+package geohashi
+
+import (
+	"math"
+	"math/rand"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Volume", func() {
+	subject := Volume{MinLat: 51, MaxLat: 52, MinLon: -1, MaxLon: 0, MinAlt: 100, MaxAlt: 200}
+
+	It("should calculate center", func() {
+		lat, lon, alt := subject.Center()
+		Expect([]float64{lat, lon, alt}).To(Equal([]float64{51.5, -0.5, 150}))
+	})
+
+	It("should check if coordinates are contained", func() {
+		Expect(subject.Contains(51.5, -0.5, 150)).To(BeTrue())
+		Expect(subject.Contains(51.5, -0.5, 200)).To(BeTrue())
+		Expect(subject.Contains(51.5, -0.5, 201)).To(BeFalse())
+		Expect(subject.Contains(52.5, -0.5, 150)).To(BeFalse())
+	})
+
+	It("should return its area", func() {
+		Expect(subject.Area()).To(Equal(Area{MinLat: 51, MaxLat: 52, MinLon: -1, MaxLon: 0}))
+	})
+})
+
+var _ = Describe("VolumeHash", func() {
+	const lat, lon, alt = 51.524632318, -0.0841140747, 1250.0
+
+	grid := VolumeGrid{MinAlt: -500, MaxAlt: 20000}
+
+	It("should encode", func() {
+		h, err := grid.Encode(lat, lon, alt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.Precision()).To(Equal(uint8(VolumePrecisionMax)))
+		Expect(h.Hash()).To(Equal(EncodeWithPrecision(lat, lon, VolumePrecisionMax)))
+
+		h, err = grid.EncodeWithPrecision(lat, lon, alt, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h).To(Equal(VolumeHash(0x0200000000000001)))
+
+		h, err = grid.EncodeWithPrecision(lat, lon, grid.MaxAlt, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h).To(Equal(VolumeHash(0x0200000000000005)))
+
+		_, err = grid.EncodeWithPrecision(lat, lon, alt, 0)
+		Expect(err).To(MatchError(errInvalidPrecision))
+		_, err = grid.EncodeWithPrecision(lat, lon, alt, 20)
+		Expect(err).To(MatchError(errInvalidPrecision))
+		_, err = grid.Encode(90, lon, alt)
+		Expect(err).To(MatchError(errInvalidCoordinates))
+		_, err = grid.Encode(lat, lon, -501)
+		Expect(err).To(MatchError(errInvalidAltitude))
+		_, err = grid.Encode(lat, lon, 20001)
+		Expect(err).To(MatchError(errInvalidAltitude))
+		_, err = grid.Encode(lat, lon, math.NaN())
+		Expect(err).To(MatchError(errInvalidAltitude))
+		_, err = VolumeGrid{}.Encode(lat, lon, 0)
+		Expect(err).To(MatchError(errInvalidAltitude))
+	})
+
+	It("should decode", func() {
+		rnd := rand.New(rand.NewSource(1))
+		for n := 0; n < 1000; n++ {
+			lat, lon := rnd.Float64()*170-85, rnd.Float64()*360-180
+			alt := grid.MinAlt + rnd.Float64()*(grid.MaxAlt-grid.MinAlt)
+			prec := uint8(rnd.Intn(VolumePrecisionMax) + 1)
+
+			h, err := grid.EncodeWithPrecision(lat, lon, alt, prec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(h.Precision()).To(Equal(prec))
+
+			v := grid.Decode(h)
+			Expect(v.Contains(lat, lon, alt)).To(BeTrue())
+			Expect(v.MaxAlt - v.MinAlt).To(BeNumerically("~", 20500/float64(uint64(1)<<prec), 1e-9))
+		}
+	})
+
+	It("should zoom out", func() {
+		for prec := uint8(VolumePrecisionMax); prec > PrecisionMin; prec-- {
+			h, err := grid.EncodeWithPrecision(lat, lon, alt, prec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(grid.EncodeWithPrecision(lat, lon, alt, prec-1)).To(Equal(h.Parent()))
+		}
+
+		h, err := grid.EncodeWithPrecision(lat, lon, alt, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.Parent()).To(Equal(VolumeHash(0)))
+	})
+
+	It("should zoom in", func() {
+		h, err := grid.EncodeWithPrecision(lat, lon, alt, 10)
+		Expect(err).NotTo(HaveOccurred())
+		v := grid.Decode(h)
+		_, _, mid := v.Center()
+
+		children := h.Children()
+		Expect(children).To(HaveLen(8))
+		for i, child := range children {
+			Expect(child.Precision()).To(Equal(uint8(11)))
+			Expect(child.Parent()).To(Equal(h))
+			Expect(child.Hash()).To(Equal(h.Hash().Children()[i%4]))
+
+			cv := grid.Decode(child)
+			Expect(v.Contains(cv.Center())).To(BeTrue())
+			if i < 4 {
+				Expect(cv.MaxAlt).To(BeNumerically("~", mid, 1e-9))
+			} else {
+				Expect(cv.MinAlt).To(BeNumerically("~", mid, 1e-9))
+			}
+		}
+
+		h, err = grid.Encode(lat, lon, alt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.Children()).To(BeNil())
+	})
+
+	It("should find neighbors", func() {
+		h, err := grid.EncodeWithPrecision(lat, lon, alt, 12)
+		Expect(err).NotTo(HaveOccurred())
+		v := grid.Decode(h)
+
+		neighbors := h.Neighbors()
+		Expect(neighbors).To(HaveLen(26))
+		Expect(neighbors).NotTo(ContainElement(h))
+
+		seen := make(map[VolumeHash]bool)
+		for _, n := range neighbors {
+			Expect(seen).NotTo(HaveKey(n))
+			seen[n] = true
+
+			// neighbors touch the volume
+			nv := grid.Decode(n)
+			Expect(nv.MinLat).To(BeNumerically("<=", v.MaxLat+1e-9))
+			Expect(nv.MaxLat).To(BeNumerically(">=", v.MinLat-1e-9))
+			Expect(nv.MinLon).To(BeNumerically("<=", v.MaxLon+1e-9))
+			Expect(nv.MaxLon).To(BeNumerically(">=", v.MinLon-1e-9))
+			Expect(nv.MinAlt).To(BeNumerically("<=", v.MaxAlt+1e-9))
+			Expect(nv.MaxAlt).To(BeNumerically(">=", v.MinAlt-1e-9))
+		}
+
+		below, above := grid.Decode(neighbors[0]), grid.Decode(neighbors[17])
+		Expect(below.Area()).To(Equal(v.Area()))
+		Expect(below.MaxAlt).To(BeNumerically("~", v.MinAlt, 1e-9))
+		Expect(above.Area()).To(Equal(v.Area()))
+		Expect(above.MinAlt).To(BeNumerically("~", v.MaxAlt, 1e-9))
+	})
+
+	It("should omit neighbors beyond the limits", func() {
+		h, err := grid.EncodeWithPrecision(lat, lon, grid.MinAlt, 12)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.Neighbors()).To(HaveLen(17))
+
+		h, err = grid.EncodeWithPrecision(LatMax, lon, grid.MaxAlt, 12)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.Neighbors()).To(HaveLen(11))
+
+		h, err = grid.EncodeWithPrecision(lat, LonMax, alt, 12)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.Neighbors()).To(HaveLen(26))
+	})
+})
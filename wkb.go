@@ -0,0 +1,251 @@
+package geohashi
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var errInvalidWKB = errors.New("geohashi: invalid WKB")
+
+const (
+	wkbPoint              = 1
+	wkbPolygon            = 3
+	wkbMultiPoint         = 4
+	wkbMultiPolygon       = 6
+	wkbGeometryCollection = 7
+
+	wkbLineString      = 2
+	wkbMultiLineString = 5
+
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+
+	wgs84SRID = 4326
+)
+
+// WKB returns the area as a WKB polygon.
+func (a Area) WKB(order binary.AppendByteOrder) []byte {
+	return wkbPolygonBytes(a.ring(), order, false)
+}
+
+// EWKB returns the area as an EWKB polygon with SRID 4326.
+func (a Area) EWKB(order binary.AppendByteOrder) []byte {
+	return wkbPolygonBytes(a.ring(), order, true)
+}
+
+// WKB returns the cell of the hash as a WKB polygon.
+func (h Hash) WKB(order binary.AppendByteOrder) []byte { return h.Decode().WKB(order) }
+
+// EWKB returns the cell of the hash as an EWKB polygon with SRID 4326.
+func (h Hash) EWKB(order binary.AppendByteOrder) []byte { return h.Decode().EWKB(order) }
+
+// WKB returns the polygon as a WKB polygon.
+func (p Polygon) WKB(order binary.AppendByteOrder) []byte {
+	return wkbPolygonBytes(p.ring(), order, false)
+}
+
+// EWKB returns the polygon as an EWKB polygon with SRID 4326.
+func (p Polygon) EWKB(order binary.AppendByteOrder) []byte {
+	return wkbPolygonBytes(p.ring(), order, true)
+}
+
+// wkbPolygonBytes encodes a closed ring as a WKB polygon.
+func wkbPolygonBytes(ring []Point, order binary.AppendByteOrder, srid bool) []byte {
+	b := make([]byte, 0, 13+4+len(ring)*16)
+
+	// encoded in the given order, the first byte of a uint16 1 is the byte
+	// order flag, i.e. 1 for little and 0 for big endian. This supports any
+	// order, including binary.NativeEndian
+	b = order.AppendUint16(b, 1)[:1]
+
+	typ := uint32(wkbPolygon)
+	if srid {
+		typ |= ewkbSRID
+	}
+
+	b = order.AppendUint32(b, typ)
+	if srid {
+		b = order.AppendUint32(b, wgs84SRID)
+	}
+	if len(ring) == 0 {
+		return order.AppendUint32(b, 0)
+	}
+
+	b = order.AppendUint32(b, 1)
+	b = order.AppendUint32(b, uint32(len(ring)))
+	for _, pt := range ring {
+		b = order.AppendUint64(b, math.Float64bits(pt.Lon))
+		b = order.AppendUint64(b, math.Float64bits(pt.Lat))
+	}
+	return b
+}
+
+// --------------------------------------------------------------------
+
+// ParseWKB extracts points and polygons from a WKB or EWKB geometry. Point,
+// MultiPoint, Polygon, MultiPolygon and GeometryCollection geometries are
+// supported, in either byte order. SRIDs are ignored, coordinates are
+// expected to be WGS84 longitude/latitude pairs, Z and M values are dropped.
+// Holes of polygons are ignored.
+func ParseWKB(data []byte) (points []Point, polygons []Polygon, err error) {
+	p := wkbParser{data: data}
+	if err := p.parseGeometry(); err != nil {
+		return nil, nil, err
+	}
+	if len(p.data) != 0 {
+		return nil, nil, errInvalidWKB
+	}
+	return p.points, p.polygons, nil
+}
+
+type wkbParser struct {
+	data  []byte
+	order binary.ByteOrder
+	dims  int
+
+	points   []Point
+	polygons []Polygon
+}
+
+func (p *wkbParser) parseGeometry() error {
+	if len(p.data) < 5 {
+		return errInvalidWKB
+	}
+	switch p.data[0] {
+	case 0:
+		p.order = binary.BigEndian
+	case 1:
+		p.order = binary.LittleEndian
+	default:
+		return errInvalidWKB
+	}
+	p.data = p.data[1:]
+
+	typ, err := p.uint32()
+	if err != nil {
+		return err
+	}
+
+	// EWKB flags
+	p.dims = 2
+	if typ&ewkbZ != 0 {
+		p.dims++
+	}
+	if typ&ewkbM != 0 {
+		p.dims++
+	}
+	if typ&ewkbSRID != 0 {
+		if _, err := p.uint32(); err != nil {
+			return err
+		}
+	}
+	typ &^= ewkbZ | ewkbM | ewkbSRID
+
+	// ISO WKB dimensions
+	switch typ / 1000 {
+	case 0:
+	case 1, 2:
+		p.dims++
+	case 3:
+		p.dims += 2
+	default:
+		return errInvalidWKB
+	}
+
+	switch typ % 1000 {
+	case wkbPoint:
+		pt, err := p.coord()
+		if err != nil {
+			return err
+		}
+		if !math.IsNaN(pt.Lat) && !math.IsNaN(pt.Lon) {
+			p.points = append(p.points, pt)
+		}
+		return nil
+	case wkbPolygon:
+		return p.parsePolygon()
+	case wkbMultiPoint, wkbMultiPolygon, wkbGeometryCollection:
+		n, err := p.uint32()
+		if err != nil {
+			return err
+		}
+		for i := uint32(0); i < n; i++ {
+			if err := p.parseGeometry(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case wkbLineString, wkbMultiLineString:
+		return errUnsupportedGeometry
+	}
+	return errInvalidWKB
+}
+
+func (p *wkbParser) parsePolygon() error {
+	numRings, err := p.uint32()
+	if err != nil || numRings == 0 {
+		return err
+	}
+
+	var ring []Point
+	for i := uint32(0); i < numRings; i++ {
+		n, err := p.uint32()
+		if err != nil {
+			return err
+		}
+		if uint64(n)*uint64(p.dims)*8 > uint64(len(p.data)) {
+			return errInvalidWKB
+		}
+
+		for j := uint32(0); j < n; j++ {
+			pt, err := p.coord()
+			if err != nil {
+				return err
+			}
+			// use the outer ring only
+			if i == 0 {
+				ring = append(ring, pt)
+			}
+		}
+	}
+
+	poly, ok := ringPolygon(ring)
+	if !ok {
+		return errInvalidWKB
+	}
+	for _, pt := range poly {
+		if !pt.valid() { // empty points
+			return errInvalidWKB
+		}
+	}
+	p.polygons = append(p.polygons, poly)
+	return nil
+}
+
+// coord reads a coordinate. Empty points, with NaN coordinates, are accepted.
+func (p *wkbParser) coord() (Point, error) {
+	if len(p.data) < 8*p.dims {
+		return Point{}, errInvalidWKB
+	}
+
+	lon := math.Float64frombits(p.order.Uint64(p.data))
+	lat := math.Float64frombits(p.order.Uint64(p.data[8:]))
+	p.data = p.data[8*p.dims:]
+
+	pt := Point{Lat: lat, Lon: lon}
+	if !pt.valid() && !(math.IsNaN(lat) && math.IsNaN(lon)) {
+		return Point{}, errInvalidWKB
+	}
+	return pt, nil
+}
+
+func (p *wkbParser) uint32() (uint32, error) {
+	if len(p.data) < 4 {
+		return 0, errInvalidWKB
+	}
+	v := p.order.Uint32(p.data)
+	p.data = p.data[4:]
+	return v, nil
+}
@@ -0,0 +1,89 @@
+package geohashi
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WKB", func() {
+	square := Area{MinLat: 0, MaxLat: 1, MinLon: 0, MaxLon: 1}
+	squareLE := "0103000000010000000500000000000000000000000000000000000000000000000000F03F0000000000000000000000000000F03F000000000000F03F0000000000000000000000000000F03F00000000000000000000000000000000"
+	squareBE := "0020000003000010E60000000100000005000000000000000000000000000000003FF000000000000000000000000000003FF00000000000003FF000000000000000000000000000003FF000000000000000000000000000000000000000000000"
+
+	decode := func(s string) []byte {
+		data, err := hex.DecodeString(s)
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	It("should encode", func() {
+		Expect(strings.ToUpper(hex.EncodeToString(square.WKB(binary.LittleEndian)))).To(Equal(squareLE))
+		Expect(strings.ToUpper(hex.EncodeToString(square.EWKB(binary.BigEndian)))).To(Equal(squareBE))
+
+		h := EncodeWithPrecision(1, 1, 1)
+		Expect(h.WKB(binary.BigEndian)).To(Equal(h.Decode().WKB(binary.BigEndian)))
+		Expect(h.EWKB(binary.LittleEndian)).To(Equal(h.Decode().EWKB(binary.LittleEndian)))
+
+		Expect(Polygon(nil).WKB(binary.LittleEndian)).To(Equal([]byte{1, 3, 0, 0, 0, 0, 0, 0, 0}))
+	})
+
+	It("should parse", func() {
+		for _, s := range []string{squareLE, squareBE} {
+			points, polys, err := ParseWKB(decode(s))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(points).To(BeEmpty())
+			Expect(polys).To(HaveLen(1))
+			Expect(polys[0].Bounds()).To(Equal(square))
+		}
+
+		// EWKB point, as returned by ST_AsEWKB('SRID=4326;POINT(1 2)')
+		points, _, err := ParseWKB(decode("0101000020E6100000000000000000F03F0000000000000040"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(points).To(Equal([]Point{{Lat: 2, Lon: 1}}))
+
+		// ISO WKB multi point with Z values
+		points, _, err = ParseWKB(decode("01EC0300000200000001E9030000000000000000F03F0000000000000040000000000000224001E9030000000000000000084000000000000010400000000000002240"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(points).To(Equal([]Point{{Lat: 2, Lon: 1}, {Lat: 4, Lon: 3}}))
+
+		// empty point
+		points, _, err = ParseWKB(decode("0101000000000000000000F87F000000000000F87F"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(points).To(BeEmpty())
+	})
+
+	It("should round-trip", func() {
+		poly := Polygon{{Lat: 51.5, Lon: -0.2}, {Lat: 51.5, Lon: 0.1}, {Lat: 51.6, Lon: 0}}
+		for _, order := range []binary.AppendByteOrder{binary.LittleEndian, binary.BigEndian, binary.NativeEndian} {
+			_, polys, err := ParseWKB(poly.EWKB(order))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(polys).To(Equal([]Polygon{poly}))
+
+			_, polys, err = ParseWKB(poly.WKB(order))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(polys).To(Equal([]Polygon{poly}))
+		}
+		Expect(poly.WKB(binary.NativeEndian)).To(Or(Equal(poly.WKB(binary.LittleEndian)), Equal(poly.WKB(binary.BigEndian))))
+	})
+
+	It("should reject bad inputs", func() {
+		parse := func(data []byte) error {
+			_, _, err := ParseWKB(data)
+			return err
+		}
+
+		data := decode(squareLE)
+		Expect(parse(data[:len(data)-1])).To(MatchError(errInvalidWKB))
+		Expect(parse(append(data, 0))).To(MatchError(errInvalidWKB))
+		Expect(parse(nil)).To(MatchError(errInvalidWKB))
+		Expect(parse([]byte{2, 1, 0, 0, 0})).To(MatchError(errInvalidWKB))
+		Expect(parse([]byte{1, 8, 0, 0, 0})).To(MatchError(errInvalidWKB))
+		Expect(parse([]byte{1, 2, 0, 0, 0, 0, 0, 0, 0})).To(MatchError(errUnsupportedGeometry))
+		Expect(parse(decode("0101000000000000000000F03F0000000000C05640"))).To(MatchError(errInvalidWKB))
+		Expect(parse([]byte{1, 3, 0, 0, 0, 1, 0, 0, 0, 255, 255, 255, 255})).To(MatchError(errInvalidWKB))
+	})
+})
@@ -0,0 +1,243 @@
+package geohashi
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var errInvalidWKT = errors.New("geohashi: invalid WKT")
+
+// WKT returns the area as a WKT POLYGON.
+func (a Area) WKT() string { return wktPolygon(a.ring()) }
+
+// WKT returns the cell of the hash as a WKT POLYGON.
+func (h Hash) WKT() string { return h.Decode().WKT() }
+
+// WKT returns the polygon as a WKT POLYGON.
+func (p Polygon) WKT() string { return wktPolygon(p.ring()) }
+
+// wktPolygon formats a closed ring as a WKT POLYGON.
+func wktPolygon(ring []Point) string {
+	if len(ring) == 0 {
+		return "POLYGON EMPTY"
+	}
+
+	b := []byte("POLYGON((")
+	for i, pt := range ring {
+		if i != 0 {
+			b = append(b, ',')
+		}
+		b = strconv.AppendFloat(b, pt.Lon, 'f', -1, 64)
+		b = append(b, ' ')
+		b = strconv.AppendFloat(b, pt.Lat, 'f', -1, 64)
+	}
+	b = append(b, "))"...)
+	return string(b)
+}
+
+// --------------------------------------------------------------------
+
+// ParseWKT extracts points and polygons from a WKT or EWKT geometry. POINT,
+// MULTIPOINT, POLYGON, MULTIPOLYGON and GEOMETRYCOLLECTION geometries are
+// supported. SRIDs are ignored, coordinates are expected to be WGS84
+// longitude/latitude pairs, Z and M values are dropped. Holes of polygons are
+// ignored.
+func ParseWKT(s string) (points []Point, polygons []Polygon, err error) {
+	p := wktParser{s: s}
+
+	// strip EWKT SRID prefix
+	if p.skipSpace(); len(s)-p.pos > 5 && strings.EqualFold(s[p.pos:p.pos+5], "SRID=") {
+		n := strings.IndexByte(s, ';')
+		if n < 0 {
+			return nil, nil, errInvalidWKT
+		}
+		p.pos = n + 1
+	}
+
+	if err := p.parseGeometry(); err != nil {
+		return nil, nil, err
+	}
+	if p.skipSpace(); p.pos != len(s) {
+		return nil, nil, errInvalidWKT
+	}
+	return p.points, p.polygons, nil
+}
+
+type wktParser struct {
+	s   string
+	pos int
+
+	points   []Point
+	polygons []Polygon
+}
+
+func (p *wktParser) parseGeometry() error {
+	typ := strings.ToUpper(p.word())
+	switch dim := strings.ToUpper(p.word()); dim {
+	case "", "Z", "M", "ZM":
+	case "EMPTY":
+		return nil
+	default:
+		return errInvalidWKT
+	}
+	if p.skipWord("EMPTY") {
+		return nil
+	}
+
+	switch typ {
+	case "POINT":
+		pt, err := p.parsePoint()
+		if err != nil {
+			return err
+		}
+		p.points = append(p.points, pt)
+		return nil
+	case "MULTIPOINT":
+		return p.parseList(func() error {
+			// points may or may not be wrapped in parentheses
+			wrapped := p.skip('(')
+			pt, err := p.parseCoord()
+			if err != nil {
+				return err
+			}
+			if wrapped && !p.skip(')') {
+				return errInvalidWKT
+			}
+			p.points = append(p.points, pt)
+			return nil
+		})
+	case "POLYGON":
+		return p.parsePolygon()
+	case "MULTIPOLYGON":
+		return p.parseList(p.parsePolygon)
+	case "GEOMETRYCOLLECTION":
+		return p.parseList(p.parseGeometry)
+	case "LINESTRING", "MULTILINESTRING":
+		return errUnsupportedGeometry
+	}
+	return errInvalidWKT
+}
+
+func (p *wktParser) parsePoint() (Point, error) {
+	if !p.skip('(') {
+		return Point{}, errInvalidWKT
+	}
+	pt, err := p.parseCoord()
+	if err != nil {
+		return Point{}, err
+	}
+	if !p.skip(')') {
+		return Point{}, errInvalidWKT
+	}
+	return pt, nil
+}
+
+func (p *wktParser) parsePolygon() error {
+	var ring []Point
+	n := 0
+	err := p.parseList(func() error {
+		// use the outer ring only
+		n++
+		return p.parseList(func() error {
+			pt, err := p.parseCoord()
+			if err == nil && n == 1 {
+				ring = append(ring, pt)
+			}
+			return err
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	poly, ok := ringPolygon(ring)
+	if !ok {
+		return errInvalidWKT
+	}
+	p.polygons = append(p.polygons, poly)
+	return nil
+}
+
+// parseList parses a comma-separated list of elements, wrapped in
+// parentheses.
+func (p *wktParser) parseList(fn func() error) error {
+	if !p.skip('(') {
+		return errInvalidWKT
+	}
+	for {
+		if err := fn(); err != nil {
+			return err
+		}
+		if p.skip(')') {
+			return nil
+		}
+		if !p.skip(',') {
+			return errInvalidWKT
+		}
+	}
+}
+
+// parseCoord parses a coordinate of two to four numbers.
+func (p *wktParser) parseCoord() (Point, error) {
+	var vv []float64
+	for len(vv) < 4 {
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.s) && strings.IndexByte("+-.0123456789eE", p.s[p.pos]) > -1 {
+			p.pos++
+		}
+		if start == p.pos {
+			break
+		}
+
+		v, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			return Point{}, errInvalidWKT
+		}
+		vv = append(vv, v)
+	}
+
+	if len(vv) < 2 {
+		return Point{}, errInvalidWKT
+	}
+	if pt := (Point{Lat: vv[1], Lon: vv[0]}); pt.valid() {
+		return pt, nil
+	}
+	return Point{}, errInvalidWKT
+}
+
+// word consumes and returns the next word.
+func (p *wktParser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && (p.s[p.pos] >= 'A' && p.s[p.pos] <= 'Z' || p.s[p.pos] >= 'a' && p.s[p.pos] <= 'z') {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// skipWord consumes the next word, if it equals w.
+func (p *wktParser) skipWord(w string) bool {
+	pos := p.pos
+	if strings.EqualFold(p.word(), w) {
+		return true
+	}
+	p.pos = pos
+	return false
+}
+
+// skip consumes the next non-space character, if it equals c.
+func (p *wktParser) skip(c byte) bool {
+	if p.skipSpace(); p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) > -1 {
+		p.pos++
+	}
+}
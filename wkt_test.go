@@ -0,0 +1,84 @@
+package geohashi
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WKT", func() {
+	triangle := Polygon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 1}, {Lat: 1, Lon: 0}}
+
+	It("should encode", func() {
+		Expect(Area{MinLat: -1.5, MaxLat: 2, MinLon: 3, MaxLon: 4.25}.WKT()).To(Equal("POLYGON((3 -1.5,4.25 -1.5,4.25 2,3 2,3 -1.5))"))
+		Expect(EncodeWithPrecision(1, 1, 1).WKT()).To(Equal("POLYGON((0 0,180 0,180 85.05112878,0 85.05112878,0 0))"))
+		Expect(triangle.WKT()).To(Equal("POLYGON((0 0,1 0,0 1,0 0))"))
+		Expect(Polygon(nil).WKT()).To(Equal("POLYGON EMPTY"))
+	})
+
+	It("should parse points", func() {
+		points, polys, err := ParseWKT("POINT (-0.1 51.5)")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(points).To(Equal([]Point{{Lat: 51.5, Lon: -0.1}}))
+		Expect(polys).To(BeEmpty())
+
+		points, _, err = ParseWKT("SRID=4326;point z(1 2 3)")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(points).To(Equal([]Point{{Lat: 2, Lon: 1}}))
+
+		points, _, err = ParseWKT("MULTIPOINT ((1 2), (3 4))")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(points).To(Equal([]Point{{Lat: 2, Lon: 1}, {Lat: 4, Lon: 3}}))
+
+		points, _, err = ParseWKT("MULTIPOINT (1 2, 3e0 4)")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(points).To(Equal([]Point{{Lat: 2, Lon: 1}, {Lat: 4, Lon: 3}}))
+
+		points, _, err = ParseWKT("POINT EMPTY")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(points).To(BeEmpty())
+	})
+
+	It("should parse polygons", func() {
+		_, polys, err := ParseWKT("POLYGON ((0 0, 1 0, 0 1, 0 0), (0.1 0.1, 0.2 0.1, 0.1 0.2, 0.1 0.1))")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(polys).To(Equal([]Polygon{triangle}))
+
+		_, polys, err = ParseWKT("MULTIPOLYGON (((0 0, 1 0, 0 1, 0 0)), ((5 5, 6 5, 6 6, 5 5)))")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(polys).To(Equal([]Polygon{triangle, {{Lat: 5, Lon: 5}, {Lat: 5, Lon: 6}, {Lat: 6, Lon: 6}}}))
+
+		points, polys, err := ParseWKT("GEOMETRYCOLLECTION (POINT (1 2), POLYGON ZM ((0 0 1 1, 1 0 1 1, 0 1 1 1, 0 0 1 1)), POLYGON EMPTY)")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(points).To(Equal([]Point{{Lat: 2, Lon: 1}}))
+		Expect(polys).To(Equal([]Polygon{triangle}))
+	})
+
+	It("should round-trip", func() {
+		a := Area{MinLat: 51.5, MaxLat: 51.6, MinLon: -0.2, MaxLon: 0.1}
+		_, polys, err := ParseWKT(a.WKT())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(polys).To(HaveLen(1))
+		Expect(polys[0].Bounds()).To(Equal(a))
+	})
+
+	It("should reject bad inputs", func() {
+		parse := func(s string) error {
+			_, _, err := ParseWKT(s)
+			return err
+		}
+
+		Expect(parse("LINESTRING (0 0, 1 1)")).To(MatchError(errUnsupportedGeometry))
+		Expect(parse("CIRCLE (0 0)")).To(MatchError(errInvalidWKT))
+		Expect(parse("POINT (0)")).To(MatchError(errInvalidWKT))
+		Expect(parse("POINT (0 91)")).To(MatchError(errInvalidWKT))
+		Expect(parse("POINT (0 0")).To(MatchError(errInvalidWKT))
+		Expect(parse("POINT (0 0) x")).To(MatchError(errInvalidWKT))
+		Expect(parse("POINT X (0 0)")).To(MatchError(errInvalidWKT))
+		Expect(parse("POLYGON ((0 0, 1 0, 0 0))")).To(MatchError(errInvalidWKT))
+		Expect(parse("POLYGON ((0 0, 1 0, 0 1, 1 1))")).To(MatchError(errInvalidWKT))
+		Expect(parse("POLYGON ((0 0, 1 0, 1 0, 0 0))")).To(MatchError(errInvalidWKT))
+		Expect(parse("POLYGON ((0 0, 1 0, 0 0, 1 0, 0 0))")).To(MatchError(errInvalidWKT))
+		Expect(parse("SRID=4326 POINT (0 0)")).To(MatchError(errInvalidWKT))
+		Expect(parse("")).To(MatchError(errInvalidWKT))
+	})
+})